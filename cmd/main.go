@@ -1,15 +1,23 @@
 package main
 
 import (
+	"go-event/internal/audit"
+	"go-event/internal/broadcast"
 	"fmt"
 	"go-event/internal/event"
+	"go-event/internal/eventbus"
 	"go-event/internal/notification"
 	"go-event/internal/notification/email"
 	"go-event/internal/participant"
+	"go-event/internal/payment"
 	"go-event/internal/schedule"
+	"go-event/internal/ticket"
 	"go-event/internal/user"
+	"go-event/internal/venue"
+	"go-event/internal/webhook"
 
 	"go-event/pkg/config"
+	"go-event/pkg/idempotency"
 	"go-event/pkg/middlewares"
 	"log"
 
@@ -17,6 +25,7 @@ import (
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/fiber/v2/middleware/logger"
 	"github.com/gofiber/fiber/v2/middleware/recover"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
 )
 
 func main() {
@@ -28,14 +37,17 @@ func main() {
 	})
 
 	app.Use(recover.New())
+	// Request ID dikirim balik di header X-Request-ID dan di setiap response error
+	app.Use(requestid.New())
 	app.Use(logger.New(logger.Config{
-		Format: "[${time}] ${status} - ${method} ${path} ${latency}\n",
+		Format: "[${time}] ${locals:requestid} ${status} - ${method} ${path} ${latency}\n",
 	}))
 	
 	app.Use(cors.New(cors.Config{
 		AllowOrigins: cfg.CorsOrigin,
 		AllowCredentials: true,
-		AllowHeaders: "Origin, Content-Type, Accept, Authorization",
+		AllowHeaders: "Origin, Content-Type, Accept, Authorization, X-Request-ID, Idempotency-Key",
+		ExposeHeaders: "X-Request-ID, Idempotent-Replayed",
 		AllowMethods: "GET, POST, PUT, DELETE, OPTIONS",
 	}))
 
@@ -48,8 +60,28 @@ func main() {
 		&user.User{},
 		&event.Event{}, // tambahkan model Event ke migrasi
 		&participant.Participant{}, // tambahkan model Event ke migrasi
+		&participant.Guest{},
 		&schedule.ScheduleJob{}, // tambahkan model Event ke migrasi
 		&notification.Notification{}, // tambahkan model Notification ke migrasi
+		&notification.NotificationPreference{},
+		&notification.NotificationSettings{},
+		&notification.ReminderOptOut{},
+		&notification.DeferredEmail{},
+		&venue.Venue{},
+		&venue.Room{},
+		&ticket.TicketType{},
+		&ticket.PromoCode{},
+		&ticket.Order{},
+		&idempotency.Key{},
+		&broadcast.Broadcast{},
+		&broadcast.BroadcastRecipient{},
+		&webhook.Webhook{},
+		&webhook.WebhookDelivery{},
+		&audit.AuditLog{},
+	}
+	// Baris participant ganda harus dibersihkan sebelum unique index (event_id, user_id) dibuat
+	if err := participant.DedupeBeforeMigrate(db); err != nil {
+		log.Fatalf("Participant dedupe failed: %v", err)
 	}
 	if err := db.AutoMigrate(tables...); err != nil {
 		log.Fatalf("Database migration failed: %v", err)
@@ -71,40 +103,99 @@ func main() {
 	participantRepo := participant.Newrepository(db)
 	scheduleRepo := schedule.NewRepository(db)
 	notificationRepo := notification.Newrepository(db)
+	venueRepo := venue.NewRepository(db)
+	ticketRepo := ticket.NewRepository(db)
+	broadcastRepo := broadcast.NewRepository(db)
+	webhookRepo := webhook.NewRepository(db)
+	auditRepo := audit.NewRepository(db)
+	idempotencyRepo := idempotency.NewRepository(db)
 	
 	// Create adapter for event repository to avoid circular dependency
-	eventRepoAdapter := event.NewEventRepositoryAdapter(eventRepo)
+	roomBookingAdapter := event.NewRoomBookingAdapter(eventRepo)
 	
-	// Initialize notification service (dibutuhkan oleh event service dan scheduler)
-	notificationService := notification.NewService(notificationRepo, eventRepo, emailService, cfg)
+	// Event bus in-process: event, participant, dan scheduler mem-publish domain event,
+	// service lain subscribe di bawah setelah semua service dibuat
+	bus := eventbus.New()
+	webhookService := webhook.NewService(webhookRepo, cfg)
+	webhookController := webhook.NewController(webhookService, cfg)
+
+	// Initialize notification service (dibutuhkan oleh event service dan scheduler).
+	// Hub in-process mengirim notifikasi baru ke koneksi SSE/WebSocket
+	notificationHub := notification.NewMemoryHub()
+	notificationService := notification.NewService(notificationRepo, eventRepo, participantRepo, userRepo, emailService, notificationHub, cfg)
 	notificationController := notification.NewController(notificationService, cfg)
 	
 	// Initialize services
 	userService := user.NewService(userRepo, emailService, cfg)
 	userController := user.NewController(userService, cfg)
 	
-	// Initialize event service (dengan dependency notification untuk update/cancel)
-	eventService := event.NewService(eventRepo, participantRepo, userRepo, notificationService, cfg)
+	// Initialize ticket service (payment provider fake sampai gateway asli tersedia,
+	// order yang lunas dikabarkan lewat event bus)
+	paymentProvider := payment.NewFakeProvider()
+	ticketService := ticket.NewService(ticketRepo, eventRepo, participantRepo, userRepo, paymentProvider, emailService, bus, cfg)
+	ticketController := ticket.NewController(ticketService, cfg)
+
+	// Initialize schedule service (menggabungkan notifikasi update event)
+	scheduleService := schedule.NewService(scheduleRepo, eventRepo, cfg)
+	scheduleController := schedule.NewController(scheduleService, cfg)
+
+	// Initialize event service (update/cancel dikabarkan lewat event bus)
+	eventService := event.NewService(eventRepo, participantRepo, userRepo, venueRepo, bus, cfg)
 	eventController := event.NewController(eventService, cfg)
+
+	// Initialize venue service (booking room dibaca dari event lewat adapter)
+	venueService := venue.NewService(venueRepo, roomBookingAdapter, cfg)
+	venueController := venue.NewController(venueService, cfg)
 	
-	// Initialize participant service (dengan email service untuk konfirmasi registrasi)
-	participantService := participant.NewService(participantRepo, eventRepoAdapter, userRepo, emailService, cfg)
+	// Initialize participant service (dengan email service untuk konfirmasi registrasi,
+	// hasil approval dikabarkan lewat event bus)
+	participantService := participant.NewService(participantRepo, eventRepo, userRepo, emailService, ticketService, bus, cfg)
 	participantController := participant.NewController(participantService, *cfg)
+
+	// Initialize broadcast service (broadcast terjadwal dijalankan lewat schedule job)
+	broadcastService := broadcast.NewService(broadcastRepo, eventRepo, participantRepo, notificationService, scheduleService, cfg)
+	broadcastController := broadcast.NewController(broadcastService, cfg)
 	
-	// Initialize schedule service
-	scheduleService := schedule.NewService(scheduleRepo, eventRepo, cfg)
-	scheduleController := schedule.NewController(scheduleService, cfg)
+	// Subscriber domain event. Sync untuk pekerjaan yang harus tersimpan sebelum response
+	// dikirim, async untuk notifikasi, refund, dan webhook
+	eventbus.Subscribe(bus, eventbus.Sync, scheduleService.OnEventUpdated)
+	eventbus.Subscribe(bus, eventbus.Async, notificationService.OnEventCancelled)
+	eventbus.Subscribe(bus, eventbus.Async, notificationService.OnParticipantReviewed)
+	eventbus.Subscribe(bus, eventbus.Async, ticketService.OnEventCancelled)
+	eventbus.SubscribeAll(bus, eventbus.Async, webhookService.Dispatch)
+
+	// Audit log: route mutasi dicatat lewat auditLog.Record, keadaan target sebelum dan
+	// sesudah request dibaca lewat loader per tipe target untuk diff. Audit sengaja tidak
+	// subscribe ke event bus: IP, user agent, dan keadaan sebelum perubahan hanya ada di
+	// request HTTP, sedangkan domain event dari scheduler bukan aksi admin / organizer
+	auditService := audit.NewService(auditRepo)
+	auditController := audit.NewController(auditService, cfg)
+	auditLog := audit.NewRecorder(auditService)
+	auditLog.Register("user", audit.Load(userRepo.GetByID, (*user.User).ToResponse))
+	auditLog.Register("event", audit.Load(eventRepo.GetByID, (*event.Event).ToResponse))
+	auditLog.Register("participant", audit.Load(participantRepo.FindByID, (*participant.Participant).ToResponse))
+	auditLog.Register("schedule", audit.Load(scheduleRepo.GetByID, (*schedule.ScheduleJob).ToResponse))
+	auditLog.Register("notification", audit.Load(notificationRepo.GetByID, (*notification.Notification).ToResponse))
+	auditLog.Register("notification_preferences", func(userID uint) (interface{}, error) {
+		return notificationService.GetPreferences(userID)
+	})
+
 	// Initialize scheduler with all dependencies
-	scheduler := schedule.NewScheduler(scheduleRepo, notificationService, participantRepo, userRepo)
+	scheduler := schedule.NewScheduler(scheduleRepo, notificationService, participantRepo, userRepo, eventService, ticketService, broadcastService, webhookService, idempotencyRepo, bus)
 	scheduler.Start()
 	defer scheduler.Stop()
 
 	// Use vertical layer routes
-	user.SetupUserRoutes(app, userController, cfg)
-	event.SetupOrganizerEventRoutes(app, eventController, cfg)
-	participant.SetupParticipantRoute(app, participantController, cfg)
-	schedule.SetupScheduleRoutes(app, scheduleController, cfg)
-	notification.SetupNotificationRoutes(app, notificationController, cfg)
+	user.SetupUserRoutes(app, userController, cfg, auditLog)
+	event.SetupOrganizerEventRoutes(app, eventController, cfg, auditLog)
+	participant.SetupParticipantRoute(app, participantController, cfg, idempotencyRepo, auditLog)
+	schedule.SetupScheduleRoutes(app, scheduleController, cfg, auditLog)
+	notification.SetupNotificationRoutes(app, notificationController, cfg, auditLog)
+	venue.SetupVenueRoutes(app, venueController, cfg)
+	ticket.SetupTicketRoutes(app, ticketController, cfg, idempotencyRepo)
+	broadcast.SetupBroadcastRoutes(app, broadcastController, cfg, idempotencyRepo)
+	webhook.SetupWebhookRoutes(app, webhookController, cfg)
+	audit.SetupAuditRoutes(app, auditController, cfg)
 
 	app.Use(middlewares.NotFound)
 
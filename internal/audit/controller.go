@@ -0,0 +1,48 @@
+package audit
+
+import (
+	"go-event/pkg/apperror"
+	"go-event/pkg/config"
+	"go-event/pkg/pagination"
+	"go-event/pkg/validation"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type Controller struct {
+	service Service
+	cfg     *config.Config
+}
+
+func NewController(service Service, cfg *config.Config) *Controller {
+	return &Controller{service: service, cfg: cfg}
+}
+
+// GetAuditLogs - audit log terbaru dulu, bisa difilter actor, action, target, dan rentang waktu
+func (ctrl *Controller) GetAuditLogs(c *fiber.Ctx) error {
+	var page pagination.Params
+	if err := c.QueryParser(&page); err != nil {
+		return apperror.ErrInvalidParam
+	}
+	if err := validation.Struct(&page); err != nil {
+		return err
+	}
+	var query ListQuery
+	if err := c.QueryParser(&query); err != nil {
+		return apperror.ErrInvalidParam
+	}
+	if err := validation.Struct(&query); err != nil {
+		return err
+	}
+
+	result, err := ctrl.service.ListLogs(&query, page)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message":    "audit logs retrieved successfully",
+		"audit_logs": result.AuditLogs,
+		"pagination": result.Pagination,
+	})
+}
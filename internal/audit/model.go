@@ -0,0 +1,79 @@
+package audit
+
+import (
+	"errors"
+	"go-event/pkg/pagination"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// errAppendOnly dikembalikan hook GORM jika ada kode yang mencoba mengubah audit log
+var errAppendOnly = errors.New("audit log is append-only")
+
+// AuditLog adalah satu aksi mutasi yang berhasil. Hanya pernah di-insert, tidak
+// pernah diubah atau dihapus
+type AuditLog struct {
+	ID        uint   `json:"id" gorm:"primaryKey"`
+	ActorID   uint   `json:"actor_id" gorm:"index"`
+	ActorRole string `json:"actor_role" gorm:"size:20"`
+	// Action berformat <target>.<aksi>, contoh user.update_role, event.cancel
+	Action     string `json:"action" gorm:"size:64;index"`
+	TargetType string `json:"target_type" gorm:"size:32;index:idx_audit_target,priority:1"`
+	// TargetID nil untuk aksi bulk (misalnya notification.read_all)
+	TargetID *uint `json:"target_id" gorm:"index:idx_audit_target,priority:2"`
+	// TargetIDs berisi target aksi bulk yang ID-nya diketahui (misalnya participant yang di-approve)
+	TargetIDs []uint    `json:"target_ids,omitempty" gorm:"serializer:json;type:text"`
+	Changes   []Change  `json:"changes" gorm:"serializer:json;type:mediumtext"`
+	IP        string    `json:"ip" gorm:"size:45"`
+	UserAgent string    `json:"user_agent" gorm:"size:255"`
+	RequestID string    `json:"request_id" gorm:"size:64"`
+	CreatedAt time.Time `json:"created_at" gorm:"index"`
+}
+
+// BeforeUpdate menjaga audit log tetap append-only
+func (*AuditLog) BeforeUpdate(*gorm.DB) error {
+	return errAppendOnly
+}
+
+// BeforeDelete menjaga audit log tetap append-only
+func (*AuditLog) BeforeDelete(*gorm.DB) error {
+	return errAppendOnly
+}
+
+// Change adalah satu field target yang berubah. Old nil = target baru dibuat,
+// New nil = target dihapus
+type Change struct {
+	Field string      `json:"field"`
+	Old   interface{} `json:"old"`
+	New   interface{} `json:"new"`
+}
+
+// 📩 Query struct
+type ListQuery struct {
+	ActorID    uint   `query:"actor_id"`
+	Action     string `query:"action" validate:"omitempty,max=64"`
+	TargetType string `query:"target_type" validate:"omitempty,max=32"`
+	TargetID   uint   `query:"target_id"`
+	// From dan To dalam format RFC3339, contoh 2025-11-01T00:00:00+07:00
+	From string `query:"from" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	To   string `query:"to" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+}
+
+// ListFilter adalah ListQuery yang sudah di-parse untuk repository
+type ListFilter struct {
+	ActorID    uint
+	Action     string
+	TargetType string
+	TargetID   uint
+	From       *time.Time
+	To         *time.Time
+	Offset     int
+	Limit      int
+}
+
+// 📤 Response struct
+type AuditLogPage struct {
+	AuditLogs  []AuditLog
+	Pagination pagination.Meta
+}
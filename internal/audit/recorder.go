@@ -0,0 +1,287 @@
+package audit
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// maxUserAgentLength sesuai ukuran kolom user_agent
+const maxUserAgentLength = 255
+
+// ignoredFields tidak ikut dibandingkan karena selalu berubah setiap kali target disimpan
+var ignoredFields = map[string]bool{"updated_at": true}
+
+// Loader mengambil keadaan target untuk dibandingkan sebelum dan sesudah request.
+// (nil, nil) atau gorm.ErrRecordNotFound berarti target tidak ada
+type Loader func(id uint) (interface{}, error)
+
+// Load membuat Loader dari getter repository dan fungsi yang mengubah entity menjadi
+// bentuk yang dicatat (biasanya ToResponse agar field rahasia tidak ikut), contoh:
+//
+//	audit.Load(userRepo.GetByID, (*user.User).ToResponse)
+func Load[T any, V any](get func(id uint) (*T, error), view func(*T) V) Loader {
+	return func(id uint) (interface{}, error) {
+		entity, err := get(id)
+		if err != nil || entity == nil {
+			return nil, err
+		}
+		return view(entity), nil
+	}
+}
+
+// Target menentukan apa yang diubah oleh sebuah endpoint
+type Target struct {
+	Type string
+	// id membaca ID target dari request sebelum handler dijalankan
+	id func(c *fiber.Ctx) uint
+	// created adalah path ID target baru di response body, contoh "event.id"
+	created string
+	bulk    bool
+	// affected adalah path ID target aksi bulk di response body, contoh "result.reviewed.id"
+	affected string
+}
+
+// Param: ID target dari path param
+func Param(targetType, param string) Target {
+	return Target{Type: targetType, id: func(c *fiber.Ctx) uint {
+		id, _ := strconv.ParseUint(c.Params(param), 10, 32)
+		return uint(id)
+	}}
+}
+
+// Self: target adalah milik user yang login, ID-nya sama dengan ID user
+func Self(targetType string) Target {
+	return Target{Type: targetType, id: func(c *fiber.Ctx) uint {
+		id, _ := c.Locals("userID").(uint)
+		return id
+	}}
+}
+
+// Created: target dibuat oleh request, ID-nya dibaca dari response body
+func Created(targetType, path string) Target {
+	return Target{Type: targetType, created: path}
+}
+
+// Bulk: aksi terhadap banyak target sekaligus, dicatat tanpa target ID dan diff
+func Bulk(targetType string) Target {
+	return Target{Type: targetType, bulk: true}
+}
+
+// BulkFrom: aksi bulk yang ID targetnya dibaca dari response body. Path boleh melewati
+// array, contoh "report.rows.participant_id"
+func BulkFrom(targetType, path string) Target {
+	return Target{Type: targetType, bulk: true, affected: path}
+}
+
+// Lookup: ID target dicari dari request sebelum handler dijalankan, contoh pendaftaran
+// milik user yang login. 0 berarti target tidak ditemukan
+func Lookup(targetType string, id func(c *fiber.Ctx) uint) Target {
+	return Target{Type: targetType, id: id}
+}
+
+// Recorder mencatat request mutasi yang berhasil ke audit log
+type Recorder struct {
+	service Service
+	loaders map[string]Loader
+}
+
+func NewRecorder(service Service) *Recorder {
+	return &Recorder{service: service, loaders: make(map[string]Loader)}
+}
+
+// Register mendaftarkan Loader untuk satu tipe target. Target tanpa Loader dicatat tanpa diff
+func (r *Recorder) Register(targetType string, loader Loader) {
+	r.loaders[targetType] = loader
+}
+
+// Record adalah middleware yang mencatat aksi setelah handler berhasil (status < 400),
+// beserta diff keadaan target sebelum dan sesudahnya. Harus dipasang setelah
+// Authenticate dan Idempotency (response yang di-replay tidak dicatat ulang)
+func (r *Recorder) Record(action string, target Target) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var targetID uint
+		if target.id != nil {
+			targetID = target.id(c)
+		}
+		before, beforeOK := r.load(target.Type, targetID)
+
+		if err := c.Next(); err != nil {
+			return err
+		}
+		if c.Response().StatusCode() >= fiber.StatusBadRequest {
+			return nil
+		}
+
+		if target.created != "" {
+			targetID = idFromBody(c.Response().Body(), target.created)
+		}
+		after, afterOK := r.load(target.Type, targetID)
+
+		actorID, _ := c.Locals("userID").(uint)
+		actorRole, _ := c.Locals("userRole").(string)
+		requestID, _ := c.Locals("requestid").(string)
+		entry := &AuditLog{
+			ActorID:    actorID,
+			ActorRole:  actorRole,
+			Action:     action,
+			TargetType: target.Type,
+			IP:         c.IP(),
+			UserAgent:  truncate(string(c.Request().Header.UserAgent()), maxUserAgentLength),
+			RequestID:  requestID,
+		}
+		if !target.bulk {
+			if targetID != 0 {
+				entry.TargetID = &targetID
+			}
+			if beforeOK && afterOK {
+				entry.Changes = diff(before, after)
+			}
+		} else if target.affected != "" {
+			entry.TargetIDs = idsFromBody(c.Response().Body(), target.affected)
+		}
+		// Aksi sudah terjadi, gagal mencatat tidak boleh mengubah response
+		if err := r.service.Record(entry); err != nil {
+			log.Printf("audit: failed to record %s by user %d: %v", action, actorID, err)
+		}
+		return nil
+	}
+}
+
+// load mengambil keadaan target. false jika keadaannya tidak bisa diketahui
+// (tidak ada Loader atau error), sehingga diff tidak dicatat
+func (r *Recorder) load(targetType string, id uint) (interface{}, bool) {
+	loader, ok := r.loaders[targetType]
+	if !ok {
+		return nil, false
+	}
+	if id == 0 {
+		return nil, true
+	}
+	value, err := loader(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, true
+	}
+	if err != nil {
+		log.Printf("audit: failed to load %s %d: %v", targetType, id, err)
+		return nil, false
+	}
+	return value, true
+}
+
+// diff membandingkan field JSON tingkat atas before dan after
+func diff(before, after interface{}) []Change {
+	old, current := toFields(before), toFields(after)
+	keys := make([]string, 0, len(old)+len(current))
+	for k := range old {
+		keys = append(keys, k)
+	}
+	for k := range current {
+		if _, ok := old[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	changes := []Change{}
+	for _, k := range keys {
+		if ignoredFields[k] || reflect.DeepEqual(old[k], current[k]) {
+			continue
+		}
+		changes = append(changes, Change{Field: k, Old: old[k], New: current[k]})
+	}
+	return changes
+}
+
+// toFields mengubah value menjadi map field JSON, nil jika value bukan object
+func toFields(value interface{}) map[string]interface{} {
+	if value == nil {
+		return nil
+	}
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil
+	}
+	return fields
+}
+
+// idFromBody membaca ID di path JSON body, 0 jika tidak ditemukan
+func idFromBody(body []byte, path string) uint {
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return 0
+	}
+	for _, key := range strings.Split(path, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return 0
+		}
+		value = object[key]
+	}
+	return toID(value)
+}
+
+// idsFromBody membaca semua ID di path JSON body, array di tengah path ditelusuri
+// per elemen. Nilai yang bukan ID (misalnya baris import yang gagal) dilewati
+func idsFromBody(body []byte, path string) []uint {
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return nil
+	}
+	values := []interface{}{value}
+	for _, key := range strings.Split(path, ".") {
+		next := make([]interface{}, 0, len(values))
+		for _, v := range values {
+			if items, ok := v.([]interface{}); ok {
+				for _, item := range items {
+					if object, ok := item.(map[string]interface{}); ok {
+						next = append(next, object[key])
+					}
+				}
+			} else if object, ok := v.(map[string]interface{}); ok {
+				next = append(next, object[key])
+			}
+		}
+		values = next
+	}
+
+	ids := []uint{}
+	for _, v := range values {
+		if id := toID(v); id != 0 {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// toID mengubah angka JSON menjadi ID, 0 jika bukan ID yang valid
+func toID(value interface{}) uint {
+	id, ok := value.(float64)
+	if !ok || id <= 0 {
+		return 0
+	}
+	return uint(id)
+}
+
+// truncate memotong s menjadi maksimal max byte tanpa memotong karakter UTF-8
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	for max > 0 && !utf8.RuneStart(s[max]) {
+		max--
+	}
+	return s[:max]
+}
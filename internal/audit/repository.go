@@ -0,0 +1,54 @@
+package audit
+
+import "gorm.io/gorm"
+
+// Repository sengaja hanya bisa menambah dan membaca audit log
+type Repository interface {
+	Create(log *AuditLog) error
+	Find(filter ListFilter) ([]AuditLog, int64, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+// Create implements Repository.
+func (r *repository) Create(log *AuditLog) error {
+	return r.db.Create(log).Error
+}
+
+// Find implements Repository.
+// Terbaru dulu
+func (r *repository) Find(filter ListFilter) ([]AuditLog, int64, error) {
+	query := r.db.Model(&AuditLog{})
+	if filter.ActorID != 0 {
+		query = query.Where("actor_id = ?", filter.ActorID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.TargetType != "" {
+		query = query.Where("target_type = ?", filter.TargetType)
+	}
+	if filter.TargetID != 0 {
+		query = query.Where("target_id = ?", filter.TargetID)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var logs []AuditLog
+	err := query.Order("id desc").Offset(filter.Offset).Limit(filter.Limit).Find(&logs).Error
+	return logs, total, err
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
@@ -0,0 +1,15 @@
+package audit
+
+import (
+	"go-event/pkg/config"
+	"go-event/pkg/middlewares"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func SetupAuditRoutes(app *fiber.App, ctrl *Controller, cfg *config.Config) {
+	logs := app.Group("/api/audit-logs")
+
+	// Hanya admin yang bisa membaca audit log, tidak ada endpoint untuk mengubahnya
+	logs.Get("/", middlewares.Authenticate(cfg), middlewares.Authorize("admin"), ctrl.GetAuditLogs)
+}
@@ -0,0 +1,64 @@
+package audit
+
+import (
+	"go-event/pkg/apperror"
+	"go-event/pkg/pagination"
+	"go-event/pkg/validation"
+	"time"
+)
+
+type Service interface {
+	Record(log *AuditLog) error
+	ListLogs(query *ListQuery, page pagination.Params) (*AuditLogPage, error)
+}
+
+type service struct {
+	repo Repository
+}
+
+// Record implements Service.
+func (s *service) Record(log *AuditLog) error {
+	return s.repo.Create(log)
+}
+
+// ListLogs implements Service.
+// Rentang waktu inklusif di from dan eksklusif di to
+func (s *service) ListLogs(query *ListQuery, page pagination.Params) (*AuditLogPage, error) {
+	page.Normalize()
+	filter := ListFilter{
+		ActorID:    query.ActorID,
+		Action:     query.Action,
+		TargetType: query.TargetType,
+		TargetID:   query.TargetID,
+		Offset:     page.Offset(),
+		Limit:      page.Limit(),
+	}
+	// Format sudah divalidasi tag datetime
+	if query.From != "" {
+		from, _ := time.Parse(time.RFC3339, query.From)
+		filter.From = &from
+	}
+	if query.To != "" {
+		to, _ := time.Parse(time.RFC3339, query.To)
+		filter.To = &to
+	}
+	if filter.From != nil && filter.To != nil && !filter.To.After(*filter.From) {
+		return nil, validation.NewError("to", "gtfield", "from", "must be after from")
+	}
+
+	logs, total, err := s.repo.Find(filter)
+	if err != nil {
+		return nil, apperror.Internal(err)
+	}
+	if logs == nil {
+		logs = []AuditLog{}
+	}
+	return &AuditLogPage{
+		AuditLogs:  logs,
+		Pagination: pagination.NewMeta(page, total),
+	}, nil
+}
+
+func NewService(repo Repository) Service {
+	return &service{repo: repo}
+}
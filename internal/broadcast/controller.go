@@ -0,0 +1,125 @@
+package broadcast
+
+import (
+	"go-event/pkg/apperror"
+	"go-event/pkg/config"
+	"go-event/pkg/validation"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type Controller struct {
+	service Service
+	cfg     *config.Config
+}
+
+func NewController(service Service, cfg *config.Config) *Controller {
+	return &Controller{service: service, cfg: cfg}
+}
+
+// CreateBroadcast - kirim (atau jadwalkan) pengumuman ke participant event
+func (ctrl *Controller) CreateBroadcast(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	userRole := c.Locals("userRole").(string)
+
+	eventID, err := parseID(c, "id", "event ID")
+	if err != nil {
+		return err
+	}
+	var req CreateBroadcastRequest
+	if err := c.BodyParser(&req); err != nil {
+		return apperror.ErrInvalidBody
+	}
+	if err := validation.Struct(&req); err != nil {
+		return err
+	}
+
+	broadcast, err := ctrl.service.CreateBroadcast(userID, userRole, eventID, &req)
+	if err != nil {
+		return err
+	}
+
+	// Pengiriman berjalan di background, statistik dibaca lewat GET
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"message":   "broadcast accepted",
+		"broadcast": broadcast,
+	})
+}
+
+func (ctrl *Controller) GetBroadcasts(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	userRole := c.Locals("userRole").(string)
+
+	eventID, err := parseID(c, "id", "event ID")
+	if err != nil {
+		return err
+	}
+
+	broadcasts, err := ctrl.service.GetBroadcasts(userID, userRole, eventID)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message":    "broadcasts retrieved successfully",
+		"broadcasts": broadcasts,
+	})
+}
+
+func (ctrl *Controller) GetBroadcast(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	userRole := c.Locals("userRole").(string)
+
+	eventID, err := parseID(c, "id", "event ID")
+	if err != nil {
+		return err
+	}
+	broadcastID, err := parseID(c, "broadcastId", "broadcast ID")
+	if err != nil {
+		return err
+	}
+
+	broadcast, err := ctrl.service.GetBroadcast(userID, userRole, eventID, broadcastID)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message":   "broadcast retrieved successfully",
+		"broadcast": broadcast,
+	})
+}
+
+func (ctrl *Controller) CancelBroadcast(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	userRole := c.Locals("userRole").(string)
+
+	eventID, err := parseID(c, "id", "event ID")
+	if err != nil {
+		return err
+	}
+	broadcastID, err := parseID(c, "broadcastId", "broadcast ID")
+	if err != nil {
+		return err
+	}
+
+	broadcast, err := ctrl.service.CancelBroadcast(userID, userRole, eventID, broadcastID)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message":   "broadcast cancelled successfully",
+		"broadcast": broadcast,
+	})
+}
+
+// parseID membaca path param sebagai ID
+func parseID(c *fiber.Ctx, param, name string) (uint, error) {
+	id, err := strconv.ParseUint(c.Params(param), 10, 32)
+	if err != nil {
+		return 0, apperror.InvalidParam(name)
+	}
+	return uint(id), nil
+}
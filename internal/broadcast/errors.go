@@ -0,0 +1,10 @@
+package broadcast
+
+import "go-event/pkg/apperror"
+
+var (
+	ErrBroadcastNotFound = apperror.New(apperror.KindNotFound, "BROADCAST_NOT_FOUND", "broadcast not found")
+	ErrEventNotFound     = apperror.New(apperror.KindNotFound, "EVENT_NOT_FOUND", "event not found")
+	ErrForbidden         = apperror.New(apperror.KindForbidden, "BROADCAST_FORBIDDEN", "only the event organizer can send broadcasts")
+	ErrNotCancellable    = apperror.New(apperror.KindConflict, "BROADCAST_NOT_CANCELLABLE", "only scheduled broadcasts can be cancelled")
+)
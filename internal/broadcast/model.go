@@ -0,0 +1,118 @@
+package broadcast
+
+import (
+	"go-event/internal/participant"
+	"time"
+)
+
+type StatusType string
+
+const (
+	StatusScheduled StatusType = "scheduled"
+	StatusSending   StatusType = "sending"
+	StatusSent      StatusType = "sent"
+	StatusCancelled StatusType = "cancelled"
+)
+
+// Hasil pengiriman ke satu penerima
+const (
+	DeliverySent   = "sent"
+	DeliveryFailed = "failed"
+)
+
+// defaultStatuses adalah penerima jika filter status tidak dikirim: semua pendaftaran aktif
+var defaultStatuses = []participant.StatusType{participant.StatusRegistered, participant.StatusAttended, participant.StatusPendingApproval}
+
+// 🧱 Entity (database model)
+
+// Broadcast adalah pengumuman organizer ke participant satu event
+type Broadcast struct {
+	ID       uint `gorm:"primaryKey"`
+	EventID  uint `gorm:"index"`
+	SenderID uint
+	Subject  string `gorm:"size:150"`
+	Message  string `gorm:"type:text"`
+	// Status participant penerima, diisi defaultStatuses jika tidak dipilih
+	Statuses []participant.StatusType `gorm:"serializer:json;type:text"`
+	// Guest dari participant terkonfirmasi ikut dikirimi email
+	IncludeGuests bool
+	Status        StatusType `gorm:"size:16;index"`
+	ScheduledAt   *time.Time
+	SentAt        *time.Time
+	// Statistik diisi setelah pengiriman selesai
+	Recipients int
+	Sent       int
+	Failed     int
+	CreatedAt  time.Time
+}
+
+// BroadcastRecipient mencatat hasil pengiriman ke satu user atau guest.
+// NotificationID dipakai untuk menghitung berapa yang sudah dibaca
+type BroadcastRecipient struct {
+	ID             uint  `gorm:"primaryKey"`
+	BroadcastID    uint  `gorm:"index"`
+	UserID         *uint // nil untuk guest
+	GuestID        *uint
+	Email          string `gorm:"size:255"`
+	NotificationID *uint  `gorm:"index"` // nil jika in-app dimatikan user atau penerima adalah guest
+	Status         string `gorm:"size:16"`
+	Error          string `gorm:"size:255"`
+	CreatedAt      time.Time
+}
+
+// 📩 Request structs
+type CreateBroadcastRequest struct {
+	Subject string `json:"subject" validate:"required,notblank,max=150"`
+	Message string `json:"message" validate:"required,notblank,max=1800"`
+	// Kosong = semua pendaftaran aktif (registered, attended, pending_approval)
+	Statuses      []string   `json:"statuses" validate:"omitempty,max=5,dive,oneof=registered attended cancelled pending_approval rejected"`
+	IncludeGuests *bool      `json:"include_guests"`                           // default true
+	ScheduledAt   *time.Time `json:"scheduled_at" validate:"omitempty,future"` // kosong = kirim sekarang
+}
+
+// 📤 Response structs
+type BroadcastResponse struct {
+	ID            uint                     `json:"id"`
+	EventID       uint                     `json:"event_id"`
+	SenderID      uint                     `json:"sender_id"`
+	Subject       string                   `json:"subject"`
+	Message       string                   `json:"message"`
+	Statuses      []participant.StatusType `json:"statuses"`
+	IncludeGuests bool                     `json:"include_guests"`
+	Status        StatusType               `json:"status"`
+	ScheduledAt   *time.Time               `json:"scheduled_at,omitempty"`
+	SentAt        *time.Time               `json:"sent_at,omitempty"`
+	Stats         Stats                    `json:"stats"`
+	CreatedAt     time.Time                `json:"created_at"`
+}
+
+// Stats adalah statistik pengiriman. Read hanya menghitung notifikasi in-app
+// (guest tidak punya inbox)
+type Stats struct {
+	Recipients int   `json:"recipients"`
+	Sent       int   `json:"sent"`
+	Failed     int   `json:"failed"`
+	Read       int64 `json:"read"`
+}
+
+func (b *Broadcast) ToResponse(read int64) BroadcastResponse {
+	return BroadcastResponse{
+		ID:            b.ID,
+		EventID:       b.EventID,
+		SenderID:      b.SenderID,
+		Subject:       b.Subject,
+		Message:       b.Message,
+		Statuses:      b.Statuses,
+		IncludeGuests: b.IncludeGuests,
+		Status:        b.Status,
+		ScheduledAt:   b.ScheduledAt,
+		SentAt:        b.SentAt,
+		Stats: Stats{
+			Recipients: b.Recipients,
+			Sent:       b.Sent,
+			Failed:     b.Failed,
+			Read:       read,
+		},
+		CreatedAt: b.CreatedAt,
+	}
+}
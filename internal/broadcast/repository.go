@@ -0,0 +1,94 @@
+package broadcast
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Repository interface {
+	Create(broadcast *Broadcast) error
+	GetByID(id uint) (*Broadcast, error)
+	FindByEventID(eventID uint) ([]Broadcast, error)
+	Transition(id uint, from, to StatusType) (bool, error)
+	Complete(broadcast *Broadcast) error
+	CreateRecipients(recipients []BroadcastRecipient) error
+	CountRead(broadcastIDs []uint) (map[uint]int64, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+// Create implements Repository.
+func (r *repository) Create(broadcast *Broadcast) error {
+	return r.db.Create(broadcast).Error
+}
+
+// GetByID implements Repository.
+func (r *repository) GetByID(id uint) (*Broadcast, error) {
+	var broadcast Broadcast
+	if err := r.db.Where("id = ?", id).Take(&broadcast).Error; err != nil {
+		return nil, err
+	}
+	return &broadcast, nil
+}
+
+// FindByEventID implements Repository.
+func (r *repository) FindByEventID(eventID uint) ([]Broadcast, error) {
+	var broadcasts []Broadcast
+	err := r.db.Where("event_id = ?", eventID).Order("created_at desc").Find(&broadcasts).Error
+	return broadcasts, err
+}
+
+// Transition implements Repository.
+// Mengubah status hanya jika status saat ini from, false jika sudah diubah proses lain
+func (r *repository) Transition(id uint, from, to StatusType) (bool, error) {
+	result := r.db.Model(&Broadcast{}).Where("id = ? AND status = ?", id, from).Update("status", to)
+	return result.RowsAffected == 1, result.Error
+}
+
+// Complete implements Repository.
+// Menyimpan statistik dan menandai broadcast terkirim
+func (r *repository) Complete(broadcast *Broadcast) error {
+	now := time.Now()
+	broadcast.Status = StatusSent
+	broadcast.SentAt = &now
+	return r.db.Model(broadcast).Select("status", "sent_at", "recipients", "sent", "failed").Updates(broadcast).Error
+}
+
+// CreateRecipients implements Repository.
+func (r *repository) CreateRecipients(recipients []BroadcastRecipient) error {
+	if len(recipients) == 0 {
+		return nil
+	}
+	return r.db.CreateInBatches(recipients, 200).Error
+}
+
+// CountRead implements Repository.
+// Jumlah notifikasi broadcast yang sudah dibaca. Notifikasi yang sudah dihapus user
+// (atau oleh retention) tidak terhitung
+func (r *repository) CountRead(broadcastIDs []uint) (map[uint]int64, error) {
+	counts := make(map[uint]int64, len(broadcastIDs))
+	if len(broadcastIDs) == 0 {
+		return counts, nil
+	}
+	var rows []struct {
+		BroadcastID uint
+		Count       int64
+	}
+	err := r.db.Model(&BroadcastRecipient{}).
+		Select("broadcast_recipients.broadcast_id, COUNT(*) AS count").
+		Joins("JOIN notifications ON notifications.id = broadcast_recipients.notification_id").
+		Where("broadcast_recipients.broadcast_id IN ? AND notifications.is_read = ?", broadcastIDs, true).
+		Group("broadcast_recipients.broadcast_id").
+		Scan(&rows).Error
+	for _, row := range rows {
+		counts[row.BroadcastID] = row.Count
+	}
+	return counts, err
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
@@ -0,0 +1,20 @@
+package broadcast
+
+import (
+	"go-event/pkg/config"
+	"go-event/pkg/idempotency"
+	"go-event/pkg/middlewares"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func SetupBroadcastRoutes(app *fiber.App, ctrl *Controller, cfg *config.Config, idempotencyStore idempotency.Repository) {
+	broadcasts := app.Group("/api/event/:id/broadcasts")
+
+	// Organizer pemilik event (atau admin). Idempotency-Key opsional agar retry
+	// tidak mengirim pengumuman dua kali
+	broadcasts.Post("/", middlewares.Authenticate(cfg), middlewares.Authorize("organizer", "admin"), middlewares.Idempotency(idempotencyStore), ctrl.CreateBroadcast)
+	broadcasts.Get("/", middlewares.Authenticate(cfg), middlewares.Authorize("organizer", "admin"), ctrl.GetBroadcasts)
+	broadcasts.Get("/:broadcastId", middlewares.Authenticate(cfg), middlewares.Authorize("organizer", "admin"), ctrl.GetBroadcast)
+	broadcasts.Post("/:broadcastId/cancel", middlewares.Authenticate(cfg), middlewares.Authorize("organizer", "admin"), ctrl.CancelBroadcast)
+}
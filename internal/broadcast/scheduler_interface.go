@@ -0,0 +1,9 @@
+package broadcast
+
+import "time"
+
+// Scheduler interface ke package schedule untuk menghindari circular dependency
+// (schedule import broadcast untuk menjalankan job)
+type Scheduler interface {
+	ScheduleBroadcast(eventID, broadcastID uint, runAt time.Time) error
+}
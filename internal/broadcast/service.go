@@ -0,0 +1,280 @@
+package broadcast
+
+import (
+	"errors"
+	"fmt"
+	"go-event/internal/event"
+	"go-event/internal/notification"
+	"go-event/internal/participant"
+	"go-event/pkg/apperror"
+	"go-event/pkg/config"
+	"go-event/pkg/i18n"
+	"log"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+type Service interface {
+	CreateBroadcast(requesterID uint, requesterRole string, eventID uint, req *CreateBroadcastRequest) (*BroadcastResponse, error)
+	GetBroadcasts(requesterID uint, requesterRole string, eventID uint) ([]BroadcastResponse, error)
+	GetBroadcast(requesterID uint, requesterRole string, eventID, broadcastID uint) (*BroadcastResponse, error)
+	CancelBroadcast(requesterID uint, requesterRole string, eventID, broadcastID uint) (*BroadcastResponse, error)
+	Deliver(broadcastID uint) error
+}
+
+// recipientBatch adalah jumlah participant yang diproses (dan dicatat) per batch
+const recipientBatch = 200
+
+type service struct {
+	repo            Repository
+	eventRepo       event.Repository
+	participantRepo participant.Repository
+	notifService    notification.Service
+	scheduler       Scheduler
+	cfg             *config.Config
+}
+
+// CreateBroadcast implements Service.
+// Tanpa scheduled_at broadcast langsung dikirim di background (status sending),
+// selain itu dijadwalkan lewat schedule job
+func (s *service) CreateBroadcast(requesterID uint, requesterRole string, eventID uint, req *CreateBroadcastRequest) (*BroadcastResponse, error) {
+	if _, err := s.getManagedEvent(requesterID, requesterRole, eventID); err != nil {
+		return nil, err
+	}
+
+	statuses := defaultStatuses
+	if len(req.Statuses) > 0 {
+		statuses = make([]participant.StatusType, 0, len(req.Statuses))
+		for _, status := range req.Statuses {
+			statuses = append(statuses, participant.StatusType(status))
+		}
+	}
+	broadcast := &Broadcast{
+		EventID:       eventID,
+		SenderID:      requesterID,
+		Subject:       strings.TrimSpace(req.Subject),
+		Message:       strings.TrimSpace(req.Message),
+		Statuses:      statuses,
+		IncludeGuests: req.IncludeGuests == nil || *req.IncludeGuests,
+		Status:        StatusSending,
+	}
+	if req.ScheduledAt != nil {
+		scheduledAt := *req.ScheduledAt
+		broadcast.Status = StatusScheduled
+		broadcast.ScheduledAt = &scheduledAt
+	}
+	if err := s.repo.Create(broadcast); err != nil {
+		return nil, apperror.Internal(fmt.Errorf("failed to create broadcast: %w", err))
+	}
+
+	response := broadcast.ToResponse(0)
+	if broadcast.Status == StatusScheduled {
+		if err := s.scheduler.ScheduleBroadcast(eventID, broadcast.ID, *broadcast.ScheduledAt); err != nil {
+			if _, cancelErr := s.repo.Transition(broadcast.ID, StatusScheduled, StatusCancelled); cancelErr != nil {
+				log.Printf("broadcast: failed to cancel unscheduled broadcast %d: %v", broadcast.ID, cancelErr)
+			}
+			return nil, apperror.Internal(fmt.Errorf("failed to schedule broadcast: %w", err))
+		}
+		return &response, nil
+	}
+
+	go s.deliver(broadcast)
+	return &response, nil
+}
+
+// GetBroadcasts implements Service.
+func (s *service) GetBroadcasts(requesterID uint, requesterRole string, eventID uint) ([]BroadcastResponse, error) {
+	if _, err := s.getManagedEvent(requesterID, requesterRole, eventID); err != nil {
+		return nil, err
+	}
+	broadcasts, err := s.repo.FindByEventID(eventID)
+	if err != nil {
+		return nil, apperror.Internal(err)
+	}
+
+	ids := make([]uint, 0, len(broadcasts))
+	for _, b := range broadcasts {
+		ids = append(ids, b.ID)
+	}
+	read, err := s.repo.CountRead(ids)
+	if err != nil {
+		return nil, apperror.Internal(err)
+	}
+
+	responses := make([]BroadcastResponse, 0, len(broadcasts))
+	for i := range broadcasts {
+		responses = append(responses, broadcasts[i].ToResponse(read[broadcasts[i].ID]))
+	}
+	return responses, nil
+}
+
+// GetBroadcast implements Service.
+func (s *service) GetBroadcast(requesterID uint, requesterRole string, eventID, broadcastID uint) (*BroadcastResponse, error) {
+	broadcast, err := s.getEventBroadcast(requesterID, requesterRole, eventID, broadcastID)
+	if err != nil {
+		return nil, err
+	}
+	read, err := s.repo.CountRead([]uint{broadcast.ID})
+	if err != nil {
+		return nil, apperror.Internal(err)
+	}
+	response := broadcast.ToResponse(read[broadcast.ID])
+	return &response, nil
+}
+
+// CancelBroadcast implements Service.
+// Hanya broadcast terjadwal; schedule job-nya tetap ada tapi tidak mengirim apa pun
+func (s *service) CancelBroadcast(requesterID uint, requesterRole string, eventID, broadcastID uint) (*BroadcastResponse, error) {
+	broadcast, err := s.getEventBroadcast(requesterID, requesterRole, eventID, broadcastID)
+	if err != nil {
+		return nil, err
+	}
+	cancelled, err := s.repo.Transition(broadcast.ID, StatusScheduled, StatusCancelled)
+	if err != nil {
+		return nil, apperror.Internal(err)
+	}
+	if !cancelled {
+		return nil, ErrNotCancellable
+	}
+	broadcast.Status = StatusCancelled
+	response := broadcast.ToResponse(0)
+	return &response, nil
+}
+
+// Deliver implements Service.
+// Dipanggil scheduler saat scheduled_at tercapai. Broadcast yang sudah dibatalkan
+// atau sedang dikirim dilewati
+func (s *service) Deliver(broadcastID uint) error {
+	claimed, err := s.repo.Transition(broadcastID, StatusScheduled, StatusSending)
+	if err != nil {
+		return fmt.Errorf("failed to claim broadcast %d: %w", broadcastID, err)
+	}
+	if !claimed {
+		log.Printf("broadcast: skipping broadcast %d, no longer scheduled", broadcastID)
+		return nil
+	}
+	broadcast, err := s.repo.GetByID(broadcastID)
+	if err != nil {
+		return fmt.Errorf("failed to get broadcast %d: %w", broadcastID, err)
+	}
+	s.deliver(broadcast)
+	return nil
+}
+
+// deliver mengirim broadcast ke setiap participant sesuai filter status (notifikasi
+// in-app + email mengikuti preferensi user) dan guest-nya (email saja), lalu
+// menyimpan hasil per penerima dan statistiknya
+func (s *service) deliver(broadcast *Broadcast) {
+	message := broadcast.Subject + "\n\n" + broadcast.Message
+	err := s.participantRepo.EachByEventID(broadcast.EventID, broadcast.Statuses, recipientBatch, func(batch []participant.Participant) error {
+		recipients := make([]BroadcastRecipient, 0, len(batch))
+		for i := range batch {
+			p := &batch[i]
+			recipients = append(recipients, s.sendToUser(broadcast, p, message))
+			// Guest dari pendaftar pending belum menerima tiket
+			if !broadcast.IncludeGuests || !p.Status.IsConfirmed() {
+				continue
+			}
+			for j := range p.Guests {
+				recipients = append(recipients, s.sendToGuest(broadcast, &p.Guests[j], message, i18n.Resolve(p.User.Locale)))
+			}
+		}
+		for _, r := range recipients {
+			broadcast.Recipients++
+			if r.Status == DeliverySent {
+				broadcast.Sent++
+			} else {
+				broadcast.Failed++
+			}
+		}
+		return s.repo.CreateRecipients(recipients)
+	})
+	if err != nil {
+		// Statistik tetap disimpan untuk penerima yang sudah diproses
+		log.Printf("broadcast: delivery of broadcast %d stopped: %v", broadcast.ID, err)
+	}
+	if err := s.repo.Complete(broadcast); err != nil {
+		log.Printf("broadcast: failed to save stats of broadcast %d: %v", broadcast.ID, err)
+	}
+}
+
+func (s *service) sendToUser(broadcast *Broadcast, p *participant.Participant, message string) BroadcastRecipient {
+	userID := p.UserID
+	recipient := BroadcastRecipient{BroadcastID: broadcast.ID, UserID: &userID, Email: p.User.Email, Status: DeliverySent}
+	notif, err := s.notifService.CreateNotificationWithEmail(&notification.CreateNotificationRequest{
+		UserID:  p.UserID,
+		EventID: &broadcast.EventID,
+		Type:    string(notification.NotifAnnouncement),
+		Message: message,
+	}, p.User.Email, p.User.Name)
+	if err != nil {
+		recipient.Status, recipient.Error = DeliveryFailed, truncate(err.Error())
+		return recipient
+	}
+	if notif != nil {
+		recipient.NotificationID = &notif.ID
+	}
+	return recipient
+}
+
+func (s *service) sendToGuest(broadcast *Broadcast, g *participant.Guest, message string, locale i18n.Locale) BroadcastRecipient {
+	guestID := g.ID
+	recipient := BroadcastRecipient{BroadcastID: broadcast.ID, GuestID: &guestID, Email: g.Email, Status: DeliverySent}
+	if err := s.notifService.SendGuestEmail(broadcast.EventID, notification.NotifAnnouncement, message, g.Email, g.Name, locale); err != nil {
+		recipient.Status, recipient.Error = DeliveryFailed, truncate(err.Error())
+	}
+	return recipient
+}
+
+// truncate memotong pesan error agar muat di kolom
+func truncate(s string) string {
+	if len(s) > 255 {
+		return s[:255]
+	}
+	return s
+}
+
+// getManagedEvent memastikan event ada dan requester adalah organizer-nya (atau admin)
+func (s *service) getManagedEvent(requesterID uint, requesterRole string, eventID uint) (*event.Event, error) {
+	ev, err := s.eventRepo.GetByID(eventID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrEventNotFound
+		}
+		return nil, apperror.Internal(err)
+	}
+	if requesterRole != "admin" && ev.OrganizerID != requesterID {
+		return nil, ErrForbidden
+	}
+	return ev, nil
+}
+
+// getEventBroadcast mengambil broadcast milik event yang dikelola requester
+func (s *service) getEventBroadcast(requesterID uint, requesterRole string, eventID, broadcastID uint) (*Broadcast, error) {
+	if _, err := s.getManagedEvent(requesterID, requesterRole, eventID); err != nil {
+		return nil, err
+	}
+	broadcast, err := s.repo.GetByID(broadcastID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrBroadcastNotFound
+		}
+		return nil, apperror.Internal(err)
+	}
+	if broadcast.EventID != eventID {
+		return nil, ErrBroadcastNotFound
+	}
+	return broadcast, nil
+}
+
+func NewService(repo Repository, eventRepo event.Repository, participantRepo participant.Repository, notifService notification.Service, scheduler Scheduler, cfg *config.Config) Service {
+	return &service{
+		repo:            repo,
+		eventRepo:       eventRepo,
+		participantRepo: participantRepo,
+		notifService:    notifService,
+		scheduler:       scheduler,
+		cfg:             cfg,
+	}
+}
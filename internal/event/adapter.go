@@ -1,33 +1,44 @@
 package event
 
 import (
-	"go-event/internal/participant"
+	"go-event/internal/venue"
+	"time"
 )
 
-// EventRepositoryAdapter mengadaptasi event.Repository ke participant.EventRepository
-type EventRepositoryAdapter struct {
-repo Repository
+// RoomBookingAdapter mengadaptasi event.Repository ke venue.BookingRepository
+type RoomBookingAdapter struct {
+	repo Repository
 }
 
-// NewEventRepositoryAdapter membuat adapter baru
-func NewEventRepositoryAdapter(repo Repository) participant.EventRepository {
-return &EventRepositoryAdapter{repo: repo}
+// NewRoomBookingAdapter membuat adapter baru
+func NewRoomBookingAdapter(repo Repository) venue.BookingRepository {
+	return &RoomBookingAdapter{repo: repo}
 }
 
-// GetByID implements participant.EventRepository
-func (a *EventRepositoryAdapter) GetByID(id uint) (*participant.EventInfo, error) {
-event, err := a.repo.GetByID(id)
-if err != nil {
-return nil, err
+// FindBookings implements venue.BookingRepository
+func (a *RoomBookingAdapter) FindBookings(roomID uint, from, to time.Time) ([]venue.Booking, error) {
+	events, err := a.repo.FindOverlappingInRoom(roomID, from, to, 0)
+	if err != nil {
+		return nil, err
+	}
+	return toBookings(events), nil
 }
 
-return &participant.EventInfo{
-ID:          event.ID,
-Title:       event.Title,
-Description: event.Description,
-Location:    event.Location,
-StartTime:   event.StartTime,
-EndTime:     event.EndTime,
-OrganizerID: event.OrganizerID,
-}, nil
+// HasBookings implements venue.BookingRepository
+func (a *RoomBookingAdapter) HasBookings(roomIDs ...uint) (bool, error) {
+	return a.repo.HasEventsInRooms(roomIDs)
+}
+
+// toBookings mengubah event menjadi booking room (juga dipakai sebagai detail error bentrok)
+func toBookings(events []*Event) []venue.Booking {
+	bookings := make([]venue.Booking, 0, len(events))
+	for _, e := range events {
+		bookings = append(bookings, venue.Booking{
+			EventID:   e.ID,
+			Title:     e.Title,
+			StartTime: e.StartTime,
+			EndTime:   e.EndTime,
+		})
+	}
+	return bookings
 }
@@ -1,20 +1,26 @@
 package event
 
 import (
+	"bytes"
+	"fmt"
+	"go-event/pkg/apperror"
 	"go-event/pkg/config"
+	"go-event/pkg/ics"
+	"go-event/pkg/validation"
 	"strconv"
 
 	"github.com/gofiber/fiber/v2"
 )
+
 type Controller struct {
 	service Service
 	cfg     *config.Config
 }
 
-func NewController(service Service, cfg *config.Config ) *Controller {
+func NewController(service Service, cfg *config.Config) *Controller {
 	return &Controller{
 		service: service,
-		cfg: cfg,
+		cfg:     cfg,
 	}
 }
 
@@ -23,17 +29,15 @@ func (ctrl *Controller) CreateEvent(c *fiber.Ctx) error {
 
 	var req CreateEventRequest
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "invalid request body",
-		})
+		return apperror.ErrInvalidBody
+	}
+	if err := validation.Struct(&req); err != nil {
+		return err
 	}
 
 	eventResponse, err := ctrl.service.CreateEvent(userID, &req)
-
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"message": err.Error(),
-		})
+		return err
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
@@ -46,79 +50,51 @@ func (ctrl *Controller) GetAllEventByUserID(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uint)
 	events, err := ctrl.service.GetEventByUserID(userID)
 	if err != nil {
-    return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-        "message": "failed to retrieve events",
-        "error":   err.Error(),
-    })
-}
+		return err
+	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"message": "events retrieved successfully",
-		"events": events,
+		"events":  events,
 	})
 }
 
 func (ctrl *Controller) UpdateEvent(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uint)
-	id := c.Params("id")
 
-	eventId, err := strconv.ParseUint(id, 10, 32)
+	eventId, err := parseEventID(c)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "invalid event id",
-		})
+		return err
 	}
 
 	var req UpdateEventRequest
 	if err := c.BodyParser(&req); err != nil {
-		return  c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "invalid request body",
-		})
+		return apperror.ErrInvalidBody
+	}
+	if err := validation.Struct(&req); err != nil {
+		return err
 	}
 
-	updatedEvent, err := ctrl.service.UpdateEvent(userID, uint(eventId), &req)
+	updatedEvent, err := ctrl.service.UpdateEvent(userID, eventId, &req)
 	if err != nil {
-		statusCode := fiber.StatusInternalServerError
-		if err.Error() == "event not found" {
-			statusCode = fiber.StatusNotFound
-		}else if err.Error()== "unauthorized to update this event"{
-			statusCode = fiber.StatusUnauthorized
-		}else if err.Error()== "invalid event data"{
-			statusCode = fiber.StatusBadRequest
-		}
-		return  c.Status(statusCode).JSON(fiber.Map{
-			"message": err.Error(),
-		})
+		return err
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-	"message": "event updated successfully",
-	"event":   updatedEvent,
-})
-
+		"message": "event updated successfully",
+		"event":   updatedEvent,
+	})
 }
 
 func (ctrl *Controller) DeleteEvent(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uint)
-	id := c.Params("id")
 
-	eventId, err := strconv.ParseUint(id, 10, 32)
+	eventId, err := parseEventID(c)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "invalid event id",
-		})
+		return err
 	}
-	err = ctrl.service.DeleteEvent(userID, uint(eventId))
-	if err != nil {
-		statusCode := fiber.StatusInternalServerError
-		if err.Error() == "event not found" {
-			statusCode = fiber.StatusNotFound
-		}else if err.Error()== "unauthorized to delete this event"{
-			statusCode = fiber.StatusUnauthorized
-		}
-		return c.Status(statusCode).JSON(fiber.Map{
-			"message": err.Error(),
-		})
+	if err := ctrl.service.DeleteEvent(userID, eventId); err != nil {
+		return err
 	}
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"message": "event deleted successfully",
@@ -126,26 +102,132 @@ func (ctrl *Controller) DeleteEvent(c *fiber.Ctx) error {
 }
 
 func (ctrl *Controller) GetEventByID(c *fiber.Ctx) error {
-	id := c.Params("id")
-
-	eventId, err := strconv.ParseUint(id, 10, 32)
+	eventId, err := parseEventID(c)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "invalid event id",
-		})
+		return err
 	}
-	event, err := ctrl.service.GetEventByID(uint(eventId))
+	event, err := ctrl.service.GetEventByID(eventId)
 	if err != nil {
-		statusCode := fiber.StatusInternalServerError
-		if err.Error() == "event not found" {
-			statusCode = fiber.StatusNotFound
-		}
-		return c.Status(statusCode).JSON(fiber.Map{
-			"message": err.Error(),
-		})
+		return err
 	}
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"message": "event retrieved successfully",
 		"event":   event,
 	})
-}
\ No newline at end of file
+}
+
+// PublishEvent - draft -> published, event mulai bisa didaftari participant
+func (ctrl *Controller) PublishEvent(c *fiber.Ctx) error {
+	return ctrl.changeStatus(c, ctrl.service.PublishEvent, "event published successfully")
+}
+
+// UnpublishEvent - published -> draft, hanya jika belum ada participant
+func (ctrl *Controller) UnpublishEvent(c *fiber.Ctx) error {
+	return ctrl.changeStatus(c, ctrl.service.UnpublishEvent, "event unpublished successfully")
+}
+
+// CloseRegistration - published -> registration_closed
+func (ctrl *Controller) CloseRegistration(c *fiber.Ctx) error {
+	return ctrl.changeStatus(c, ctrl.service.CloseRegistration, "event registration closed successfully")
+}
+
+// CancelEvent - ubah status ke cancelled dan kirim notifikasi ke participant
+func (ctrl *Controller) CancelEvent(c *fiber.Ctx) error {
+	return ctrl.changeStatus(c, ctrl.service.CancelEvent, "event cancelled successfully")
+}
+
+// GetEventCalendar - download event sebagai file .ics untuk aplikasi kalender
+func (ctrl *Controller) GetEventCalendar(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	userRole := c.Locals("userRole").(string)
+
+	eventId, err := parseEventID(c)
+	if err != nil {
+		return err
+	}
+
+	calendar, err := ctrl.service.GetCalendar(userID, userRole, eventId)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := calendar.Write(&buf); err != nil {
+		return apperror.Internal(err)
+	}
+	c.Set(fiber.HeaderContentType, ics.ContentType)
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="event-%d.ics"`, eventId))
+	return c.Send(buf.Bytes())
+}
+
+// GetRegistrationForm - pertanyaan yang harus dijawab saat mendaftar
+func (ctrl *Controller) GetRegistrationForm(c *fiber.Ctx) error {
+	eventId, err := parseEventID(c)
+	if err != nil {
+		return err
+	}
+
+	questions, err := ctrl.service.GetRegistrationForm(eventId)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message":   "registration form retrieved successfully",
+		"questions": questions,
+	})
+}
+
+// UpdateRegistrationForm - ganti seluruh pertanyaan form pendaftaran
+func (ctrl *Controller) UpdateRegistrationForm(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	eventId, err := parseEventID(c)
+	if err != nil {
+		return err
+	}
+
+	var req UpdateRegistrationFormRequest
+	if err := c.BodyParser(&req); err != nil {
+		return apperror.ErrInvalidBody
+	}
+
+	event, err := ctrl.service.UpdateRegistrationForm(userID, eventId, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "registration form updated successfully",
+		"event":   event,
+	})
+}
+
+// changeStatus adalah handler bersama untuk semua endpoint transisi status
+func (ctrl *Controller) changeStatus(c *fiber.Ctx, action func(userID, eventID uint) (*EventResponse, error), successMessage string) error {
+	userID := c.Locals("userID").(uint)
+
+	eventId, err := parseEventID(c)
+	if err != nil {
+		return err
+	}
+
+	event, err := action(userID, eventId)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": successMessage,
+		"event":   event,
+	})
+}
+
+// parseEventID membaca path param :id
+func parseEventID(c *fiber.Ctx) (uint, error) {
+	eventId, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return 0, apperror.InvalidParam("event id")
+	}
+	return uint(eventId), nil
+}
@@ -0,0 +1,15 @@
+package event
+
+import "go-event/pkg/apperror"
+
+var (
+	ErrEventNotFound        = apperror.New(apperror.KindNotFound, "EVENT_NOT_FOUND", "event not found")
+	ErrNotOrganizer         = apperror.New(apperror.KindForbidden, "EVENT_FORBIDDEN", "unauthorized to modify this event")
+	ErrMissingFields        = apperror.New(apperror.KindBadRequest, "EVENT_MISSING_FIELDS", "all fields are required")
+	ErrEventFinalized       = apperror.New(apperror.KindConflict, "EVENT_FINALIZED", "event can no longer be modified")
+	ErrEventAlreadyStarted  = apperror.New(apperror.KindConflict, "EVENT_ALREADY_STARTED", "cannot publish an event that has already started")
+	ErrEventHasParticipants = apperror.New(apperror.KindConflict, "EVENT_HAS_PARTICIPANTS", "cannot unpublish an event that already has participants")
+	ErrRoomConflict         = apperror.New(apperror.KindConflict, "EVENT_ROOM_CONFLICT", "room is already booked for the selected time")
+	// ErrInvalidTransition dikembalikan jika perpindahan status tidak diizinkan
+	ErrInvalidTransition = apperror.New(apperror.KindConflict, "EVENT_INVALID_TRANSITION", "invalid status transition")
+)
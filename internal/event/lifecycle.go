@@ -0,0 +1,46 @@
+package event
+
+import "go-event/internal/eventbus"
+
+// allowedTransitions mendefinisikan state machine lifecycle event.
+// completed dan cancelled adalah status akhir.
+var allowedTransitions = map[EventStatus][]EventStatus{
+	StatusDraft:              {StatusPublished, StatusCancelled},
+	StatusPublished:          {StatusDraft, StatusRegistrationClosed, StatusOngoing, StatusCancelled},
+	StatusRegistrationClosed: {StatusPublished, StatusOngoing, StatusCancelled},
+	StatusOngoing:            {StatusCompleted, StatusCancelled},
+	StatusCompleted:          {},
+	StatusCancelled:          {},
+}
+
+// statusEvents membuat domain event yang dikirim saat event pindah ke status tersebut.
+// Kembali ke draft dan menutup pendaftaran tidak dikirim
+var statusEvents = map[EventStatus]func(eventbus.Meta, eventbus.EventSnapshot) eventbus.Event{
+	StatusPublished: func(m eventbus.Meta, e eventbus.EventSnapshot) eventbus.Event {
+		return eventbus.EventPublished{Meta: m, Event: e}
+	},
+	StatusCancelled: func(m eventbus.Meta, e eventbus.EventSnapshot) eventbus.Event {
+		return eventbus.EventCancelled{Meta: m, Event: e}
+	},
+	StatusOngoing: func(m eventbus.Meta, e eventbus.EventSnapshot) eventbus.Event {
+		return eventbus.EventStarted{Meta: m, Event: e}
+	},
+	StatusCompleted: func(m eventbus.Meta, e eventbus.EventSnapshot) eventbus.Event {
+		return eventbus.EventCompleted{Meta: m, Event: e}
+	},
+}
+
+// CanTransition mengecek apakah event boleh pindah dari status from ke status to
+func CanTransition(from, to EventStatus) bool {
+	for _, next := range allowedTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// IsFinal mengembalikan true untuk status yang tidak bisa diubah lagi
+func (s EventStatus) IsFinal() bool {
+	return s == StatusCompleted || s == StatusCancelled
+}
@@ -1,10 +1,25 @@
 package event
 
 import (
+	"go-event/internal/eventbus"
 	"go-event/internal/user"
+	"go-event/pkg/i18n"
+	"go-event/pkg/regform"
+	"go-event/pkg/timezone"
 	"time"
 )
 
+type EventStatus string
+
+const (
+	StatusDraft              EventStatus = "draft"
+	StatusPublished          EventStatus = "published"
+	StatusRegistrationClosed EventStatus = "registration_closed"
+	StatusOngoing            EventStatus = "ongoing"
+	StatusCompleted          EventStatus = "completed"
+	StatusCancelled          EventStatus = "cancelled"
+)
+
 // 🧱 Entity (database model)
 type Event struct {
 	ID          uint      `json:"id" gorm:"primaryKey"`
@@ -13,29 +28,95 @@ type Event struct {
 	Location    string    `json:"location"`
 	StartTime   time.Time `json:"start_time"`
 	EndTime     time.Time `json:"end_time"`
+	// Timezone IANA tempat event berlangsung (contoh Asia/Jakarta). StartTime/EndTime tetap
+	// disimpan dalam UTC, timezone dipakai untuk menampilkan waktu ke penerima yang belum memilih timezone
+	Timezone    string    `json:"timezone" gorm:"size:64;default:UTC"`
 	OrganizerID uint      `json:"organizer_id"`
 	Organizer   user.User `json:"organizer" gorm:"foreignKey:OrganizerID"` // relasi ke User
+	RoomID      *uint     `json:"room_id" gorm:"index"`                     // opsional, room dari katalog venue
+	// Pertanyaan tambahan saat pendaftaran, jawaban disimpan di participant.Participant.Answers
+	RegistrationForm []regform.Question `json:"registration_form" gorm:"serializer:json;type:text"`
+	// Pendaftar harus di-approve organizer sebelum dihitung sebagai participant
+	RequiresApproval bool `json:"requires_approval" gorm:"default:false"`
+	// Batas participant yang sudah di-approve (termasuk guest), 0 = tanpa batas
+	Capacity int `json:"capacity" gorm:"default:0"`
+	// Default published agar event lama (sebelum ada lifecycle) tetap live setelah migrasi,
+	// event baru selalu dibuat sebagai draft oleh service
+	Status EventStatus `json:"status" gorm:"size:32;index;default:'published'"`
 
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+// FieldChange adalah satu perubahan event yang dikirim ke participant. Field dipakai
+// untuk menggabungkan perubahan berulang pada field yang sama (hanya yang terakhir dikirim).
+// Pesannya ditulis saat dikirim, dalam bahasa masing-masing participant
+type FieldChange struct {
+	Field string     `json:"field"`
+	Value string     `json:"value,omitempty"`
+	Time  *time.Time `json:"time,omitempty"` // untuk start_time / end_time
+}
+
+// Render menulis perubahan dalam bahasa locale, waktu ditulis dalam timezone loc
+func (c FieldChange) Render(locale i18n.Locale, loc *time.Location) string {
+	key := "notification.change." + c.Field
+	switch {
+	case c.Time != nil:
+		return i18n.T(locale, key, i18n.FormatDateTime(locale, c.Time.In(loc)))
+	case c.Value != "":
+		return i18n.T(locale, key, c.Value)
+	}
+	return i18n.T(locale, key)
+}
+
+// NotifiableChanges memilih perubahan dari EventUpdated yang dikabarkan ke participant:
+// judul, deskripsi, lokasi, dan waktu. Pengaturan pendaftaran, room, dan timezone tidak
+func NotifiableChanges(changes []eventbus.Change) []FieldChange {
+	var result []FieldChange
+	for _, c := range changes {
+		switch c.Field {
+		case "title", "location":
+			value, _ := c.New.(string)
+			result = append(result, FieldChange{Field: c.Field, Value: value})
+		case "description":
+			result = append(result, FieldChange{Field: c.Field})
+		case "start_time", "end_time":
+			if t, ok := c.New.(time.Time); ok {
+				result = append(result, FieldChange{Field: c.Field, Time: &t})
+			}
+		}
+	}
+	return result
+}
+
 // 📩 Request structs
 type CreateEventRequest struct {
-	Title       string    `json:"title" validate:"required"`
-	Description string    `json:"description" validate:"required"`
-	Location    string    `json:"location" validate:"required"`
-	StartTime   time.Time `json:"start_time" validate:"required"`
-	EndTime     time.Time `json:"end_time" validate:"required"`
-	OrganizerID uint      `json:"organizer_id" validate:"required"`
+	Title       string    `json:"title" validate:"required,notblank,max=200"`
+	Description string    `json:"description" validate:"required,notblank,max=5000"`
+	Location    string    `json:"location" validate:"required_without=RoomID,max=255"` // diisi dari venue jika kosong
+	StartTime   time.Time `json:"start_time" validate:"required,future"`
+	EndTime     time.Time `json:"end_time" validate:"required,gtfield=StartTime"`
+	Timezone    string    `json:"timezone" validate:"omitempty,timezone"` // kosong = timezone organizer, lalu UTC
+	RoomID      *uint     `json:"room_id" validate:"omitempty,gt=0"`
+	RequiresApproval bool `json:"requires_approval"`
+	Capacity    int       `json:"capacity" validate:"gte=0"`
+	OrganizerID uint      `json:"organizer_id"` // diisi dari token, bukan dari body
 }
 
 type UpdateEventRequest struct {
-	Title       *string    `json:"title"`
-	Description *string    `json:"description"`
-	Location    *string    `json:"location"`
-	StartTime   *time.Time `json:"start_time"`
-	EndTime     *time.Time `json:"end_time"`
+	Title       *string    `json:"title" validate:"omitempty,notblank,max=200"`
+	Description *string    `json:"description" validate:"omitempty,notblank,max=5000"`
+	Location    *string    `json:"location" validate:"omitempty,notblank,max=255"`
+	StartTime   *time.Time `json:"start_time" validate:"omitempty,future"`
+	EndTime     *time.Time `json:"end_time" validate:"omitempty,future"`
+	Timezone    *string    `json:"timezone" validate:"omitempty,timezone"`
+	RoomID      *uint      `json:"room_id"` // 0 = lepas event dari room
+	RequiresApproval *bool `json:"requires_approval"`
+	Capacity    *int       `json:"capacity" validate:"omitempty,gte=0"`
+}
+
+type UpdateRegistrationFormRequest struct {
+	Questions []regform.Question `json:"questions"`
 }
 
 // 📤 Response structs
@@ -46,6 +127,76 @@ type EventResponse struct {
 	Location    string                `json:"location"`
 	StartTime   time.Time             `json:"start_time"`
 	EndTime     time.Time             `json:"end_time"`
+	Timezone    string                `json:"timezone"`
 	OrganizerID uint    							`json:"organizer_id"`
+	RoomID      *uint                 `json:"room_id"`
+	Status      EventStatus           `json:"status"`
+	RegistrationForm []regform.Question `json:"registration_form"`
+	RequiresApproval bool                `json:"requires_approval"`
+	Capacity    int                   `json:"capacity"`
 	CreatedAt   time.Time             `json:"created_at"`
 }
+
+func (e *Event) ToResponse() *EventResponse {
+	return &EventResponse{
+		ID:          e.ID,
+		Title:       e.Title,
+		Description: e.Description,
+		Location:    e.Location,
+		StartTime:   e.StartTime,
+		EndTime:     e.EndTime,
+		Timezone:    e.zoneName(),
+		OrganizerID: e.OrganizerID,
+		RoomID:      e.RoomID,
+		Status:      e.Status,
+		RegistrationForm: registrationForm(e.RegistrationForm),
+		RequiresApproval: e.RequiresApproval,
+		Capacity:    e.Capacity,
+		CreatedAt:   e.CreatedAt,
+	}
+}
+
+// zoneName mengembalikan timezone event, event lama tanpa timezone dianggap UTC
+func (e *Event) zoneName() string {
+	if e.Timezone == "" {
+		return timezone.Default
+	}
+	return e.Timezone
+}
+
+// Zone adalah timezone tempat event berlangsung
+func (e *Event) Zone() *time.Location {
+	return timezone.Load(e.Timezone)
+}
+
+// Snapshot adalah data event untuk domain event di eventbus
+func (e *Event) Snapshot() eventbus.EventSnapshot {
+	return eventbus.EventSnapshot{
+		ID:               e.ID,
+		Title:            e.Title,
+		Description:      e.Description,
+		Location:         e.Location,
+		StartTime:        e.StartTime,
+		EndTime:          e.EndTime,
+		Timezone:         e.zoneName(),
+		OrganizerID:      e.OrganizerID,
+		RoomID:           e.RoomID,
+		Status:           string(e.Status),
+		RequiresApproval: e.RequiresApproval,
+		Capacity:         e.Capacity,
+		RegistrationForm: registrationForm(e.RegistrationForm),
+	}
+}
+
+// meta adalah Meta domain event untuk event ini
+func (e *Event) meta(actorID uint) eventbus.Meta {
+	return eventbus.NewMeta(actorID, e.ID, e.OrganizerID)
+}
+
+// registrationForm memastikan form kosong dikirim sebagai [] bukan null
+func registrationForm(questions []regform.Question) []regform.Question {
+	if questions == nil {
+		return []regform.Question{}
+	}
+	return questions
+}
@@ -0,0 +1,8 @@
+package event
+
+// ParticipantCounter dipakai UnpublishEvent untuk mengecek pendaftar aktif.
+// Diimplementasikan participant.Repository, didefinisikan di sini agar event tidak
+// bergantung pada package participant
+type ParticipantCounter interface {
+	CountActiveByEventID(eventID uint) (int64, error)
+}
@@ -1,13 +1,25 @@
 package event
 
-import "gorm.io/gorm"
+import (
+	"go-event/internal/venue"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
 
 type Repository interface {
 	Create(event *Event) error
 	GetByID(id uint) (*Event, error)
+	GetByIDs(ids []uint) ([]*Event, error)
 	Update(event *Event) error
 	Delete(event *Event) error
 	GetAllByUserID(userID uint ) ([]*Event, error)
+	FindDueForStart(now time.Time) ([]*Event, error)
+	FindDueForCompletion(now time.Time) ([]*Event, error)
+	FindOverlappingInRoom(roomID uint, start, end time.Time, excludeID uint) ([]*Event, error)
+	HasEventsInRooms(roomIDs []uint) (bool, error)
+	WithRoomLock(roomID uint, fn func(repo Repository) error) error
 }
 
 type repository struct {
@@ -24,6 +36,16 @@ func (r *repository) Delete(event *Event) error {
 	return r.db.Delete(event).Error
 }
 
+// GetByIDs implements Repository.
+func (r *repository) GetByIDs(ids []uint) ([]*Event, error) {
+	var events []*Event
+	if len(ids) == 0 {
+		return events, nil
+	}
+	err := r.db.Where("id IN ?", ids).Find(&events).Error
+	return events, err
+}
+
 // GetAll implements Repository.
 func (r *repository) GetAllByUserID(userID uint) ([]*Event, error) {
 	var events []*Event
@@ -36,6 +58,59 @@ func (r *repository) GetAllByUserID(userID uint) ([]*Event, error) {
 	return events, nil
 }
 
+// FindDueForStart implements Repository.
+// Event published/registration_closed yang StartTime-nya sudah lewat
+func (r *repository) FindDueForStart(now time.Time) ([]*Event, error) {
+	var events []*Event
+	err := r.db.
+		Where("status IN ? AND start_time <= ?", []EventStatus{StatusPublished, StatusRegistrationClosed}, now).
+		Find(&events).Error
+	return events, err
+}
+
+// FindDueForCompletion implements Repository.
+// Event ongoing yang EndTime-nya sudah lewat
+func (r *repository) FindDueForCompletion(now time.Time) ([]*Event, error) {
+	var events []*Event
+	err := r.db.
+		Where("status = ? AND end_time <= ?", StatusOngoing, now).
+		Find(&events).Error
+	return events, err
+}
+
+// FindOverlappingInRoom implements Repository.
+// Event (selain cancelled) di room yang sama dengan rentang waktu beririsan [start, end)
+func (r *repository) FindOverlappingInRoom(roomID uint, start, end time.Time, excludeID uint) ([]*Event, error) {
+	var events []*Event
+	err := r.db.
+		Where("room_id = ? AND status <> ? AND start_time < ? AND end_time > ? AND id <> ?", roomID, StatusCancelled, end, start, excludeID).
+		Order("start_time asc").
+		Find(&events).Error
+	return events, err
+}
+
+// HasEventsInRooms implements Repository.
+func (r *repository) HasEventsInRooms(roomIDs []uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&Event{}).
+		Where("room_id IN ? AND status <> ?", roomIDs, StatusCancelled).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// WithRoomLock implements Repository.
+// Menjalankan fn di dalam transaksi yang mengunci baris room (SELECT ... FOR UPDATE),
+// sehingga cek bentrok + simpan event untuk room yang sama tidak bisa berjalan paralel
+func (r *repository) WithRoomLock(roomID uint, fn func(repo Repository) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var room venue.Room
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", roomID).First(&room).Error; err != nil {
+			return err
+		}
+		return fn(&repository{db: tx})
+	})
+}
+
 // GetByID implements Repository.
 func (r *repository) GetByID(id uint) (*Event, error) {
 	var event Event
@@ -1,18 +1,32 @@
 package event
 
 import (
+	"go-event/internal/audit"
 	"go-event/pkg/config"
 	"go-event/pkg/middlewares"
 
 	"github.com/gofiber/fiber/v2"
 )
 
-func SetupOrganizerEventRoutes(app *fiber.App, ctrl *Controller, cfg *config.Config) {
+func SetupOrganizerEventRoutes(app *fiber.App, ctrl *Controller, cfg *config.Config, auditLog *audit.Recorder) {
 	EO := app.Group("/api/event")
 
-	EO.Post("/", middlewares.Authenticate(cfg), middlewares.Authorize("organizer"), ctrl.CreateEvent)
+	EO.Post("/", middlewares.Authenticate(cfg), middlewares.Authorize("organizer"), auditLog.Record("event.create", audit.Created("event", "event.id")), ctrl.CreateEvent)
 	EO.Get("/",middlewares.Authenticate(cfg), middlewares.Authorize("organizer"), ctrl.GetAllEventByUserID)
 	EO.Get("/:id",middlewares.Authenticate(cfg), middlewares.Authorize("organizer"), ctrl.GetEventByID)
-	EO.Put(":id",middlewares.Authenticate(cfg), middlewares.Authorize("organizer"), ctrl.UpdateEvent)
-	EO.Delete(":id",middlewares.Authenticate(cfg), middlewares.Authorize("organizer"), ctrl.DeleteEvent)
+	EO.Put(":id",middlewares.Authenticate(cfg), middlewares.Authorize("organizer"), auditLog.Record("event.update", audit.Param("event", "id")), ctrl.UpdateEvent)
+	EO.Delete(":id",middlewares.Authenticate(cfg), middlewares.Authorize("organizer"), auditLog.Record("event.delete", audit.Param("event", "id")), ctrl.DeleteEvent)
+
+	// Lifecycle transitions
+	EO.Post("/:id/publish", middlewares.Authenticate(cfg), middlewares.Authorize("organizer"), auditLog.Record("event.publish", audit.Param("event", "id")), ctrl.PublishEvent)
+	EO.Post("/:id/unpublish", middlewares.Authenticate(cfg), middlewares.Authorize("organizer"), auditLog.Record("event.unpublish", audit.Param("event", "id")), ctrl.UnpublishEvent)
+	EO.Post("/:id/close-registration", middlewares.Authenticate(cfg), middlewares.Authorize("organizer"), auditLog.Record("event.close_registration", audit.Param("event", "id")), ctrl.CloseRegistration)
+	EO.Post("/:id/cancel", middlewares.Authenticate(cfg), middlewares.Authorize("organizer"), auditLog.Record("event.cancel", audit.Param("event", "id")), ctrl.CancelEvent)
+
+	// Registration form: dibaca semua user yang login, diubah oleh organizer
+	EO.Get("/:id/form", middlewares.Authenticate(cfg), ctrl.GetRegistrationForm)
+	EO.Put("/:id/form", middlewares.Authenticate(cfg), middlewares.Authorize("organizer"), auditLog.Record("event.update_form", audit.Param("event", "id")), ctrl.UpdateRegistrationForm)
+
+	// File kalender (.ics) untuk semua user yang login, waktu dalam timezone user
+	EO.Get("/:id/calendar.ics", middlewares.Authenticate(cfg), ctrl.GetEventCalendar)
 }
\ No newline at end of file
@@ -3,10 +3,19 @@ package event
 import (
 	"errors"
 	"fmt"
-	"go-event/internal/participant"
+	"go-event/internal/eventbus"
 	"go-event/internal/user"
+	"go-event/internal/venue"
+	"go-event/pkg/apperror"
 	"go-event/pkg/config"
+	"go-event/pkg/i18n"
+	"go-event/pkg/ics"
+	"go-event/pkg/regform"
+	"go-event/pkg/timezone"
+	"go-event/pkg/validation"
 	"log"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -17,46 +26,67 @@ type Service interface {
 	GetEventByID(eventId uint) (*EventResponse, error)
 	UpdateEvent(userID,eventID uint, req *UpdateEventRequest) (*EventResponse, error)
 	DeleteEvent(userID,eventID uint) error
+	PublishEvent(userID, eventID uint) (*EventResponse, error)
+	UnpublishEvent(userID, eventID uint) (*EventResponse, error)
+	CloseRegistration(userID, eventID uint) (*EventResponse, error)
+	CancelEvent(userID, eventID uint) (*EventResponse, error)
+	GetRegistrationForm(eventID uint) ([]regform.Question, error)
+	UpdateRegistrationForm(userID, eventID uint, req *UpdateRegistrationFormRequest) (*EventResponse, error)
+	AdvanceLifecycle(now time.Time) error
+	GetCalendar(userID uint, userRole string, eventID uint) (*ics.Calendar, error)
 }
 
 type service struct {
-	repo            Repository
-	participantRepo participant.Repository
-	userRepo        user.Repository
-	notifService    NotificationService
-	cfg             *config.Config
+	repo         Repository
+	participants ParticipantCounter
+	userRepo     user.Repository
+	venueRepo    venue.Repository
+	bus          eventbus.Publisher
+	cfg          *config.Config
 }
 
 // CreateEvent implements Service.
 func (s *service) CreateEvent(userID uint, req *CreateEventRequest) (*EventResponse, error) {
-	if req.Title == "" || req.Description == "" || req.Location == "" || req.StartTime.IsZero() || req.EndTime.IsZero() {
-		return nil, errors.New("all fields are required" )
+	if req.Title == "" || req.Description == "" || req.StartTime.IsZero() || req.EndTime.IsZero() {
+		return nil, ErrMissingFields
+	}
+	if !req.EndTime.After(req.StartTime) {
+		return nil, validation.NewError("end_time", "gtfield", "start_time", "must be after start_time")
 	}
 	event := &Event{
 		Title:       req.Title,
 		Description: req.Description,
 		Location:    req.Location,
-		StartTime:   req.StartTime,
-		EndTime:     req.EndTime,
+		StartTime:   req.StartTime.UTC(),
+		EndTime:     req.EndTime.UTC(),
+		Timezone:    s.defaultTimezone(userID, req.Timezone),
 		OrganizerID: userID,
+		RoomID:      req.RoomID,
+		RequiresApproval: req.RequiresApproval,
+		Capacity:    req.Capacity,
+		Status:      StatusDraft,
+	}
 
+	// Lokasi diambil dari venue jika event memakai room dan lokasi tidak diisi
+	if event.RoomID != nil {
+		location, err := s.roomLocation(*event.RoomID)
+		if err != nil {
+			return nil, err
+		}
+		if strings.TrimSpace(event.Location) == "" {
+			event.Location = location
+		}
 	}
-	
-	if err := s.repo.Create(event); err != nil {
-		return nil, errors.New("failed to create event: " + err.Error())
+	if strings.TrimSpace(event.Location) == "" {
+		return nil, ErrMissingFields
 	}
 
-	response := &EventResponse{
-		ID:          event.ID,
-		Title:       event.Title,
-		Description: event.Description,
-		Location:    event.Location,
-		StartTime:   event.StartTime,
-		EndTime:     event.EndTime,
-		OrganizerID: 	 event.OrganizerID,
+	if err := s.saveInRoom(event, func(repo Repository) error { return repo.Create(event) }); err != nil {
+		return nil, err
 	}
-	return response, nil
 
+	s.publish(eventbus.EventCreated{Meta: event.meta(userID), Event: event.Snapshot()})
+	return event.ToResponse(), nil
 }
 
 // DeleteEvent implements Service.
@@ -64,38 +94,24 @@ func (s *service) DeleteEvent(userID uint,eventID uint) error {
 	event, err := s.repo.GetByID(eventID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound){
-			return errors.New("event not found")
+			return ErrEventNotFound
 		}
-		return errors.New("failed to get event")
+		return apperror.Internal(err)
 	}
 	
 	if event.OrganizerID != userID {
-		return errors.New("unauthorized to delete this event")
+		return ErrNotOrganizer
 	}
 	
-	// Kirim notifikasi pembatalan ke semua participant (async)
-	go func() {
-		participants, err := s.participantRepo.FindByEventID(eventID)
-		if err != nil {
-			log.Printf("Failed to get participants for event %d: %v", eventID, err)
-			return
-		}
-				for _, p := range participants {
-			userInfo, err := s.userRepo.GetByID(p.UserID)
-			if err != nil {
-				log.Printf("Failed to get user %d: %v", p.UserID, err)
-				continue
-			}
-					message := fmt.Sprintf("Event '%s' telah dibatalkan oleh organizer.", event.Title)
-			
-			if err := s.notifService.SendNotificationWithEmailByString(p.UserID, eventID, "cancellation", message, userInfo.Email, userInfo.Name); err != nil {
-				log.Printf("Failed to send cancellation notification to user %d: %v", p.UserID, err)
-			}
-		}
-	}()
-	
 	if err := s.repo.Delete(event); err != nil {
-		return errors.New("failed to delete event")
+		return apperror.Internal(err)
+	}
+
+	// Menghapus event yang belum dibatalkan sama dengan membatalkannya: participant dikabari
+	// dan order di-refund subscriber EventCancelled. Event yang sudah cancelled sudah pernah diproses
+	if event.Status != StatusCancelled {
+		event.Status = StatusCancelled
+		s.publish(eventbus.EventCancelled{Meta: event.meta(userID), Event: event.Snapshot()})
 	}
 	return nil
 }
@@ -105,40 +121,22 @@ func (s *service) GetEventByID(eventId uint) (*EventResponse, error) {
 	event, err := s.repo.GetByID(eventId)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("event not found")
+			return nil, ErrEventNotFound
 		}
-		return nil, errors.New("failed to get event")
+		return nil, apperror.Internal(err)
 	}
-	response := &EventResponse{
-		ID:          event.ID,
-		Title:       event.Title,
-		Description: event.Description,
-		Location:    event.Location,
-		StartTime:   event.StartTime,
-		EndTime:     event.EndTime,
-		OrganizerID: event.OrganizerID,
-	}
-	return response, nil
+	return event.ToResponse(), nil
 }
 
 // GetEventByuserID implements Service.
 func (s *service) GetEventByUserID(userID uint) ([]EventResponse, error) {
 	events, err := s.repo.GetAllByUserID(userID)
 	if err != nil {
-		return nil, errors.New("failed to get events")
+		return nil, apperror.Internal(err)
 	}
 	var responses []EventResponse
 	for _, event := range events {
-		response := EventResponse{
-			ID:          event.ID,
-			Title:       event.Title,
-			Description: event.Description,
-			Location:    event.Location,
-			StartTime:   event.StartTime,
-			EndTime:     event.EndTime,
-			OrganizerID: event.OrganizerID,
-		}
-		responses = append(responses, response)
+		responses = append(responses, *event.ToResponse())
 	}
 	return responses, nil
 }
@@ -148,86 +146,366 @@ func (s *service) UpdateEvent(userID uint,eventID uint, req *UpdateEventRequest)
 	event, err := s.repo.GetByID(eventID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("event not found")
+			return nil, ErrEventNotFound
 		}
-		return nil, errors.New("failed to get event")
+		return nil, apperror.Internal(err)
 	}
 	if event.OrganizerID != userID {
-		return nil, errors.New("unauthorized to update this event")
+		return nil, ErrNotOrganizer
+	}
+	if event.Status.IsFinal() {
+		return nil, ErrEventFinalized
 	}
 
-	// Track perubahan untuk notifikasi
-	var changes []string
-	
+	// Semua field yang berubah dikirim lewat EventUpdated, subscriber yang memilih
+	// perubahan mana yang perlu dikabarkan ke participant
+	var changes []eventbus.Change
+	// Cek bentrok room hanya jika room atau waktu berubah
+	roomChanged := false
+
+	if req.RoomID != nil && !sameRoom(event.RoomID, *req.RoomID) {
+		roomChanged = true
+		oldRoomID := event.RoomID
+		if *req.RoomID == 0 {
+			event.RoomID = nil
+		} else {
+			location, err := s.roomLocation(*req.RoomID)
+			if err != nil {
+				return nil, err
+			}
+			roomID := *req.RoomID
+			event.RoomID = &roomID
+			// Lokasi ikut room baru kecuali organizer mengirim lokasi sendiri
+			if req.Location == nil {
+				req.Location = &location
+			}
+		}
+		changes = append(changes, eventbus.Change{Field: "room_id", Old: oldRoomID, New: event.RoomID})
+	}
 	if req.Title != nil && *req.Title != event.Title {
-		changes = append(changes, fmt.Sprintf("Judul diubah menjadi: %s", *req.Title))
+		changes = append(changes, eventbus.Change{Field: "title", Old: event.Title, New: *req.Title})
 		event.Title = *req.Title
 	}
 	if req.Description != nil && *req.Description != event.Description {
-		changes = append(changes, "Deskripsi event telah diperbarui")
+		changes = append(changes, eventbus.Change{Field: "description", Old: event.Description, New: *req.Description})
 		event.Description = *req.Description
 	}
 	if req.Location != nil && *req.Location != event.Location {
-		changes = append(changes, fmt.Sprintf("Lokasi diubah menjadi: %s", *req.Location))
+		changes = append(changes, eventbus.Change{Field: "location", Old: event.Location, New: *req.Location})
 		event.Location = *req.Location
 	}
 	if req.StartTime != nil && !req.StartTime.Equal(event.StartTime) {
-		changes = append(changes, fmt.Sprintf("Waktu mulai diubah menjadi: %s", req.StartTime.Format("02 Jan 2006 15:04")))
-		event.StartTime = *req.StartTime
+		startTime := req.StartTime.UTC()
+		changes = append(changes, eventbus.Change{Field: "start_time", Old: event.StartTime, New: startTime})
+		event.StartTime = startTime
+		roomChanged = true
 	}
 	if req.EndTime != nil && !req.EndTime.Equal(event.EndTime) {
-		changes = append(changes, fmt.Sprintf("Waktu selesai diubah menjadi: %s", req.EndTime.Format("02 Jan 2006 15:04")))
-		event.EndTime = *req.EndTime
+		endTime := req.EndTime.UTC()
+		changes = append(changes, eventbus.Change{Field: "end_time", Old: event.EndTime, New: endTime})
+		event.EndTime = endTime
+		roomChanged = true
+	}
+	// Timezone hanya mengubah tampilan waktu, jam mulai/selesai (UTC) tetap sama
+	if req.Timezone != nil && *req.Timezone != event.Timezone {
+		changes = append(changes, eventbus.Change{Field: "timezone", Old: event.Timezone, New: *req.Timezone})
+		event.Timezone = *req.Timezone
 	}
 
-	if err := s.repo.Update(event); err != nil {
-		return nil, errors.New("failed to update event")
+	// Menurunkan capacity tidak membatalkan participant yang sudah di-approve
+	if req.RequiresApproval != nil && *req.RequiresApproval != event.RequiresApproval {
+		changes = append(changes, eventbus.Change{Field: "requires_approval", Old: event.RequiresApproval, New: *req.RequiresApproval})
+		event.RequiresApproval = *req.RequiresApproval
+	}
+	if req.Capacity != nil && *req.Capacity != event.Capacity {
+		changes = append(changes, eventbus.Change{Field: "capacity", Old: event.Capacity, New: *req.Capacity})
+		event.Capacity = *req.Capacity
+	}
+
+	// Validasi ulang setelah digabung dengan data lama, misalnya hanya end_time yang dikirim
+	if !event.EndTime.After(event.StartTime) {
+		return nil, validation.NewError("end_time", "gtfield", "start_time", "must be after start_time")
+	}
+
+	if roomChanged {
+		err = s.saveInRoom(event, func(repo Repository) error { return repo.Update(event) })
+	} else {
+		err = s.repo.Update(event)
+	}
+	if err != nil {
+		if _, ok := apperror.As(err); ok {
+			return nil, err
+		}
+		return nil, apperror.Internal(err)
 	}
 	
-	// Kirim notifikasi update ke semua participant jika ada perubahan (async)
+	// Notifikasi update ke participant dijadwalkan subscriber schedule
 	if len(changes) > 0 {
-		go func() {
-			participants, err := s.participantRepo.FindByEventID(eventID)
-			if err != nil {
-				log.Printf("Failed to get participants for event %d: %v", eventID, err)
-				return
-			}
-			
-			updateMessage := "Perubahan yang dilakukan:\n"
-			for _, change := range changes {
-				updateMessage += "- " + change + "\n"
-			}
-					for _, p := range participants {
-				userInfo, err := s.userRepo.GetByID(p.UserID)
-				if err != nil {
-					log.Printf("Failed to get user %d: %v", p.UserID, err)
-					continue
-				}
-						if err := s.notifService.SendNotificationWithEmailByString(p.UserID, eventID, "update", updateMessage, userInfo.Email, userInfo.Name); err != nil {
-					log.Printf("Failed to send update notification to user %d: %v", p.UserID, err)
-				}
-			}
-		}()
+		s.publish(eventbus.EventUpdated{Meta: event.meta(userID), Event: event.Snapshot(), Changes: changes})
 	}
-	
-	response := &EventResponse{
-		ID:          event.ID,
-		Title:       event.Title,
-		Description: event.Description,
-		Location:    event.Location,
-		StartTime:   event.StartTime,
-		EndTime:     event.EndTime,
-		OrganizerID: event.OrganizerID,
+	return event.ToResponse(), nil
+}
+
+// PublishEvent implements Service.
+func (s *service) PublishEvent(userID, eventID uint) (*EventResponse, error) {
+	event, err := s.getOwnedEvent(userID, eventID)
+	if err != nil {
+		return nil, err
+	}
+	if event.Status == StatusDraft && !event.StartTime.After(time.Now()) {
+		return nil, ErrEventAlreadyStarted
+	}
+	return s.transition(userID, event, StatusPublished)
+}
+
+// UnpublishEvent implements Service.
+// Event hanya bisa dikembalikan ke draft jika belum ada participant
+func (s *service) UnpublishEvent(userID, eventID uint) (*EventResponse, error) {
+	event, err := s.getOwnedEvent(userID, eventID)
+	if err != nil {
+		return nil, err
+	}
+	active, err := s.participants.CountActiveByEventID(eventID)
+	if err != nil {
+		return nil, apperror.Internal(err)
+	}
+	if active > 0 {
+		return nil, ErrEventHasParticipants
+	}
+	return s.transition(userID, event, StatusDraft)
+}
+
+// CloseRegistration implements Service.
+func (s *service) CloseRegistration(userID, eventID uint) (*EventResponse, error) {
+	event, err := s.getOwnedEvent(userID, eventID)
+	if err != nil {
+		return nil, err
+	}
+	return s.transition(userID, event, StatusRegistrationClosed)
+}
+
+// CancelEvent implements Service.
+// Berbeda dengan DeleteEvent, data event tetap disimpan dengan status cancelled.
+// Refund order dan notifikasi ke participant dijalankan subscriber EventCancelled
+func (s *service) CancelEvent(userID, eventID uint) (*EventResponse, error) {
+	event, err := s.getOwnedEvent(userID, eventID)
+	if err != nil {
+		return nil, err
+	}
+	return s.transition(userID, event, StatusCancelled)
+}
+
+// GetRegistrationForm implements Service.
+// Dipakai calon participant untuk menampilkan form sebelum mendaftar
+func (s *service) GetRegistrationForm(eventID uint) ([]regform.Question, error) {
+	event, err := s.repo.GetByID(eventID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrEventNotFound
+		}
+		return nil, apperror.Internal(err)
+	}
+	return registrationForm(event.RegistrationForm), nil
+}
+
+// UpdateRegistrationForm implements Service.
+// Form diganti seluruhnya. Jawaban yang sudah tersimpan tidak diubah, pertanyaan
+// yang dihapus tetap muncul di data participant lama
+func (s *service) UpdateRegistrationForm(userID, eventID uint, req *UpdateRegistrationFormRequest) (*EventResponse, error) {
+	event, err := s.getOwnedEvent(userID, eventID)
+	if err != nil {
+		return nil, err
+	}
+	if event.Status.IsFinal() {
+		return nil, ErrEventFinalized
+	}
+	if err := regform.ValidateQuestions(req.Questions); err != nil {
+		return nil, err
+	}
+
+	change := eventbus.Change{Field: "registration_form", Old: registrationForm(event.RegistrationForm), New: registrationForm(req.Questions)}
+	event.RegistrationForm = req.Questions
+	if err := s.repo.Update(event); err != nil {
+		return nil, apperror.Internal(err)
+	}
+	s.publish(eventbus.EventUpdated{Meta: event.meta(userID), Event: event.Snapshot(), Changes: []eventbus.Change{change}})
+	return event.ToResponse(), nil
+}
+
+// AdvanceLifecycle implements Service.
+// Dipanggil scheduler secara berkala: event yang sudah mencapai StartTime menjadi
+// ongoing dan event ongoing yang sudah melewati EndTime menjadi completed
+func (s *service) AdvanceLifecycle(now time.Time) error {
+	starting, err := s.repo.FindDueForStart(now)
+	if err != nil {
+		return fmt.Errorf("failed to get events due for start: %w", err)
+	}
+	for _, event := range starting {
+		if _, err := s.transition(0, event, StatusOngoing); err != nil {
+			log.Printf("lifecycle: failed to start event %d: %v", event.ID, err)
+		}
+	}
+
+	finishing, err := s.repo.FindDueForCompletion(now)
+	if err != nil {
+		return fmt.Errorf("failed to get events due for completion: %w", err)
+	}
+	for _, event := range finishing {
+		if _, err := s.transition(0, event, StatusCompleted); err != nil {
+			log.Printf("lifecycle: failed to complete event %d: %v", event.ID, err)
+		}
+	}
+	return nil
+}
+
+// GetCalendar implements Service.
+// File .ics untuk satu event. Event draft hanya bisa diambil organizer-nya (atau admin).
+// Deskripsi diawali waktu event dalam bahasa dan timezone user
+func (s *service) GetCalendar(userID uint, userRole string, eventID uint) (*ics.Calendar, error) {
+	event, err := s.repo.GetByID(eventID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrEventNotFound
+		}
+		return nil, apperror.Internal(err)
+	}
+	if event.Status == StatusDraft && event.OrganizerID != userID && userRole != string(user.RoleAdmin) {
+		return nil, ErrEventNotFound
+	}
+
+	locale, loc := i18n.Default, event.Zone()
+	if u, err := s.userRepo.GetByID(userID); err == nil {
+		locale, loc = i18n.Resolve(u.Locale), timezone.Resolve(u.Timezone, event.Timezone)
+	}
+	description := fmt.Sprintf("%s: %s - %s\n\n%s", i18n.T(locale, "email.label.time"),
+		i18n.FormatDateTime(locale, event.StartTime.In(loc)), i18n.FormatDateTime(locale, event.EndTime.In(loc)), event.Description)
+
+	return &ics.Calendar{
+		Name:     event.Title,
+		Timezone: loc.String(),
+		Events: []ics.Event{{
+			UID:         fmt.Sprintf("event-%d@goevent", event.ID),
+			Summary:     event.Title,
+			Description: description,
+			Location:    event.Location,
+			Start:       event.StartTime,
+			End:         event.EndTime,
+			Cancelled:   event.Status == StatusCancelled,
+		}},
+	}, nil
+}
+
+// defaultTimezone adalah timezone event baru: dari request, lalu timezone organizer, lalu UTC
+func (s *service) defaultTimezone(organizerID uint, requested string) string {
+	if requested != "" {
+		return requested
+	}
+	if organizer, err := s.userRepo.GetByID(organizerID); err == nil && organizer.Timezone != "" {
+		return organizer.Timezone
+	}
+	return timezone.Default
+}
+
+// getOwnedEvent mengambil event dan memastikan user adalah organizer-nya
+func (s *service) getOwnedEvent(userID, eventID uint) (*Event, error) {
+	event, err := s.repo.GetByID(eventID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrEventNotFound
+		}
+		return nil, apperror.Internal(err)
+	}
+	if event.OrganizerID != userID {
+		return nil, ErrNotOrganizer
+	}
+	return event, nil
+}
+
+// transition memindahkan status event sesuai state machine di lifecycle.go.
+// actorID 0 untuk perubahan oleh scheduler
+func (s *service) transition(actorID uint, event *Event, to EventStatus) (*EventResponse, error) {
+	if !CanTransition(event.Status, to) {
+		return nil, ErrInvalidTransition.WithMessage(fmt.Sprintf("cannot change event status from %s to %s", event.Status, to))
+	}
+	event.Status = to
+	if err := s.repo.Update(event); err != nil {
+		return nil, apperror.Internal(err)
+	}
+	if newEvent, ok := statusEvents[to]; ok {
+		s.publish(newEvent(event.meta(actorID), event.Snapshot()))
+	}
+	return event.ToResponse(), nil
+}
+
+// publish mengirim domain event ke event bus. Perubahan sudah tersimpan, jadi error
+// subscriber sync hanya di-log
+func (s *service) publish(e eventbus.Event) {
+	if err := s.bus.Publish(e); err != nil {
+		log.Printf("Failed to handle %s for event %d: %v", e.Topic(), e.Metadata().EventID, err)
+	}
+}
+
+// roomLocation memastikan room ada dan mengembalikan teks lokasi dari venue-nya,
+// contoh: "Hall A, Gedung Serbaguna - Jl. Merdeka No. 1"
+func (s *service) roomLocation(roomID uint) (string, error) {
+	room, err := s.venueRepo.GetRoomByID(roomID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", venue.ErrRoomNotFound
+		}
+		return "", apperror.Internal(err)
+	}
+	v, err := s.venueRepo.GetVenueByID(room.VenueID)
+	if err != nil {
+		return "", apperror.Internal(err)
+	}
+	return fmt.Sprintf("%s, %s - %s", room.Name, v.Name, v.Address), nil
+}
+
+// saveInRoom menjalankan save dengan cek double-booking jika event memakai room.
+// Cek dan simpan dilakukan di bawah lock room agar dua request paralel tidak lolos bersamaan
+func (s *service) saveInRoom(event *Event, save func(repo Repository) error) error {
+	if event.RoomID == nil || event.Status == StatusCancelled {
+		if err := save(s.repo); err != nil {
+			return apperror.Internal(err)
+		}
+		return nil
+	}
+
+	err := s.repo.WithRoomLock(*event.RoomID, func(repo Repository) error {
+		conflicts, err := repo.FindOverlappingInRoom(*event.RoomID, event.StartTime, event.EndTime, event.ID)
+		if err != nil {
+			return err
+		}
+		if len(conflicts) > 0 {
+			return ErrRoomConflict.WithDetails(toBookings(conflicts))
+		}
+		return save(repo)
+	})
+	if err != nil {
+		if _, ok := apperror.As(err); ok {
+			return err
+		}
+		return apperror.Internal(fmt.Errorf("failed to save event in room %d: %w", *event.RoomID, err))
+	}
+	return nil
+}
+
+// sameRoom membandingkan room event saat ini dengan room_id dari request (0 = tanpa room)
+func sameRoom(current *uint, requested uint) bool {
+	if current == nil {
+		return requested == 0
 	}
-	return response, nil
+	return *current == requested
 }
 
-func NewService(repo Repository, participantRepo participant.Repository, userRepo user.Repository, notifService NotificationService, cfg *config.Config) Service {
+func NewService(repo Repository, participants ParticipantCounter, userRepo user.Repository, venueRepo venue.Repository, bus eventbus.Publisher, cfg *config.Config) Service {
 	return &service{
-		repo:            repo,
-		participantRepo: participantRepo,
-		userRepo:        userRepo,
-		notifService:    notifService,
-		cfg:             cfg,
+		repo:         repo,
+		participants: participants,
+		userRepo:     userRepo,
+		venueRepo:    venueRepo,
+		bus:          bus,
+		cfg:          cfg,
 	}
 }
@@ -0,0 +1,113 @@
+// Package eventbus adalah bus publish/subscribe in-process untuk domain event (event dibuat,
+// participant mendaftar, dll). Service mem-publish event bertipe tanpa tahu siapa yang
+// mendengarkan; notification, schedule, ticket, dan webhook subscribe ke event yang
+// mereka butuhkan. Package ini tidak boleh import package internal lain agar tidak
+// menimbulkan import cycle
+package eventbus
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"runtime/debug"
+	"sync"
+)
+
+// Mode menentukan cara handler dijalankan
+type Mode int
+
+const (
+	// Sync: handler dijalankan berurutan di goroutine publisher sebelum Publish selesai,
+	// error (termasuk panic) dikembalikan ke publisher. Untuk pekerjaan yang harus
+	// tersimpan sebelum response dikirim
+	Sync Mode = iota
+	// Async: handler dijalankan di goroutine sendiri, error hanya di-log. Untuk pekerjaan
+	// lambat seperti kirim notifikasi atau HTTP
+	Async
+)
+
+// Publisher dipakai service yang mengirim event
+type Publisher interface {
+	Publish(e Event) error
+}
+
+// Bus adalah Publisher yang bisa di-subscribe lewat Subscribe dan SubscribeAll
+type Bus interface {
+	Publisher
+	subscribe(topic Topic, mode Mode, handle func(Event) error)
+}
+
+type subscription struct {
+	mode   Mode
+	handle func(Event) error
+}
+
+type bus struct {
+	mu sync.RWMutex
+	// Key "" berisi subscriber semua topic
+	subscriptions map[Topic][]subscription
+}
+
+// Subscribe mendaftarkan handler untuk satu tipe event, contoh:
+//
+//	eventbus.Subscribe(bus, eventbus.Async, notificationService.OnEventCancelled)
+func Subscribe[E Event](b Bus, mode Mode, handler func(E) error) {
+	var zero E
+	b.subscribe(zero.Topic(), mode, func(e Event) error {
+		return handler(e.(E))
+	})
+}
+
+// SubscribeAll mendaftarkan handler untuk semua tipe event (misalnya webhook)
+func SubscribeAll(b Bus, mode Mode, handler func(Event) error) {
+	b.subscribe("", mode, handler)
+}
+
+// Publish implements Publisher.
+// Handler sync dijalankan berurutan sebelum Publish kembali, handler async dijalankan
+// di background. Error handler sync digabung dan dikembalikan, tapi tidak menghentikan
+// handler lain
+func (b *bus) Publish(e Event) error {
+	b.mu.RLock()
+	subs := make([]subscription, 0, len(b.subscriptions[e.Topic()])+len(b.subscriptions[""]))
+	subs = append(subs, b.subscriptions[e.Topic()]...)
+	subs = append(subs, b.subscriptions[""]...)
+	b.mu.RUnlock()
+
+	var errs []error
+	for _, sub := range subs {
+		if sub.mode == Async {
+			go func(sub subscription) {
+				if err := run(sub, e); err != nil {
+					log.Printf("eventbus: %s handler failed: %v", e.Topic(), err)
+				}
+			}(sub)
+			continue
+		}
+		if err := run(sub, e); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (b *bus) subscribe(topic Topic, mode Mode, handle func(Event) error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subscriptions[topic] = append(b.subscriptions[topic], subscription{mode: mode, handle: handle})
+}
+
+// run menjalankan handler, panic di subscriber tidak boleh mematikan server
+func run(sub subscription, e Event) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("eventbus: handler panic on %s: %v\n%s", e.Topic(), r, debug.Stack())
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return sub.handle(e)
+}
+
+func New() Bus {
+	return &bus{subscriptions: make(map[Topic][]subscription)}
+}
@@ -0,0 +1,200 @@
+package eventbus
+
+import (
+	"go-event/pkg/regform"
+	"time"
+)
+
+// Topic adalah nama domain event, juga dipakai sebagai nama event di webhook
+type Topic string
+
+const (
+	TopicEventCreated          Topic = "event.created"
+	TopicEventUpdated          Topic = "event.updated"
+	TopicEventPublished        Topic = "event.published"
+	TopicEventCancelled        Topic = "event.cancelled"
+	TopicEventStarted          Topic = "event.started"
+	TopicEventCompleted        Topic = "event.completed"
+	TopicReminderSent          Topic = "event.reminder_sent"
+	TopicParticipantRegistered Topic = "participant.registered"
+	TopicParticipantReviewed   Topic = "participant.reviewed"
+	TopicParticipantCancelled  Topic = "participant.cancelled"
+	TopicParticipantCheckedIn  Topic = "participant.checked_in"
+)
+
+// Topics adalah semua domain event yang bisa di-subscribe
+var Topics = []Topic{
+	TopicEventCreated,
+	TopicEventUpdated,
+	TopicEventPublished,
+	TopicEventCancelled,
+	TopicEventStarted,
+	TopicEventCompleted,
+	TopicReminderSent,
+	TopicParticipantRegistered,
+	TopicParticipantReviewed,
+	TopicParticipantCancelled,
+	TopicParticipantCheckedIn,
+}
+
+// IsValid mengecek apakah t adalah domain event yang dikenal
+func (t Topic) IsValid() bool {
+	for _, known := range Topics {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Event adalah satu domain event. Setiap tipe event adalah struct sendiri yang
+// meng-embed Meta; field lainnya adalah data event (di-marshal ke JSON untuk webhook)
+type Event interface {
+	Topic() Topic
+	Metadata() Meta
+}
+
+// Meta adalah data umum semua domain event
+type Meta struct {
+	OccurredAt time.Time
+	// ActorID adalah user yang memicu event, 0 = sistem (scheduler)
+	ActorID uint
+	// EventID dan OrganizerID adalah event (acara) yang bersangkutan
+	EventID     uint
+	OrganizerID uint
+}
+
+func (m Meta) Metadata() Meta { return m }
+
+// NewMeta membuat Meta dengan waktu sekarang
+func NewMeta(actorID, eventID, organizerID uint) Meta {
+	return Meta{OccurredAt: time.Now(), ActorID: actorID, EventID: eventID, OrganizerID: organizerID}
+}
+
+// EventSnapshot adalah data event (acara) saat domain event terjadi
+type EventSnapshot struct {
+	ID               uint               `json:"id"`
+	Title            string             `json:"title"`
+	Description      string             `json:"description"`
+	Location         string             `json:"location"`
+	StartTime        time.Time          `json:"start_time"`
+	EndTime          time.Time          `json:"end_time"`
+	Timezone         string             `json:"timezone"`
+	OrganizerID      uint               `json:"organizer_id"`
+	RoomID           *uint              `json:"room_id"`
+	Status           string             `json:"status"`
+	RequiresApproval bool               `json:"requires_approval"`
+	Capacity         int                `json:"capacity"`
+	RegistrationForm []regform.Question `json:"registration_form"`
+}
+
+// ParticipantSnapshot adalah data pendaftaran saat domain event terjadi
+type ParticipantSnapshot struct {
+	ID            uint            `json:"id"`
+	EventID       uint            `json:"event_id"`
+	UserID        uint            `json:"user_id"`
+	UserName      string          `json:"user_name"`
+	UserEmail     string          `json:"user_email"`
+	Status        string          `json:"status"`
+	OrderID       *uint           `json:"order_id,omitempty"`
+	Guests        []GuestSnapshot `json:"guests"`
+	Answers       regform.Answers `json:"answers,omitempty"`
+	ReviewMessage string          `json:"review_message,omitempty"`
+	CancelReason  string          `json:"cancel_reason,omitempty"`
+	CheckedInAt   *time.Time      `json:"checked_in_at,omitempty"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+type GuestSnapshot struct {
+	ID    uint   `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// Change adalah satu field event yang berubah. Waktu ditulis dalam UTC
+type Change struct {
+	Field string      `json:"field"`
+	Old   interface{} `json:"old"`
+	New   interface{} `json:"new"`
+}
+
+// 📣 Domain events. Meta tidak ikut di-marshal, webhook mengirimnya di luar data
+
+type EventCreated struct {
+	Meta  `json:"-"`
+	Event EventSnapshot `json:"event"`
+}
+
+// EventUpdated berisi semua field yang berubah, termasuk yang tidak dikabarkan ke
+// participant (timezone, capacity, dll)
+type EventUpdated struct {
+	Meta    `json:"-"`
+	Event   EventSnapshot `json:"event"`
+	Changes []Change      `json:"changes"`
+}
+
+type EventPublished struct {
+	Meta  `json:"-"`
+	Event EventSnapshot `json:"event"`
+}
+
+type EventCancelled struct {
+	Meta  `json:"-"`
+	Event EventSnapshot `json:"event"`
+}
+
+// EventStarted dan EventCompleted dikirim lifecycle scheduler
+type EventStarted struct {
+	Meta  `json:"-"`
+	Event EventSnapshot `json:"event"`
+}
+
+type EventCompleted struct {
+	Meta  `json:"-"`
+	Event EventSnapshot `json:"event"`
+}
+
+// ReminderSent dikirim setelah schedule job reminder selesai. Recipients adalah
+// jumlah participant (tanpa guest) yang berhasil dikirimi reminder
+type ReminderSent struct {
+	Meta       `json:"-"`
+	Event      EventSnapshot `json:"event"`
+	ScheduleID uint          `json:"schedule_id"`
+	Recipients int           `json:"recipients"`
+}
+
+// ParticipantRegistered juga dikirim untuk pendaftar yang masih menunggu approval,
+// participant hasil import, dan participant dari order tiket yang lunas
+type ParticipantRegistered struct {
+	Meta        `json:"-"`
+	Participant ParticipantSnapshot `json:"participant"`
+}
+
+// ParticipantReviewed dikirim saat organizer meng-approve atau menolak pendaftar
+type ParticipantReviewed struct {
+	Meta        `json:"-"`
+	Participant ParticipantSnapshot `json:"participant"`
+	Approved    bool                `json:"approved"`
+}
+
+type ParticipantCancelled struct {
+	Meta        `json:"-"`
+	Participant ParticipantSnapshot `json:"participant"`
+}
+
+type ParticipantCheckedIn struct {
+	Meta        `json:"-"`
+	Participant ParticipantSnapshot `json:"participant"`
+}
+
+func (EventCreated) Topic() Topic          { return TopicEventCreated }
+func (EventUpdated) Topic() Topic          { return TopicEventUpdated }
+func (EventPublished) Topic() Topic        { return TopicEventPublished }
+func (EventCancelled) Topic() Topic        { return TopicEventCancelled }
+func (EventStarted) Topic() Topic          { return TopicEventStarted }
+func (EventCompleted) Topic() Topic        { return TopicEventCompleted }
+func (ReminderSent) Topic() Topic          { return TopicReminderSent }
+func (ParticipantRegistered) Topic() Topic { return TopicParticipantRegistered }
+func (ParticipantReviewed) Topic() Topic   { return TopicParticipantReviewed }
+func (ParticipantCancelled) Topic() Topic  { return TopicParticipantCancelled }
+func (ParticipantCheckedIn) Topic() Topic  { return TopicParticipantCheckedIn }
@@ -2,7 +2,13 @@
 package notification
 
 import (
+	"fmt"
+	"go-event/pkg/apperror"
 	"go-event/pkg/config"
+	"go-event/pkg/i18n"
+	"go-event/pkg/pagination"
+	"go-event/pkg/validation"
+	"html"
 	"strconv"
 
 	"github.com/gofiber/fiber/v2"
@@ -25,16 +31,15 @@ func (ctrl *Controller) CreateNotification(c *fiber.Ctx) error {
 
 	// Bisa parse JSON & x-www-form-urlencoded
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"message": "invalid request body",
-		})
+		return apperror.ErrInvalidBody
+	}
+	if err := validation.Struct(&req); err != nil {
+		return err
 	}
 
 	notification, err := ctrl.service.CreateNotification(&req)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"message": err.Error(),
-		})
+		return err
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
@@ -43,43 +48,103 @@ func (ctrl *Controller) CreateNotification(c *fiber.Ctx) error {
 	})
 }
 
+// GetNotifications - inbox user, paginated dengan filter type, is_read, dan event_id
 func (ctrl *Controller) GetNotifications(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uint)
 
-	notifications, err := ctrl.service.GetNotificationsByUserID(userID)
+	var page pagination.Params
+	if err := c.QueryParser(&page); err != nil {
+		return apperror.ErrInvalidParam
+	}
+	if err := validation.Struct(&page); err != nil {
+		return err
+	}
+	query, err := parseListQuery(c)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"message": err.Error(),
-		})
+		return err
+	}
+
+	result, err := ctrl.service.ListNotifications(userID, query, page)
+	if err != nil {
+		return err
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"message":       "notifications retrieved successfully",
-		"notifications": notifications,
+		"notifications": result.Notifications,
+		"pagination":    result.Pagination,
+		"unread_count":  result.UnreadCount,
 	})
 }
 
-func (ctrl *Controller) MarkAsRead(c *fiber.Ctx) error {
+// GetUnreadCount - jumlah notifikasi belum dibaca untuk badge
+func (ctrl *Controller) GetUnreadCount(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uint)
-	id := c.Params("id")
 
-	notificationID, err := strconv.ParseUint(id, 10, 32)
+	unread, err := ctrl.service.GetUnreadCount(userID)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"message": "invalid notification ID",
-		})
+		return err
 	}
 
-	err = ctrl.service.MarkNotificationAsRead(uint(notificationID), userID)
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "unread count retrieved successfully",
+		"unread":  unread,
+	})
+}
+
+// MarkAllAsRead - tandai semua notifikasi (opsional difilter type/event_id) sudah dibaca
+func (ctrl *Controller) MarkAllAsRead(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	query, err := parseListQuery(c)
 	if err != nil {
-		statusCode := fiber.StatusInternalServerError
-		if err.Error() == "notification not found or unauthorized" {
-			statusCode = fiber.StatusNotFound
-		}
+		return err
+	}
 
-		return c.Status(statusCode).JSON(fiber.Map{
-			"message": err.Error(),
-		})
+	updated, err := ctrl.service.MarkAllAsRead(userID, query)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "notifications marked as read successfully",
+		"updated": updated,
+	})
+}
+
+// BulkDelete - hapus beberapa notifikasi sekaligus, atau semua yang sudah dibaca
+func (ctrl *Controller) BulkDelete(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var req BulkDeleteRequest
+	if err := c.BodyParser(&req); err != nil {
+		return apperror.ErrInvalidBody
+	}
+	if err := validation.Struct(&req); err != nil {
+		return err
+	}
+
+	deleted, err := ctrl.service.BulkDelete(userID, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "notifications deleted successfully",
+		"deleted": deleted,
+	})
+}
+
+func (ctrl *Controller) MarkAsRead(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	notificationID, err := parseNotificationID(c)
+	if err != nil {
+		return err
+	}
+
+	if err := ctrl.service.MarkNotificationAsRead(notificationID, userID); err != nil {
+		return err
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
@@ -89,28 +154,201 @@ func (ctrl *Controller) MarkAsRead(c *fiber.Ctx) error {
 
 func (ctrl *Controller) DeleteNotification(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uint)
-	id := c.Params("id")
 
-	notificationID, err := strconv.ParseUint(id, 10, 32)
+	notificationID, err := parseNotificationID(c)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"message": "invalid notification ID",
-		})
+		return err
+	}
+
+	if err := ctrl.service.DeleteNotification(notificationID, userID); err != nil {
+		return err
 	}
 
-	err = ctrl.service.DeleteNotification(uint(notificationID), userID)
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "notification deleted successfully",
+	})
+}
+
+func (ctrl *Controller) GetPreferences(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	prefs, err := ctrl.service.GetPreferences(userID)
 	if err != nil {
-		statusCode := fiber.StatusInternalServerError
-		if err.Error() == "notification not found or unauthorized" {
-			statusCode = fiber.StatusNotFound
-		}
+		return err
+	}
 
-		return c.Status(statusCode).JSON(fiber.Map{
-			"message": err.Error(),
-		})
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message":     "notification preferences retrieved successfully",
+		"preferences": prefs,
+	})
+}
+
+func (ctrl *Controller) UpdatePreferences(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var req UpdatePreferencesRequest
+	if err := c.BodyParser(&req); err != nil {
+		return apperror.ErrInvalidBody
+	}
+	if err := validation.Struct(&req); err != nil {
+		return err
+	}
+
+	prefs, err := ctrl.service.UpdatePreferences(userID, &req)
+	if err != nil {
+		return err
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"message": "notification deleted successfully",
+		"message":     "notification preferences updated successfully",
+		"preferences": prefs,
+	})
+}
+
+// MuteEventReminders - berhenti menerima reminder untuk satu event
+func (ctrl *Controller) MuteEventReminders(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	eventID, err := parseEventID(c)
+	if err != nil {
+		return err
+	}
+	if err := ctrl.service.MuteEventReminders(userID, eventID); err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "event reminders muted successfully",
+	})
+}
+
+func (ctrl *Controller) UnmuteEventReminders(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	eventID, err := parseEventID(c)
+	if err != nil {
+		return err
+	}
+	if err := ctrl.service.UnmuteEventReminders(userID, eventID); err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "event reminders unmuted successfully",
+	})
+}
+
+// UnsubscribePage - GET dari link di footer email. Hanya menampilkan konfirmasi
+// (tidak mengubah apa pun) agar link yang di-prefetch mail scanner tidak ikut unsubscribe
+func (ctrl *Controller) UnsubscribePage(c *fiber.Ctx) error {
+	_, topic, err := ctrl.service.VerifyUnsubscribeToken(c.Query("token"))
+	if err != nil {
+		return err
+	}
+
+	c.Type("html", "utf-8")
+	return c.SendString(fmt.Sprintf(`<!DOCTYPE html>
+<html><body style="font-family: Arial, sans-serif; text-align: center; padding: 40px;">
+<p>Berhenti menerima email notifikasi <strong>%s</strong>?</p>
+<form method="post"><button type="submit">Berhenti berlangganan</button></form>
+</body></html>`, html.EscapeString(topic)))
+}
+
+// Unsubscribe - POST one-click (header List-Unsubscribe-Post, RFC 8058) atau dari form konfirmasi
+func (ctrl *Controller) Unsubscribe(c *fiber.Ctx) error {
+	topic, err := ctrl.service.Unsubscribe(c.Query("token"))
+	if err != nil {
+		return err
+	}
+
+	c.Type("html", "utf-8")
+	return c.SendString(fmt.Sprintf(`<!DOCTYPE html>
+<html><body style="font-family: Arial, sans-serif; text-align: center; padding: 40px;">
+<p>Anda tidak akan lagi menerima email notifikasi <strong>%s</strong>. Pengaturan bisa diubah kembali di aplikasi.</p>
+</body></html>`, html.EscapeString(topic)))
+}
+
+// unsubscribeUserID membaca pemilik token unsubscribe, dipakai sebagai target audit.
+// 0 jika token tidak valid (request ditolak handler dan tidak dicatat)
+func (ctrl *Controller) unsubscribeUserID(c *fiber.Ctx) uint {
+	userID, _, err := ctrl.service.VerifyUnsubscribeToken(c.Query("token"))
+	if err != nil {
+		return 0
+	}
+	return userID
+}
+
+// GetEmailTemplates - daftar nama template email (admin)
+func (ctrl *Controller) GetEmailTemplates(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{
+		"templates": ctrl.service.ListEmailTemplates(),
 	})
 }
+
+// PreviewEmailTemplate - render template dengan data contoh (admin).
+// ?locale=id|en, ?format=json (default) | html | text
+func (ctrl *Controller) PreviewEmailTemplate(c *fiber.Ctx) error {
+	locale := i18n.Default
+	if raw := c.Query("locale"); raw != "" {
+		parsed, ok := i18n.Parse(raw)
+		if !ok {
+			return apperror.InvalidParam("locale")
+		}
+		locale = parsed
+	}
+
+	format := c.Query("format", "json")
+	if format != "json" && format != "html" && format != "text" {
+		return apperror.InvalidParam("format")
+	}
+
+	rendered, err := ctrl.service.PreviewEmailTemplate(c.Params("name"), locale)
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case "html":
+		c.Type("html", "utf-8")
+		return c.SendString(rendered.HTML)
+	case "text":
+		c.Type("txt", "utf-8")
+		return c.SendString(rendered.Text)
+	default:
+		return c.JSON(fiber.Map{
+			"template": c.Params("name"),
+			"locale":   locale,
+			"preview":  rendered,
+		})
+	}
+}
+
+// parseEventID membaca path param :id sebagai event ID
+func parseEventID(c *fiber.Ctx) (uint, error) {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return 0, apperror.InvalidParam("event ID")
+	}
+	return uint(id), nil
+}
+
+// parseListQuery membaca dan memvalidasi filter inbox dari query string
+func parseListQuery(c *fiber.Ctx) (*ListQuery, error) {
+	var query ListQuery
+	if err := c.QueryParser(&query); err != nil {
+		return nil, apperror.ErrInvalidParam
+	}
+	if err := validation.Struct(&query); err != nil {
+		return nil, err
+	}
+	return &query, nil
+}
+
+// parseNotificationID membaca path param :id
+func parseNotificationID(c *fiber.Ctx) (uint, error) {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return 0, apperror.InvalidParam("notification ID")
+	}
+	return uint(id), nil
+}
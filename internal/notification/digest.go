@@ -0,0 +1,127 @@
+package notification
+
+import (
+	"fmt"
+	"go-event/internal/notification/email"
+	"go-event/pkg/i18n"
+	"go-event/pkg/timezone"
+	"log"
+	"time"
+)
+
+// DigestFrequency adalah jadwal email ringkasan notifikasi yang belum dibaca
+type DigestFrequency string
+
+const (
+	DigestOff    DigestFrequency = "off"
+	DigestDaily  DigestFrequency = "daily"
+	DigestWeekly DigestFrequency = "weekly"
+)
+
+const (
+	// digestHour adalah jam pengiriman digest di timezone user, weekly dikirim hari Senin
+	digestHour = 8
+	// digestMaxItems adalah jumlah notifikasi yang ditampilkan di email, sisanya hanya dihitung
+	digestMaxItems = 20
+)
+
+// Enabled mengecek apakah digest aktif (nilai kosong dari data lama = off)
+func (f DigestFrequency) Enabled() bool {
+	return f == DigestDaily || f == DigestWeekly
+}
+
+// slots mengembalikan jadwal digest terakhir yang sudah lewat dan jadwal sebelumnya
+func (f DigestFrequency) slots(now time.Time, loc *time.Location) (time.Time, time.Time) {
+	local := now.In(loc)
+	slot := time.Date(local.Year(), local.Month(), local.Day(), digestHour, 0, 0, 0, loc)
+	if slot.After(now) {
+		slot = slot.AddDate(0, 0, -1)
+	}
+	if f == DigestWeekly {
+		for slot.Weekday() != time.Monday {
+			slot = slot.AddDate(0, 0, -1)
+		}
+		return slot, slot.AddDate(0, 0, -7)
+	}
+	return slot, slot.AddDate(0, 0, -1)
+}
+
+// DigestRecipient adalah user dengan digest aktif beserta data untuk mengirim email
+type DigestRecipient struct {
+	UserID       uint
+	Email        string
+	Name         string
+	Timezone     string
+	Locale       string
+	Digest       DigestFrequency
+	LastDigestAt *time.Time
+}
+
+// SendDigests implements Service.
+// Dipanggil scheduler secara berkala. User yang jadwal digest-nya sudah lewat dikirimi
+// ringkasan notifikasi yang belum dibaca sejak digest sebelumnya. Tidak ada email
+// jika tidak ada notifikasi baru
+func (s *service) SendDigests(now time.Time) error {
+	recipients, err := s.repo.FindDigestRecipients()
+	if err != nil {
+		return fmt.Errorf("failed to get digest recipients: %w", err)
+	}
+	for _, r := range recipients {
+		slot, previous := r.Digest.slots(now, timezone.Load(r.Timezone))
+		if r.LastDigestAt != nil && !r.LastDigestAt.Before(slot) {
+			continue
+		}
+		since := previous
+		if r.LastDigestAt != nil && r.LastDigestAt.After(since) {
+			since = *r.LastDigestAt
+		}
+
+		if err := s.sendDigest(r, since); err != nil {
+			// Tetap ditandai terkirim agar email yang gagal tidak diulang setiap putaran
+			log.Printf("Failed to send %s digest to user %d: %v", r.Digest, r.UserID, err)
+		}
+		if err := s.repo.SetLastDigestAt(r.UserID, now); err != nil {
+			return fmt.Errorf("failed to update digest time for user %d: %w", r.UserID, err)
+		}
+	}
+	return nil
+}
+
+// sendDigest mengirim satu email digest berisi notifikasi belum dibaca sejak since
+func (s *service) sendDigest(r DigestRecipient, since time.Time) error {
+	notifications, total, err := s.repo.GetUnreadSince(r.UserID, since, digestMaxItems)
+	if err != nil {
+		return err
+	}
+	if total == 0 {
+		return nil
+	}
+
+	var eventIDs []uint
+	for _, n := range notifications {
+		if n.EventID != nil {
+			eventIDs = append(eventIDs, *n.EventID)
+		}
+	}
+	titles := make(map[uint]string, len(eventIDs))
+	events, err := s.eventRepo.GetByIDs(eventIDs)
+	if err != nil {
+		return err
+	}
+	for _, e := range events {
+		titles[e.ID] = e.Title
+	}
+
+	loc := timezone.Load(r.Timezone)
+	items := make([]email.DigestItem, 0, len(notifications))
+	for _, n := range notifications {
+		item := email.DigestItem{Message: n.Message, SentAt: n.SentAt.In(loc)}
+		if n.EventID != nil {
+			item.EventTitle = titles[*n.EventID]
+		}
+		items = append(items, item)
+	}
+
+	mailer := s.emailService.WithUnsubscribe(s.unsubscribeURL(r.UserID, digestTopic)).WithLocale(i18n.Resolve(r.Locale))
+	return mailer.SendDigestEmail(r.Email, r.Name, string(r.Digest), items, int(total))
+}
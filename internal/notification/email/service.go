@@ -3,7 +3,11 @@ package email
 import (
 	"fmt"
 	"go-event/pkg/config"
+	"go-event/pkg/i18n"
+	"html"
 	"log"
+	"strings"
+	"time"
 
 	"github.com/mailjet/mailjet-apiv3-go/v4"
 )
@@ -11,19 +15,80 @@ import (
 type Service interface {
 	SendEmail(to, toName, subject, htmlBody, textBody string) error
 	SendWelcomeEmail(to, toName string) error
-	SendReminderEmail(to, toName, eventTitle, eventDate string) error
+	SendReminderEmail(to, toName, eventTitle, eventDate, message string) error
 	SendRegistrationConfirmationEmail(to, toName, eventTitle, eventDate, eventLocation string) error
 	SendCancellationEmail(to, toName, eventTitle string) error
 	SendUpdateEmail(to, toName, eventTitle, updateMessage string) error
+	SendAnnouncementEmail(to, toName, eventTitle, message string) error
+	SendGuestTicketEmail(to, toName, eventTitle, eventDate, eventLocation, ticketCode, registeredBy string) error
+	// frequency adalah "daily" atau "weekly"
+	SendDigestEmail(to, toName, frequency string, items []DigestItem, total int) error
+	// WithUnsubscribe mengembalikan Service yang menambahkan link unsubscribe ke footer
+	// dan header List-Unsubscribe (one-click, RFC 8058) pada setiap email
+	WithUnsubscribe(url string) Service
+	// WithLocale mengembalikan Service yang menulis email dalam bahasa penerima.
+	// Tanggal yang dikirim sebagai string sudah harus diformat oleh pemanggil
+	WithLocale(locale i18n.Locale) Service
+	// TemplateNames mengembalikan nama semua template email
+	TemplateNames() []string
+	// Preview merender template dengan data contoh tanpa mengirim email.
+	// Mengembalikan ErrUnknownTemplate jika nama template tidak dikenal
+	Preview(name string, locale i18n.Locale) (*Rendered, error)
+}
+
+// DigestItem adalah satu notifikasi di email digest
+type DigestItem struct {
+	EventTitle string // kosong jika notifikasi tidak terkait event
+	Message    string
+	SentAt     time.Time
 }
 
 type service struct {
-	client *mailjet.Client
-	cfg    *config.Config
+	client         *mailjet.Client
+	cfg            *config.Config
+	templates      map[string]*templateSet
+	unsubscribeURL string
+	locale         i18n.Locale
+}
+
+// WithUnsubscribe implements Service.
+func (s *service) WithUnsubscribe(url string) Service {
+	cp := *s
+	cp.unsubscribeURL = url
+	return &cp
+}
+
+// WithLocale implements Service.
+func (s *service) WithLocale(locale i18n.Locale) Service {
+	cp := *s
+	cp.locale = locale
+	return &cp
+}
+
+// t menerjemahkan key ke bahasa penerima, untuk subject dan body teks
+func (s *service) t(key string, args ...interface{}) string {
+	return i18n.T(s.locale, key, args...)
 }
 
 // SendEmail implements Service.
+// Untuk body yang disusun sendiri oleh pemanggil, link unsubscribe ditambahkan di akhir body
 func (s *service) SendEmail(to, toName, subject, htmlBody, textBody string) error {
+	if s.unsubscribeURL != "" {
+		htmlBody, textBody = s.appendUnsubscribeFooter(htmlBody, textBody)
+	}
+	return s.send(to, toName, subject, htmlBody, textBody)
+}
+
+// send mengirim email lewat Mailjet, dengan header List-Unsubscribe jika ada link unsubscribe
+func (s *service) send(to, toName, subject, htmlBody, textBody string) error {
+	var headers map[string]interface{}
+	if s.unsubscribeURL != "" {
+		headers = map[string]interface{}{
+			"List-Unsubscribe":      "<" + s.unsubscribeURL + ">",
+			"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+		}
+	}
+
 	messagesInfo := []mailjet.InfoMessagesV31{
 		{
 			From: &mailjet.RecipientV31{
@@ -39,6 +104,7 @@ func (s *service) SendEmail(to, toName, subject, htmlBody, textBody string) erro
 			Subject:  subject,
 			TextPart: textBody,
 			HTMLPart: htmlBody,
+			Headers:  headers,
 		},
 	}
 
@@ -52,234 +118,116 @@ func (s *service) SendEmail(to, toName, subject, htmlBody, textBody string) erro
 	return nil
 }
 
-// SendWelcomeEmail implements Service.
-func (s *service) SendWelcomeEmail(to, toName string) error {
-	subject := "🎉 Selamat Datang di GoEvent!"
-	
-	htmlBody := fmt.Sprintf(`
-		<html>
-			<body style="font-family: Arial, sans-serif; line-height: 1.6; color: #333;">
-				<div style="max-width: 600px; margin: 0 auto; padding: 20px; background-color: #ffffff;">
-					<div style="text-align: center; padding: 20px 0; background: linear-gradient(135deg, #10b981 0%%, #059669 100%%); border-radius: 8px 8px 0 0;">
-						<h1 style="color: #ffffff; margin: 0; font-size: 28px;">🎉 Selamat Datang!</h1>
-					</div>
-					<div style="padding: 30px; background-color: #f0fdf4; border-radius: 0 0 8px 8px;">
-						<p style="font-size: 16px;">Halo <strong>%s</strong>,</p>
-						<p style="font-size: 16px;">Terima kasih telah bergabung dengan <strong>GoEvent</strong>! 🎊</p>
-						<div style="background-color: #ffffff; padding: 20px; border-left: 4px solid #10b981; border-radius: 4px; margin: 20px 0;">
-							<p style="margin: 0; font-size: 16px;">
-								Akun Anda telah berhasil dibuat. Sekarang Anda dapat:
-							</p>
-							<ul style="margin: 15px 0; padding-left: 20px; font-size: 16px;">
-								<li>✨ Membuat dan mengelola event</li>
-								<li>🎫 Mendaftar ke berbagai event menarik</li>
-								<li>🔔 Mendapatkan notifikasi event</li>
-								<li>📊 Melihat riwayat partisipasi Anda</li>
-							</ul>
-						</div>
-						<p style="font-size: 16px;">Mulai jelajahi event yang tersedia dan ciptakan pengalaman tak terlupakan bersama kami!</p>
-						<div style="text-align: center; margin-top: 30px;">
-							<a href="http://localhost:3000" style="display: inline-block; padding: 12px 30px; background-color: #10b981; color: #ffffff; text-decoration: none; border-radius: 6px; font-weight: bold;">Mulai Sekarang</a>
-						</div>
-					</div>
-					<div style="text-align: center; padding: 20px; background-color: #f3f4f6; border-radius: 0 0 8px 8px;">
-						<p style="font-size: 12px; color: #6b7280; margin: 0;">
-							Email ini dikirim secara otomatis oleh <strong>GoEvent App</strong><br>
-							Mohon tidak membalas email ini.
-						</p>
-					</div>
-				</div>
-			</body>
-		</html>
-	`, toName)
+// appendUnsubscribeFooter menyisipkan link unsubscribe sebelum </body> (atau di akhir)
+func (s *service) appendUnsubscribeFooter(htmlBody, textBody string) (string, string) {
+	link := html.EscapeString(s.unsubscribeURL)
+	footer := fmt.Sprintf(`<p style="text-align: center; font-size: 12px; color: #6b7280;">%s <a href="%s" style="color: #6b7280;">%s</a></p>`,
+		s.translateHTML("email.unsubscribe.prompt"), link, s.translateHTML("email.unsubscribe.link"))
+	if i := strings.LastIndex(htmlBody, "</body>"); i >= 0 {
+		htmlBody = htmlBody[:i] + footer + htmlBody[i:]
+	} else {
+		htmlBody += footer
+	}
+	textBody += "\n\n" + s.t("email.unsubscribe.text", s.unsubscribeURL)
+	return htmlBody, textBody
+}
 
-	textBody := fmt.Sprintf("🎉 Selamat Datang!\n\nHalo %s,\n\nTerima kasih telah bergabung dengan GoEvent! 🎊\n\nAkun Anda telah berhasil dibuat. Sekarang Anda dapat:\n- ✨ Membuat dan mengelola event\n- 🎫 Mendaftar ke berbagai event menarik\n- 🔔 Mendapatkan notifikasi event\n- 📊 Melihat riwayat partisipasi Anda\n\nMulai jelajahi event yang tersedia dan ciptakan pengalaman tak terlupakan bersama kami!\n\n---\nGoEvent App\nEmail ini dikirim secara otomatis. Mohon tidak membalas email ini.", 
-		toName)
+// sendTemplate merender template lalu mengirimnya. Footer dan link unsubscribe
+// sudah ada di layout, jadi tidak ditambahkan lagi seperti di SendEmail
+func (s *service) sendTemplate(to, toName, name string, data interface{}) error {
+	rendered, err := s.render(name, data)
+	if err != nil {
+		log.Printf("[EMAIL] Failed to render template %s: %v", name, err)
+		return fmt.Errorf("failed to render email: %w", err)
+	}
+	return s.send(to, toName, rendered.Subject, rendered.HTML, rendered.Text)
+}
 
-	return s.SendEmail(to, toName, subject, htmlBody, textBody)
+// SendWelcomeEmail implements Service.
+func (s *service) SendWelcomeEmail(to, toName string) error {
+	return s.sendTemplate(to, toName, TemplateWelcome, WelcomeData{Name: toName, AppURL: s.cfg.CorsOrigin})
 }
 
 // SendReminderEmail implements Service.
-func (s *service) SendReminderEmail(to, toName, eventTitle, eventDate string) error {
-	subject := fmt.Sprintf("Reminder: Event '%s' akan segera dimulai", eventTitle)
-	
-	htmlBody := fmt.Sprintf(`
-		<html>
-			<body style="font-family: Arial, sans-serif; line-height: 1.6; color: #333;">
-				<div style="max-width: 600px; margin: 0 auto; padding: 20px; background-color: #ffffff;">
-					<div style="text-align: center; padding: 20px 0; background: linear-gradient(135deg, #667eea 0%%, #764ba2 100%%); border-radius: 8px 8px 0 0;">
-						<h1 style="color: #ffffff; margin: 0; font-size: 28px;">🔔 Event Reminder</h1>
-					</div>
-					<div style="padding: 30px; background-color: #f9fafb; border-radius: 0 0 8px 8px;">
-						<p style="font-size: 16px;">Halo <strong>%s</strong>,</p>
-						<p style="font-size: 16px;">Ini adalah pengingat bahwa event berikut akan segera dimulai:</p>
-						<div style="background-color: #ffffff; padding: 20px; border-left: 4px solid #667eea; border-radius: 4px; margin: 20px 0;">
-							<h2 style="color: #667eea; margin-top: 0; font-size: 22px;">%s</h2>
-							<p style="margin: 10px 0; font-size: 16px;">
-								<strong>📅 Waktu:</strong> %s
-							</p>
-						</div>
-						<p style="font-size: 16px;">Pastikan Anda sudah siap dan jangan sampai terlewat!</p>
-						<div style="text-align: center; margin-top: 30px;">
-							<p style="font-size: 14px; color: #666;">Sampai jumpa di event! 👋</p>
-						</div>
-					</div>
-					<div style="text-align: center; padding: 20px; background-color: #f3f4f6; border-radius: 0 0 8px 8px;">
-						<p style="font-size: 12px; color: #6b7280; margin: 0;">
-							Email ini dikirim secara otomatis oleh <strong>GoEvent App</strong><br>
-							Mohon tidak membalas email ini.
-						</p>
-					</div>
-				</div>
-			</body>
-		</html>
-	`, toName, eventTitle, eventDate)
-
-	textBody := fmt.Sprintf("🔔 Event Reminder\n\nHalo %s,\n\nIni adalah pengingat bahwa event '%s' akan segera dimulai pada %s.\n\nPastikan Anda sudah siap dan jangan sampai terlewat!\n\nSampai jumpa di event! 👋\n\n---\nGoEvent App\nEmail ini dikirim secara otomatis. Mohon tidak membalas email ini.", 
-		toName, eventTitle, eventDate)
-
-	return s.SendEmail(to, toName, subject, htmlBody, textBody)
+// message berisi teks reminder (bisa dari template organizer)
+func (s *service) SendReminderEmail(to, toName, eventTitle, eventDate, message string) error {
+	return s.sendTemplate(to, toName, TemplateReminder, ReminderData{
+		Name:       toName,
+		EventTitle: eventTitle,
+		EventDate:  eventDate,
+		Message:    strings.TrimSpace(message),
+	})
 }
 
 // SendRegistrationConfirmationEmail implements Service.
 func (s *service) SendRegistrationConfirmationEmail(to, toName, eventTitle, eventDate, eventLocation string) error {
-	subject := fmt.Sprintf("✅ Konfirmasi Pendaftaran: %s", eventTitle)
-	
-	htmlBody := fmt.Sprintf(`
-		<html>
-			<body style="font-family: Arial, sans-serif; line-height: 1.6; color: #333;">
-				<div style="max-width: 600px; margin: 0 auto; padding: 20px; background-color: #ffffff;">
-					<div style="text-align: center; padding: 20px 0; background: linear-gradient(135deg, #10b981 0%%, #059669 100%%); border-radius: 8px 8px 0 0;">
-						<h1 style="color: #ffffff; margin: 0; font-size: 28px;">✅ Pendaftaran Berhasil!</h1>
-					</div>
-					<div style="padding: 30px; background-color: #f0fdf4; border-radius: 0 0 8px 8px;">
-						<p style="font-size: 16px;">Halo <strong>%s</strong>,</p>
-						<p style="font-size: 16px;">Selamat! Pendaftaran Anda untuk event berikut telah berhasil dikonfirmasi:</p>
-						<div style="background-color: #ffffff; padding: 25px; border-left: 4px solid #10b981; border-radius: 4px; margin: 20px 0; box-shadow: 0 2px 4px rgba(0,0,0,0.1);">
-							<h2 style="color: #059669; margin-top: 0; font-size: 22px;">%s</h2>
-							<div style="margin-top: 15px;">
-								<p style="margin: 8px 0; font-size: 16px;">
-									<strong>📅 Waktu:</strong> %s
-								</p>
-								<p style="margin: 8px 0; font-size: 16px;">
-									<strong>📍 Lokasi:</strong> %s
-								</p>
-							</div>
-						</div>
-						<div style="background-color: #d1fae5; padding: 15px; border-radius: 6px; margin: 20px 0;">
-							<p style="margin: 0; font-size: 14px; color: #065f46;">
-								<strong>💡 Tips:</strong> Simpan email ini sebagai referensi dan jangan lupa untuk hadir tepat waktu!
-							</p>
-						</div>
-						<p style="font-size: 16px;">Kami akan mengirimkan pengingat menjelang event dimulai.</p>
-						<div style="text-align: center; margin-top: 30px;">
-							<p style="font-size: 14px; color: #666;">Sampai jumpa di event! 🎉</p>
-						</div>
-					</div>
-					<div style="text-align: center; padding: 20px; background-color: #f3f4f6; border-radius: 0 0 8px 8px;">
-						<p style="font-size: 12px; color: #6b7280; margin: 0;">
-							Email ini dikirim secara otomatis oleh <strong>GoEvent App</strong><br>
-							Mohon tidak membalas email ini.
-						</p>
-					</div>
-				</div>
-			</body>
-		</html>
-	`, toName, eventTitle, eventDate, eventLocation)
-
-	textBody := fmt.Sprintf("✅ Pendaftaran Berhasil!\n\nHalo %s,\n\nSelamat! Pendaftaran Anda untuk event berikut telah berhasil dikonfirmasi:\n\n%s\n\n📅 Waktu: %s\n📍 Lokasi: %s\n\n💡 Tips: Simpan email ini sebagai referensi dan jangan lupa untuk hadir tepat waktu!\n\nKami akan mengirimkan pengingat menjelang event dimulai.\n\nSampai jumpa di event! 🎉\n\n---\nGoEvent App\nEmail ini dikirim secara otomatis. Mohon tidak membalas email ini.", 
-		toName, eventTitle, eventDate, eventLocation)
-
-	return s.SendEmail(to, toName, subject, htmlBody, textBody)
+	return s.sendTemplate(to, toName, TemplateRegistration, RegistrationData{
+		Name:          toName,
+		EventTitle:    eventTitle,
+		EventDate:     eventDate,
+		EventLocation: eventLocation,
+	})
 }
 
 // SendCancellationEmail implements Service.
 func (s *service) SendCancellationEmail(to, toName, eventTitle string) error {
-	subject := fmt.Sprintf("❌ Pembatalan Event: %s", eventTitle)
-	
-	htmlBody := fmt.Sprintf(`
-		<html>
-			<body style="font-family: Arial, sans-serif; line-height: 1.6; color: #333;">
-				<div style="max-width: 600px; margin: 0 auto; padding: 20px; background-color: #ffffff;">
-					<div style="text-align: center; padding: 20px 0; background: linear-gradient(135deg, #ef4444 0%%, #dc2626 100%%); border-radius: 8px 8px 0 0;">
-						<h1 style="color: #ffffff; margin: 0; font-size: 28px;">❌ Event Dibatalkan</h1>
-					</div>
-					<div style="padding: 30px; background-color: #fef2f2; border-radius: 0 0 8px 8px;">
-						<p style="font-size: 16px;">Halo <strong>%s</strong>,</p>
-						<p style="font-size: 16px;">Kami informasikan bahwa event berikut telah dibatalkan:</p>
-						<div style="background-color: #ffffff; padding: 20px; border-left: 4px solid #ef4444; border-radius: 4px; margin: 20px 0;">
-							<h2 style="color: #dc2626; margin-top: 0; font-size: 22px;">%s</h2>
-						</div>
-						<p style="font-size: 16px;">Mohon maaf atas ketidaknyamanannya. Kami akan memberitahu Anda jika ada update lebih lanjut atau event pengganti.</p>
-						<div style="text-align: center; margin-top: 30px;">
-							<p style="font-size: 14px; color: #666;">Terima kasih atas pengertian Anda 🙏</p>
-						</div>
-					</div>
-					<div style="text-align: center; padding: 20px; background-color: #f3f4f6; border-radius: 0 0 8px 8px;">
-						<p style="font-size: 12px; color: #6b7280; margin: 0;">
-							Email ini dikirim secara otomatis oleh <strong>GoEvent App</strong><br>
-							Mohon tidak membalas email ini.
-						</p>
-					</div>
-				</div>
-			</body>
-		</html>
-	`, toName, eventTitle)
-
-	textBody := fmt.Sprintf("❌ Event Dibatalkan\n\nHalo %s,\n\nKami informasikan bahwa event '%s' telah dibatalkan.\n\nMohon maaf atas ketidaknyamanannya. Kami akan memberitahu Anda jika ada update lebih lanjut atau event pengganti.\n\nTerima kasih atas pengertian Anda 🙏\n\n---\nGoEvent App\nEmail ini dikirim secara otomatis. Mohon tidak membalas email ini.", 
-		toName, eventTitle)
-
-	return s.SendEmail(to, toName, subject, htmlBody, textBody)
+	return s.sendTemplate(to, toName, TemplateCancellation, CancellationData{Name: toName, EventTitle: eventTitle})
 }
 
 // SendUpdateEmail implements Service.
 func (s *service) SendUpdateEmail(to, toName, eventTitle, updateMessage string) error {
-	subject := fmt.Sprintf("📢 Update Event: %s", eventTitle)
-	
-	htmlBody := fmt.Sprintf(`
-		<html>
-			<body style="font-family: Arial, sans-serif; line-height: 1.6; color: #333;">
-				<div style="max-width: 600px; margin: 0 auto; padding: 20px; background-color: #ffffff;">
-					<div style="text-align: center; padding: 20px 0; background: linear-gradient(135deg, #3b82f6 0%%, #2563eb 100%%); border-radius: 8px 8px 0 0;">
-						<h1 style="color: #ffffff; margin: 0; font-size: 28px;">📢 Update Event</h1>
-					</div>
-					<div style="padding: 30px; background-color: #eff6ff; border-radius: 0 0 8px 8px;">
-						<p style="font-size: 16px;">Halo <strong>%s</strong>,</p>
-						<p style="font-size: 16px;">Ada update terbaru untuk event:</p>
-						<div style="background-color: #ffffff; padding: 20px; border-left: 4px solid #3b82f6; border-radius: 4px; margin: 20px 0;">
-							<h2 style="color: #2563eb; margin-top: 0; font-size: 22px;">%s</h2>
-						</div>
-						<div style="background-color: #dbeafe; padding: 20px; border-radius: 8px; margin: 20px 0;">
-							<p style="margin: 0; font-size: 16px; color: #1e40af;"><strong>Informasi Update:</strong></p>
-							<p style="margin: 10px 0 0 0; font-size: 16px;">%s</p>
-						</div>
-						<p style="font-size: 16px;">Terima kasih atas perhatiannya.</p>
-						<div style="text-align: center; margin-top: 30px;">
-							<p style="font-size: 14px; color: #666;">Tetap update dengan event Anda! ✨</p>
-						</div>
-					</div>
-					<div style="text-align: center; padding: 20px; background-color: #f3f4f6; border-radius: 0 0 8px 8px;">
-						<p style="font-size: 12px; color: #6b7280; margin: 0;">
-							Email ini dikirim secara otomatis oleh <strong>GoEvent App</strong><br>
-							Mohon tidak membalas email ini.
-						</p>
-					</div>
-				</div>
-			</body>
-		</html>
-	`, toName, eventTitle, updateMessage)
+	return s.sendTemplate(to, toName, TemplateUpdate, UpdateData{Name: toName, EventTitle: eventTitle, Message: updateMessage})
+}
+
+// SendAnnouncementEmail implements Service.
+// Pengumuman dari organizer (broadcast). Baris pertama message dipakai sebagai judul
+func (s *service) SendAnnouncementEmail(to, toName, eventTitle, message string) error {
+	title, body, _ := strings.Cut(message, "\n")
+	return s.sendTemplate(to, toName, TemplateAnnouncement, AnnouncementData{
+		Name:       toName,
+		EventTitle: eventTitle,
+		Title:      title,
+		Body:       strings.TrimSpace(body),
+	})
+}
 
-	textBody := fmt.Sprintf("📢 Update Event\n\nHalo %s,\n\nAda update terbaru untuk event '%s':\n\n%s\n\nTerima kasih atas perhatiannya.\n\nTetap update dengan event Anda! ✨\n\n---\nGoEvent App\nEmail ini dikirim secara otomatis. Mohon tidak membalas email ini.", 
-		toName, eventTitle, updateMessage)
+// SendGuestTicketEmail implements Service.
+// Dikirim ke guest dari group registration, berisi kode tiket untuk check-in
+func (s *service) SendGuestTicketEmail(to, toName, eventTitle, eventDate, eventLocation, ticketCode, registeredBy string) error {
+	return s.sendTemplate(to, toName, TemplateGuestTicket, GuestTicketData{
+		Name:          toName,
+		EventTitle:    eventTitle,
+		EventDate:     eventDate,
+		EventLocation: eventLocation,
+		TicketCode:    ticketCode,
+		RegisteredBy:  registeredBy,
+	})
+}
 
-	return s.SendEmail(to, toName, subject, htmlBody, textBody)
+// SendDigestEmail implements Service.
+// Ringkasan notifikasi yang belum dibaca. total bisa lebih banyak dari items,
+// sisanya hanya disebut jumlahnya
+func (s *service) SendDigestEmail(to, toName, frequency string, items []DigestItem, total int) error {
+	more := total - len(items)
+	if more < 0 {
+		more = 0
+	}
+	return s.sendTemplate(to, toName, TemplateDigest, DigestData{
+		Name:   toName,
+		Period: s.t("email.digest.period." + frequency),
+		Items:  items,
+		Total:  total,
+		More:   more,
+	})
 }
 
 func NewService(cfg *config.Config) Service {
 	client := mailjet.NewMailjetClient(cfg.MailjetAPIKey, cfg.MailjetAPISecret)
-	
+
 	return &service{
-		client: client,
-		cfg:    cfg,
+		client:    client,
+		cfg:       cfg,
+		templates: loadTemplates(cfg.EmailTemplateDir),
+		locale:    i18n.Default,
 	}
 }
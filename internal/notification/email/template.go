@@ -0,0 +1,366 @@
+package email
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"go-event/pkg/i18n"
+	"go-event/pkg/timezone"
+	"html"
+	htmltemplate "html/template"
+	"io/fs"
+	"log"
+	"os"
+	"strings"
+	texttemplate "text/template"
+	"time"
+)
+
+// Template bawaan. Tiap email punya <nama>.html dan <nama>.txt yang dirender
+// di dalam layout.html / layout.txt
+//
+//go:embed templates
+var defaultTemplates embed.FS
+
+// Nama template email yang tersedia
+const (
+	TemplateWelcome      = "welcome"
+	TemplateReminder     = "reminder"
+	TemplateRegistration = "registration"
+	TemplateCancellation = "cancellation"
+	TemplateUpdate       = "update"
+	TemplateAnnouncement = "announcement"
+	TemplateGuestTicket  = "guest_ticket"
+	TemplateDigest       = "digest"
+)
+
+var templateNames = []string{
+	TemplateWelcome,
+	TemplateReminder,
+	TemplateRegistration,
+	TemplateCancellation,
+	TemplateUpdate,
+	TemplateAnnouncement,
+	TemplateGuestTicket,
+	TemplateDigest,
+}
+
+// ErrUnknownTemplate dikembalikan Preview untuk nama template yang tidak ada
+var ErrUnknownTemplate = errors.New("unknown email template")
+
+// Rendered adalah hasil render satu email
+type Rendered struct {
+	Subject string `json:"subject"`
+	HTML    string `json:"html"`
+	Text    string `json:"text"`
+}
+
+// Data untuk tiap template. Name selalu nama penerima (dipakai layout untuk sapaan)
+
+type WelcomeData struct {
+	Name   string
+	AppURL string
+}
+
+type ReminderData struct {
+	Name       string
+	EventTitle string
+	EventDate  string
+	Message    string // teks reminder (bisa dari template organizer), boleh kosong
+}
+
+type RegistrationData struct {
+	Name          string
+	EventTitle    string
+	EventDate     string
+	EventLocation string
+}
+
+type CancellationData struct {
+	Name       string
+	EventTitle string
+}
+
+type UpdateData struct {
+	Name       string
+	EventTitle string
+	Message    string
+}
+
+type AnnouncementData struct {
+	Name       string
+	EventTitle string
+	Title      string
+	Body       string
+}
+
+type GuestTicketData struct {
+	Name          string
+	EventTitle    string
+	EventDate     string
+	EventLocation string
+	TicketCode    string
+	RegisteredBy  string
+}
+
+type DigestData struct {
+	Name   string
+	Period string // "harian"/"daily" dst, sudah diterjemahkan
+	Items  []DigestItem
+	Total  int
+	More   int // jumlah notifikasi yang tidak ditampilkan di Items
+}
+
+// templateSet berisi template HTML dan teks untuk satu email, sudah digabung dengan layout.
+// Template tidak pernah dieksekusi langsung, selalu di-Clone agar fungsi bisa diikat ke locale penerima
+type templateSet struct {
+	html *htmltemplate.Template
+	text *texttemplate.Template
+}
+
+// Fungsi template di-bind ulang per render (lihat render), ini hanya placeholder untuk parsing
+var htmlFuncs = htmltemplate.FuncMap{
+	"t":              func(string, ...interface{}) string { return "" },
+	"th":             func(string, ...interface{}) htmltemplate.HTML { return "" },
+	"strong":         strong,
+	"nl2br":          nl2br,
+	"datetime":       func(time.Time) string { return "" },
+	"unsubscribeURL": func() string { return "" },
+}
+
+var textFuncs = texttemplate.FuncMap{
+	"t":              func(string, ...interface{}) string { return "" },
+	"datetime":       func(time.Time) string { return "" },
+	"unsubscribeURL": func() string { return "" },
+}
+
+// overlayFS membaca file dari folder override dulu, lalu dari template bawaan
+type overlayFS struct {
+	override fs.FS
+	base     fs.FS
+}
+
+func (o overlayFS) Open(name string) (fs.File, error) {
+	if o.override != nil {
+		if f, err := o.override.Open(name); err == nil {
+			return f, nil
+		}
+	}
+	return o.base.Open(name)
+}
+
+// loadTemplates mem-parse semua template. File di dir (jika diisi) menggantikan
+// file bawaan dengan nama yang sama. Template override yang gagal di-parse dilewati
+// dengan log dan diganti template bawaan, agar satu file rusak tidak mematikan email
+func loadTemplates(dir string) map[string]*templateSet {
+	base, err := fs.Sub(defaultTemplates, "templates")
+	if err != nil {
+		panic(err)
+	}
+
+	var files fs.FS = base
+	if dir != "" {
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			log.Printf("[EMAIL] Template dir %q is not readable, using built-in templates", dir)
+		} else {
+			files = overlayFS{override: os.DirFS(dir), base: base}
+		}
+	}
+
+	sets := make(map[string]*templateSet, len(templateNames))
+	for _, name := range templateNames {
+		set, err := parseTemplateSet(files, name)
+		if err != nil && files != base {
+			log.Printf("[EMAIL] Failed to parse template %q from %s, using built-in: %v", name, dir, err)
+			set, err = parseTemplateSet(base, name)
+		}
+		if err != nil {
+			panic(fmt.Sprintf("email: invalid built-in template %q: %v", name, err))
+		}
+		sets[name] = set
+	}
+	return sets
+}
+
+func parseTemplateSet(files fs.FS, name string) (*templateSet, error) {
+	htmlSet := htmltemplate.New(name).Funcs(htmlFuncs)
+	for _, file := range []string{"layout.html", name + ".html"} {
+		src, err := fs.ReadFile(files, file)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := htmlSet.New(file).Parse(string(src)); err != nil {
+			return nil, err
+		}
+	}
+
+	textSet := texttemplate.New(name).Funcs(textFuncs)
+	for _, file := range []string{"layout.txt", name + ".txt"} {
+		src, err := fs.ReadFile(files, file)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := textSet.New(file).Parse(string(src)); err != nil {
+			return nil, err
+		}
+	}
+
+	// Pastikan semua bagian yang dibutuhkan ada, agar kesalahan ketahuan saat start
+	for _, part := range []string{"layout", "heading", "content"} {
+		if htmlSet.Lookup(part) == nil {
+			return nil, fmt.Errorf("%s.html: missing template %q", name, part)
+		}
+	}
+	for _, part := range []string{"layout", "subject", "heading", "body"} {
+		if textSet.Lookup(part) == nil {
+			return nil, fmt.Errorf("%s.txt: missing template %q", name, part)
+		}
+	}
+	return &templateSet{html: htmlSet, text: textSet}, nil
+}
+
+// render mengisi template dengan data dalam bahasa dan link unsubscribe milik s
+func (s *service) render(name string, data interface{}) (*Rendered, error) {
+	set, ok := s.templates[name]
+	if !ok {
+		return nil, ErrUnknownTemplate
+	}
+
+	translate := func(key string, args ...interface{}) string { return i18n.T(s.locale, key, args...) }
+	datetime := func(t time.Time) string { return i18n.FormatDateTime(s.locale, t) }
+	unsubscribeURL := func() string { return s.unsubscribeURL }
+
+	htmlTmpl, err := set.html.Clone()
+	if err != nil {
+		return nil, err
+	}
+	htmlTmpl.Funcs(htmltemplate.FuncMap{
+		"t":              translate,
+		"th":             s.translateHTML,
+		"datetime":       datetime,
+		"unsubscribeURL": unsubscribeURL,
+	})
+	textTmpl, err := set.text.Clone()
+	if err != nil {
+		return nil, err
+	}
+	textTmpl.Funcs(texttemplate.FuncMap{
+		"t":              translate,
+		"datetime":       datetime,
+		"unsubscribeURL": unsubscribeURL,
+	})
+
+	var subject, htmlBody, textBody bytes.Buffer
+	if err := textTmpl.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return nil, fmt.Errorf("render %s subject: %w", name, err)
+	}
+	if err := htmlTmpl.ExecuteTemplate(&htmlBody, "layout", data); err != nil {
+		return nil, fmt.Errorf("render %s html: %w", name, err)
+	}
+	if err := textTmpl.ExecuteTemplate(&textBody, "layout", data); err != nil {
+		return nil, fmt.Errorf("render %s text: %w", name, err)
+	}
+
+	return &Rendered{
+		Subject: strings.TrimSpace(subject.String()),
+		HTML:    htmlBody.String(),
+		Text:    strings.TrimSpace(textBody.String()),
+	}, nil
+}
+
+// translateHTML menerjemahkan key untuk body HTML. Teks katalog dan args di-escape,
+// kecuali args bertipe template.HTML (misalnya hasil strong)
+func (s *service) translateHTML(key string, args ...interface{}) htmltemplate.HTML {
+	msg := html.EscapeString(i18n.Message(s.locale, key))
+	if len(args) == 0 {
+		return htmltemplate.HTML(msg)
+	}
+	escaped := make([]interface{}, len(args))
+	for i, arg := range args {
+		switch v := arg.(type) {
+		case htmltemplate.HTML:
+			escaped[i] = string(v)
+		case string:
+			escaped[i] = html.EscapeString(v)
+		default:
+			escaped[i] = arg
+		}
+	}
+	return htmltemplate.HTML(fmt.Sprintf(msg, escaped...))
+}
+
+// strong menebalkan nilai yang sudah di-escape
+func strong(v interface{}) htmltemplate.HTML {
+	return htmltemplate.HTML("<strong>" + html.EscapeString(fmt.Sprint(v)) + "</strong>")
+}
+
+// nl2br meng-escape teks dan mengubah baris baru menjadi <br>
+func nl2br(s string) htmltemplate.HTML {
+	return htmltemplate.HTML(strings.ReplaceAll(html.EscapeString(s), "\n", "<br>"))
+}
+
+// TemplateNames implements Service.
+func (s *service) TemplateNames() []string {
+	names := make([]string, len(templateNames))
+	copy(names, templateNames)
+	return names
+}
+
+// Preview implements Service.
+// Template dirender dengan data contoh, tanpa mengirim email
+func (s *service) Preview(name string, locale i18n.Locale) (*Rendered, error) {
+	mailer := &service{
+		cfg:            s.cfg,
+		templates:      s.templates,
+		locale:         locale,
+		unsubscribeURL: strings.TrimRight(s.cfg.AppBaseURL, "/") + "/api/notification/unsubscribe?token=preview",
+	}
+	data, ok := mailer.sampleData(name)
+	if !ok {
+		return nil, ErrUnknownTemplate
+	}
+	return mailer.render(name, data)
+}
+
+// sampleData adalah data contoh untuk Preview
+func (s *service) sampleData(name string) (interface{}, bool) {
+	const (
+		recipient = "Budi Santoso"
+		title     = "Go Meetup Jakarta"
+		location  = "Jakarta Convention Center"
+	)
+	loc := timezone.Load("Asia/Jakarta")
+	now := time.Now().In(loc)
+	date := i18n.FormatDateTime(s.locale, now.Add(7*24*time.Hour).Truncate(time.Hour))
+
+	switch name {
+	case TemplateWelcome:
+		return WelcomeData{Name: recipient, AppURL: s.cfg.CorsOrigin}, true
+	case TemplateReminder:
+		return ReminderData{Name: recipient, EventTitle: title, EventDate: date,
+			Message: i18n.T(s.locale, "notification.reminder", title, date)}, true
+	case TemplateRegistration:
+		return RegistrationData{Name: recipient, EventTitle: title, EventDate: date, EventLocation: location}, true
+	case TemplateCancellation:
+		return CancellationData{Name: recipient, EventTitle: title}, true
+	case TemplateUpdate:
+		return UpdateData{Name: recipient, EventTitle: title,
+			Message: i18n.T(s.locale, "notification.change.location", location)}, true
+	case TemplateAnnouncement:
+		return AnnouncementData{Name: recipient, EventTitle: title, Title: "Parkir & akses masuk",
+			Body: "Parkir tersedia di basement P2.\nSilakan masuk melalui pintu utara."}, true
+	case TemplateGuestTicket:
+		return GuestTicketData{Name: "Siti Rahma", EventTitle: title, EventDate: date, EventLocation: location,
+			TicketCode: "GE-7K2P9X", RegisteredBy: recipient}, true
+	case TemplateDigest:
+		items := []DigestItem{
+			{EventTitle: title, Message: i18n.T(s.locale, "notification.reminder", title, date), SentAt: now.Add(-2 * time.Hour)},
+			{EventTitle: title, Message: i18n.T(s.locale, "notification.change.location", location), SentAt: now.Add(-5 * time.Hour)},
+		}
+		return DigestData{Name: recipient, Period: i18n.T(s.locale, "email.digest.period.daily"),
+			Items: items, Total: 5, More: 3}, true
+	default:
+		return nil, false
+	}
+}
@@ -0,0 +1,10 @@
+package notification
+
+import "go-event/pkg/apperror"
+
+var (
+	ErrNotificationNotFound    = apperror.New(apperror.KindNotFound, "NOTIFICATION_NOT_FOUND", "notification not found or unauthorized")
+	ErrEventNotFound           = apperror.New(apperror.KindNotFound, "EVENT_NOT_FOUND", "event not found")
+	ErrInvalidUnsubscribeToken = apperror.New(apperror.KindBadRequest, "UNSUBSCRIBE_TOKEN_INVALID", "unsubscribe link is invalid")
+	ErrEmailTemplateNotFound   = apperror.New(apperror.KindNotFound, "EMAIL_TEMPLATE_NOT_FOUND", "email template not found")
+)
@@ -43,6 +43,12 @@ func ParseNotifType(s string) (NotifType, bool) {
 		return NotifUpdate, true
 	case string(NotifCancellation):
 		return NotifCancellation, true
+	case string(NotifApproval):
+		return NotifApproval, true
+	case string(NotifRejection):
+		return NotifRejection, true
+	case string(NotifAnnouncement):
+		return NotifAnnouncement, true
 	default:
 		return "", false
 	}
@@ -0,0 +1,85 @@
+package notification
+
+import "sync"
+
+// subscriptionBuffer adalah jumlah notifikasi yang boleh antre per koneksi. Koneksi
+// yang tertinggal lebih dari ini diputus, client reconnect dan resume dari last id
+const subscriptionBuffer = 32
+
+// Hub mendistribusikan notifikasi yang baru disimpan ke koneksi realtime (SSE/WebSocket)
+// milik user. Implementasi default in-process; untuk beberapa instance server bisa
+// diganti broker (Redis pub/sub, NATS) tanpa mengubah service maupun controller
+type Hub interface {
+	Publish(userID uint, notif NotificationResponse)
+	Subscribe(userID uint) *Subscription
+}
+
+// Subscription adalah satu koneksi realtime. C ditutup saat Close dipanggil
+// atau saat koneksi terlalu lambat membaca
+type Subscription struct {
+	C <-chan NotificationResponse
+
+	ch    chan NotificationResponse
+	close func()
+	once  sync.Once
+}
+
+// Close melepas subscription dari hub, aman dipanggil berkali-kali
+func (s *Subscription) Close() {
+	s.once.Do(s.close)
+}
+
+type memoryHub struct {
+	mu   sync.Mutex
+	subs map[uint]map[*Subscription]struct{}
+}
+
+// NewMemoryHub membuat hub in-process (satu instance server)
+func NewMemoryHub() Hub {
+	return &memoryHub{subs: make(map[uint]map[*Subscription]struct{})}
+}
+
+// Publish implements Hub.
+// Tidak pernah block: subscriber yang buffer-nya penuh diputus
+func (h *memoryHub) Publish(userID uint, notif NotificationResponse) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for sub := range h.subs[userID] {
+		select {
+		case sub.ch <- notif:
+		default:
+			h.remove(userID, sub)
+		}
+	}
+}
+
+// Subscribe implements Hub.
+func (h *memoryHub) Subscribe(userID uint) *Subscription {
+	ch := make(chan NotificationResponse, subscriptionBuffer)
+	sub := &Subscription{C: ch, ch: ch}
+	sub.close = func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		h.remove(userID, sub)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.subs[userID] == nil {
+		h.subs[userID] = make(map[*Subscription]struct{})
+	}
+	h.subs[userID][sub] = struct{}{}
+	return sub
+}
+
+// remove harus dipanggil dengan h.mu terkunci
+func (h *memoryHub) remove(userID uint, sub *Subscription) {
+	if _, ok := h.subs[userID][sub]; !ok {
+		return
+	}
+	delete(h.subs[userID], sub)
+	if len(h.subs[userID]) == 0 {
+		delete(h.subs, userID)
+	}
+	close(sub.ch)
+}
@@ -2,6 +2,7 @@ package notification
 
 import (
 	"go-event/internal/user"
+	"go-event/pkg/pagination"
 	"time"
 )
 
@@ -11,26 +12,66 @@ const (
 	NotifReminder     NotifType = "reminder"
 	NotifUpdate       NotifType = "update"
 	NotifCancellation NotifType = "cancellation"
+	// Hasil review pendaftaran pada event dengan approval mode
+	NotifApproval  NotifType = "approval"
+	NotifRejection NotifType = "rejection"
+	// Pengumuman dari organizer ke participant event (broadcast)
+	NotifAnnouncement NotifType = "announcement"
 )
 
 // 🧱 Entity (database model)
 type Notification struct {
 	ID        uint      `json:"id" gorm:"primaryKey"`
-	UserID    uint      `json:"user_id"`
+	UserID    uint      `json:"user_id" gorm:"index:idx_notifications_user_read,priority:1"`
 	EventID   *uint     `json:"event_id"`
-	Type      NotifType `json:"type"` // reminder, update, cancellation
+	Type      NotifType `json:"type"` // reminder, update, cancellation, approval, rejection, announcement
 	Message   string    `json:"message"`
-	IsRead    bool      `json:"is_read"`
-	SentAt    time.Time `json:"sent_at"`
+	IsRead    bool      `json:"is_read" gorm:"index:idx_notifications_user_read,priority:2"`
+	SentAt    time.Time `json:"sent_at" gorm:"index"`
 	User      user.User `json:"user" gorm:"foreignKey:UserID"`
 }
 
+func (n *Notification) ToResponse() *NotificationResponse {
+	return &NotificationResponse{
+		ID:      n.ID,
+		Type:    n.Type,
+		Message: n.Message,
+		IsRead:  n.IsRead,
+		SentAt:  n.SentAt,
+		EventID: n.EventID,
+	}
+}
+
 // 📩 Request structs
 type CreateNotificationRequest struct {
 	UserID  uint   `json:"user_id" form:"user_id" validate:"required"`
 	EventID *uint  `json:"event_id" form:"event_id"`
-	Type    string `json:"type" form:"type" validate:"required"`
-	Message string `json:"message" form:"message" validate:"required"`
+	Type    string `json:"type" form:"type" validate:"required,oneof=reminder update cancellation"`
+	Message string `json:"message" form:"message" validate:"required,notblank,max=2000"`
+	// EventTitle dipakai di email jika event sudah dihapus saat email dikirim
+	EventTitle string `json:"-" form:"-"`
+}
+
+// ListQuery adalah filter listing inbox, semua opsional
+type ListQuery struct {
+	Type    string `json:"type" query:"type" validate:"omitempty,oneof=reminder update cancellation approval rejection announcement"`
+	IsRead  string `json:"is_read" query:"is_read" validate:"omitempty,oneof=true false"`
+	EventID uint   `json:"event_id" query:"event_id"`
+}
+
+// ListFilter adalah ListQuery yang sudah di-parse untuk repository
+type ListFilter struct {
+	Type    NotifType
+	IsRead  *bool
+	EventID uint
+	Offset  int
+	Limit   int
+}
+
+// BulkDeleteRequest menghapus notifikasi berdasarkan ID, atau semua yang sudah dibaca
+type BulkDeleteRequest struct {
+	IDs     []uint `json:"ids" validate:"required_without=AllRead,omitempty,max=100,dive,gt=0"`
+	AllRead bool   `json:"all_read"`
 }
 
 // 📤 Response structs
@@ -43,3 +84,17 @@ type NotificationResponse struct {
 	EventID *uint     `json:"event_id,omitempty"`
 }
 
+
+// NotificationPage adalah satu halaman inbox. UnreadCount dihitung dari seluruh
+// notifikasi user (tidak terpengaruh filter)
+type NotificationPage struct {
+	Notifications []NotificationResponse `json:"notifications"`
+	Pagination    pagination.Meta        `json:"pagination"`
+	UnreadCount   int64                  `json:"unread_count"`
+}
+
+// UnreadCountResponse adalah jumlah notifikasi belum dibaca, total dan per tipe
+type UnreadCountResponse struct {
+	Total  int64               `json:"total"`
+	ByType map[NotifType]int64 `json:"by_type"`
+}
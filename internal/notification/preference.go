@@ -0,0 +1,141 @@
+package notification
+
+import (
+	"fmt"
+	"time"
+)
+
+// NotifTypes adalah semua tipe notifikasi yang preferensinya bisa diatur user
+var NotifTypes = []NotifType{NotifReminder, NotifUpdate, NotifCancellation, NotifApproval, NotifRejection, NotifAnnouncement}
+
+// ChannelPreference adalah channel yang aktif untuk satu tipe notifikasi.
+// Push disimpan untuk client mobile, pengirimnya belum ada
+type ChannelPreference struct {
+	InApp bool `json:"in_app"`
+	Email bool `json:"email"`
+	Push  bool `json:"push"`
+}
+
+// defaultChannels berlaku untuk tipe yang belum pernah diatur user
+var defaultChannels = ChannelPreference{InApp: true, Email: true}
+
+// 🧱 Entity (database model)
+
+// NotificationPreference menyimpan channel per user per tipe. Tidak ada baris = defaultChannels
+type NotificationPreference struct {
+	ID        uint      `gorm:"primaryKey"`
+	UserID    uint      `gorm:"uniqueIndex:idx_notification_preferences_user_type,priority:1"`
+	Type      NotifType `gorm:"size:32;uniqueIndex:idx_notification_preferences_user_type,priority:2"`
+	InApp     bool
+	Email     bool
+	Push      bool
+	UpdatedAt time.Time
+}
+
+// NotificationSettings menyimpan pengaturan notifikasi umum per user
+type NotificationSettings struct {
+	UserID uint `gorm:"primaryKey;autoIncrement:false"`
+	// Quiet hours "HH:MM" di timezone user, kosong = tidak aktif. Boleh melewati tengah malam (22:00-07:00)
+	QuietHoursStart string `gorm:"size:5"`
+	QuietHoursEnd   string `gorm:"size:5"`
+	// Email ringkasan notifikasi yang belum dibaca, kosong/off = tidak aktif
+	Digest       DigestFrequency `gorm:"size:10;index"`
+	LastDigestAt *time.Time
+	UpdatedAt    time.Time
+}
+
+// ReminderOptOut: user tidak mau menerima reminder untuk event tertentu
+type ReminderOptOut struct {
+	UserID    uint `gorm:"primaryKey;autoIncrement:false"`
+	EventID   uint `gorm:"primaryKey;autoIncrement:false;index"`
+	CreatedAt time.Time
+}
+
+// DeferredEmail adalah email notifikasi yang ditahan karena quiet hours,
+// dikirim scheduler setelah SendAfter
+type DeferredEmail struct {
+	ID         uint `gorm:"primaryKey"`
+	UserID     uint `gorm:"index"`
+	EventID    *uint
+	EventTitle string    `gorm:"size:255"` // cadangan jika event sudah dihapus saat email dikirim
+	Type       NotifType `gorm:"size:32"`
+	Message    string    `gorm:"type:text"`
+	ToEmail    string    `gorm:"size:255"`
+	ToName     string    `gorm:"size:100"`
+	SendAfter  time.Time `gorm:"index"`
+	CreatedAt  time.Time
+}
+
+// QuietHours adalah rentang waktu harian tanpa email, dalam timezone user
+type QuietHours struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+// Until mengembalikan akhir quiet hours jika now berada di dalamnya
+func (q QuietHours) Until(now time.Time, loc *time.Location) (time.Time, bool) {
+	start, errStart := parseClock(q.Start)
+	end, errEnd := parseClock(q.End)
+	if errStart != nil || errEnd != nil || start == end {
+		return time.Time{}, false
+	}
+
+	local := now.In(loc)
+	minute := local.Hour()*60 + local.Minute()
+	endAt := func(dayOffset int) time.Time {
+		return time.Date(local.Year(), local.Month(), local.Day()+dayOffset, end/60, end%60, 0, 0, loc)
+	}
+	if start < end {
+		if minute >= start && minute < end {
+			return endAt(0), true
+		}
+		return time.Time{}, false
+	}
+	// Melewati tengah malam, contoh 22:00-07:00
+	switch {
+	case minute >= start:
+		return endAt(1), true
+	case minute < end:
+		return endAt(0), true
+	}
+	return time.Time{}, false
+}
+
+// parseClock mengubah "HH:MM" menjadi menit sejak tengah malam
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid clock %q: %w", s, err)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// 📩 Request structs
+
+// UpdatePreferencesRequest mengubah sebagian preferensi, field yang tidak dikirim tidak berubah
+type UpdatePreferencesRequest struct {
+	// Kirim start & end kosong untuk mematikan quiet hours
+	QuietHours *QuietHoursRequest                  `json:"quiet_hours"`
+	Channels   map[string]ChannelPreferenceRequest `json:"channels" validate:"omitempty,max=10,dive,keys,oneof=reminder update cancellation approval rejection announcement,endkeys"`
+	Digest     *string                             `json:"digest" validate:"omitempty,oneof=off daily weekly"`
+}
+
+type QuietHoursRequest struct {
+	Start string `json:"start" validate:"required_with=End,omitempty,datetime=15:04"`
+	End   string `json:"end" validate:"required_with=Start,omitempty,datetime=15:04"`
+}
+
+type ChannelPreferenceRequest struct {
+	InApp *bool `json:"in_app"`
+	Email *bool `json:"email"`
+	Push  *bool `json:"push"`
+}
+
+// 📤 Response structs
+type PreferencesResponse struct {
+	Timezone            string                          `json:"timezone"`
+	QuietHours          *QuietHours                     `json:"quiet_hours"` // null = tidak aktif
+	Channels            map[NotifType]ChannelPreference `json:"channels"`
+	MutedReminderEvents []uint                          `json:"muted_reminder_events"`
+	Digest              DigestFrequency                 `json:"digest"`
+}
@@ -0,0 +1,174 @@
+package notification
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-event/pkg/apperror"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	// heartbeatInterval menjaga koneksi tetap hidup melewati proxy dan mendeteksi client yang sudah pergi
+	heartbeatInterval = 25 * time.Second
+	// sseRetry adalah jeda reconnect (ms) yang disarankan ke EventSource
+	sseRetry = 3000
+)
+
+// errSubscriptionClosed: hub memutus subscription (client terlalu lambat)
+var errSubscriptionClosed = errors.New("subscription closed")
+
+// realtimeMessage adalah frame WebSocket, field event disiapkan untuk tipe pesan lain
+type realtimeMessage struct {
+	Event string               `json:"event"`
+	Data  NotificationResponse `json:"data"`
+}
+
+// StreamEvents - Server-Sent Events, resume dari header Last-Event-ID atau query last_id
+func (ctrl *Controller) StreamEvents(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	lastID, err := parseLastID(c.Get("Last-Event-ID"), c.Query("last_id"))
+	if err != nil {
+		return err
+	}
+	// Subscribe sebelum membaca backlog agar tidak ada notifikasi yang jatuh di antaranya
+	sub := ctrl.service.Subscribe(userID)
+	backlog, err := ctrl.backlog(userID, lastID)
+	if err != nil {
+		sub.Close()
+		return err
+	}
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+	c.Set("X-Accel-Buffering", "no") // matikan buffering nginx
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer sub.Close()
+		fmt.Fprintf(w, "retry: %d\n\n", sseRetry)
+		send := func(n NotificationResponse) error {
+			data, err := json.Marshal(n)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(w, "id: %d\nevent: notification\ndata: %s\n\n", n.ID, data)
+			return w.Flush()
+		}
+		ping := func() error {
+			fmt.Fprint(w, ": ping\n\n")
+			return w.Flush()
+		}
+		// Error di sini biasanya client menutup koneksi, cukup berhenti
+		_ = streamNotifications(sub, backlog, lastID, send, ping)
+	})
+	return nil
+}
+
+// StreamWebSocket - WebSocket, resume dari query last_id. Pesan dari client diabaikan
+func (ctrl *Controller) StreamWebSocket(c *fiber.Ctx) error {
+	if !websocket.IsWebSocketUpgrade(c) {
+		return fiber.ErrUpgradeRequired
+	}
+	userID := c.Locals("userID").(uint)
+	lastID, err := parseLastID("", c.Query("last_id"))
+	if err != nil {
+		return err
+	}
+
+	return websocket.New(func(conn *websocket.Conn) {
+		defer conn.Close()
+		sub := ctrl.service.Subscribe(userID)
+		defer sub.Close()
+
+		backlog, err := ctrl.backlog(userID, lastID)
+		if err != nil {
+			log.Printf("notification ws user %d: %v", userID, err)
+			_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseInternalServerErr, "internal error"))
+			return
+		}
+
+		// Read loop hanya untuk mendeteksi client menutup koneksi
+		go func() {
+			for {
+				if _, _, err := conn.ReadMessage(); err != nil {
+					sub.Close()
+					return
+				}
+			}
+		}()
+
+		send := func(n NotificationResponse) error {
+			return conn.WriteJSON(realtimeMessage{Event: "notification", Data: n})
+		}
+		ping := func() error {
+			return conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(heartbeatInterval))
+		}
+		_ = streamNotifications(sub, backlog, lastID, send, ping)
+	})(c)
+}
+
+// backlog mengambil notifikasi yang terlewat. lastID 0 = koneksi baru, tanpa backlog
+// (daftar lengkap diambil lewat GET /api/notification)
+func (ctrl *Controller) backlog(userID, lastID uint) ([]NotificationResponse, error) {
+	if lastID == 0 {
+		return nil, nil
+	}
+	return ctrl.service.GetNotificationsSince(userID, lastID)
+}
+
+// streamNotifications mengirim backlog lalu notifikasi baru dari hub sampai koneksi
+// putus. Notifikasi dengan ID <= yang terakhir dikirim dilewati (bisa muncul di
+// backlog dan hub sekaligus)
+func streamNotifications(sub *Subscription, backlog []NotificationResponse, lastID uint, send func(NotificationResponse) error, ping func() error) error {
+	for _, n := range backlog {
+		if err := send(n); err != nil {
+			return err
+		}
+		lastID = n.ID
+	}
+
+	ticker := time.NewTicker(heartbeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case n, ok := <-sub.C:
+			if !ok {
+				return errSubscriptionClosed
+			}
+			if n.ID <= lastID {
+				continue
+			}
+			if err := send(n); err != nil {
+				return err
+			}
+			lastID = n.ID
+		case <-ticker.C:
+			if err := ping(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// parseLastID membaca ID notifikasi terakhir yang diterima client, header didahulukan
+func parseLastID(header, query string) (uint, error) {
+	raw := header
+	if raw == "" {
+		raw = query
+	}
+	if raw == "" {
+		return 0, nil
+	}
+	id, err := strconv.ParseUint(raw, 10, 32)
+	if err != nil {
+		return 0, apperror.InvalidParam("last event ID")
+	}
+	return uint(id), nil
+}
@@ -1,12 +1,48 @@
 package notification
 
-import "gorm.io/gorm"
+import (
+	"errors"
+	"go-event/internal/user"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
 
 type Repository interface {
 	Create(notification *Notification) error
-	GetByUserID(userID uint) ([]Notification, error)
+	GetByUserIDAfter(userID uint, afterID uint, limit int) ([]Notification, error)
+	GetByID(notificationID uint) (*Notification, error)
+	GetByIDForUser(notificationID uint, userID uint) (*Notification, error)
+	List(userID uint, filter ListFilter) ([]Notification, int64, error)
+	CountUnreadByType(userID uint) (map[NotifType]int64, error)
 	MarkAsRead(notificationID uint) error
-	Delete(notification *Notification) error
+	MarkAllAsRead(userID uint, filter ListFilter) (int64, error)
+	DeleteForUser(userID uint, ids []uint) (int64, error)
+	DeleteReadForUser(userID uint) (int64, error)
+	PurgeRead(before time.Time, limit int) (int64, error)
+
+	// Preferensi notifikasi
+	GetPreferences(userID uint) ([]NotificationPreference, error)
+	SavePreferences(prefs []NotificationPreference) error
+	GetSettings(userID uint) (*NotificationSettings, error)
+	SaveSettings(settings *NotificationSettings) error
+	GetUserTimezone(userID uint) (string, error)
+	GetUserLocale(userID uint) (string, error)
+	IsReminderMuted(userID uint, eventID uint) (bool, error)
+	GetMutedEventIDs(userID uint) ([]uint, error)
+	MuteReminders(userID uint, eventID uint) error
+	UnmuteReminders(userID uint, eventID uint) error
+
+	// Email yang ditunda karena quiet hours
+	CreateDeferredEmail(deferred *DeferredEmail) error
+	FindDueDeferredEmails(now time.Time, limit int) ([]DeferredEmail, error)
+	DeleteDeferredEmail(id uint) error
+
+	// Digest
+	FindDigestRecipients() ([]DigestRecipient, error)
+	GetUnreadSince(userID uint, since time.Time, limit int) ([]Notification, int64, error)
+	SetLastDigestAt(userID uint, at time.Time) error
 }
 
 type repository struct {
@@ -18,16 +54,82 @@ func (r *repository) Create(notification *Notification) error {
 	return r.db.Create(notification).Error
 }
 
-// Delete implements Repository.
-func (r *repository) Delete(notification *Notification) error {
-  return r.db.Delete(notification).Error
+// GetByID implements Repository.
+// Tanpa cek kepemilikan, dipakai audit log
+func (r *repository) GetByID(notificationID uint) (*Notification, error) {
+	var notification Notification
+	if err := r.db.Where("id = ?", notificationID).Take(&notification).Error; err != nil {
+		return nil, err
+	}
+	return &notification, nil
+}
+
+// GetByIDForUser implements Repository.
+// Sekaligus cek kepemilikan: gorm.ErrRecordNotFound jika bukan milik user
+func (r *repository) GetByIDForUser(notificationID uint, userID uint) (*Notification, error) {
+	var notification Notification
+	if err := r.db.Where("id = ? AND user_id = ?", notificationID, userID).Take(&notification).Error; err != nil {
+		return nil, err
+	}
+	return &notification, nil
+}
+
+// List implements Repository.
+// Satu halaman inbox (paling baru dulu) dan total sesuai filter
+func (r *repository) List(userID uint, filter ListFilter) ([]Notification, int64, error) {
+	query := r.filtered(userID, filter)
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var notifications []Notification
+	err := query.Session(&gorm.Session{}).
+		Order("sent_at desc").Order("id desc").
+		Offset(filter.Offset).Limit(filter.Limit).
+		Find(&notifications).Error
+	return notifications, total, err
 }
 
+// filtered membangun query notifikasi user sesuai filter (tanpa offset/limit)
+func (r *repository) filtered(userID uint, filter ListFilter) *gorm.DB {
+	query := r.db.Model(&Notification{}).Where("user_id = ?", userID)
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+	if filter.IsRead != nil {
+		query = query.Where("is_read = ?", *filter.IsRead)
+	}
+	if filter.EventID != 0 {
+		query = query.Where("event_id = ?", filter.EventID)
+	}
+	return query
+}
 
-// GetByUserID implements Repository.
-func (r *repository) GetByUserID(userID uint) ([]Notification, error) {
+// CountUnreadByType implements Repository.
+func (r *repository) CountUnreadByType(userID uint) (map[NotifType]int64, error) {
+	var rows []struct {
+		Type  NotifType
+		Count int64
+	}
+	err := r.db.Model(&Notification{}).
+		Select("type, COUNT(*) AS count").
+		Where("user_id = ? AND is_read = ?", userID, false).
+		Group("type").
+		Scan(&rows).Error
+	counts := make(map[NotifType]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Type] = row.Count
+	}
+	return counts, err
+}
+
+// GetByUserIDAfter implements Repository.
+// Notifikasi dengan ID > afterID, paling baru dulu, untuk resume koneksi realtime
+func (r *repository) GetByUserIDAfter(userID uint, afterID uint, limit int) ([]Notification, error) {
 	var notifications []Notification
-	err := r.db.Preload("User").Where("user_id = ?", userID).Order("sent_at desc").Find(&notifications).Error
+	err := r.db.Where("user_id = ? AND id > ?", userID, afterID).
+		Order("id desc").Limit(limit).Find(&notifications).Error
 	return notifications, err
 }
 
@@ -36,6 +138,164 @@ func (r *repository) MarkAsRead(notificationID uint) error {
 	return r.db.Model(&Notification{}).Where("id = ?", notificationID).Update("is_read", true).Error
 }
 
+// MarkAllAsRead implements Repository.
+// Filter IsRead diabaikan, hanya notifikasi yang belum dibaca yang diubah
+func (r *repository) MarkAllAsRead(userID uint, filter ListFilter) (int64, error) {
+	filter.IsRead = nil
+	result := r.filtered(userID, filter).Where("is_read = ?", false).Update("is_read", true)
+	return result.RowsAffected, result.Error
+}
+
+// DeleteForUser implements Repository.
+// ID yang bukan milik user dilewati
+func (r *repository) DeleteForUser(userID uint, ids []uint) (int64, error) {
+	result := r.db.Where("user_id = ? AND id IN ?", userID, ids).Delete(&Notification{})
+	return result.RowsAffected, result.Error
+}
+
+// DeleteReadForUser implements Repository.
+func (r *repository) DeleteReadForUser(userID uint) (int64, error) {
+	result := r.db.Where("user_id = ? AND is_read = ?", userID, true).Delete(&Notification{})
+	return result.RowsAffected, result.Error
+}
+
+// PurgeRead implements Repository.
+// Menghapus maksimal limit notifikasi yang sudah dibaca dan dikirim sebelum before
+func (r *repository) PurgeRead(before time.Time, limit int) (int64, error) {
+	var ids []uint
+	if err := r.db.Model(&Notification{}).
+		Where("is_read = ? AND sent_at < ?", true, before).
+		Order("id").Limit(limit).
+		Pluck("id", &ids).Error; err != nil || len(ids) == 0 {
+		return 0, err
+	}
+	result := r.db.Delete(&Notification{}, ids)
+	return result.RowsAffected, result.Error
+}
+
+// GetPreferences implements Repository.
+func (r *repository) GetPreferences(userID uint) ([]NotificationPreference, error) {
+	var prefs []NotificationPreference
+	err := r.db.Where("user_id = ?", userID).Find(&prefs).Error
+	return prefs, err
+}
+
+// SavePreferences implements Repository.
+// Upsert per (user_id, type)
+func (r *repository) SavePreferences(prefs []NotificationPreference) error {
+	if len(prefs) == 0 {
+		return nil
+	}
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "type"}},
+		DoUpdates: clause.AssignmentColumns([]string{"in_app", "email", "push", "updated_at"}),
+	}).Create(&prefs).Error
+}
+
+// GetSettings implements Repository.
+// nil jika user belum pernah mengatur
+func (r *repository) GetSettings(userID uint) (*NotificationSettings, error) {
+	var settings NotificationSettings
+	err := r.db.Where("user_id = ?", userID).Take(&settings).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &settings, err
+}
+
+// SaveSettings implements Repository.
+func (r *repository) SaveSettings(settings *NotificationSettings) error {
+	return r.db.Clauses(clause.OnConflict{UpdateAll: true}).Create(settings).Error
+}
+
+// GetUserTimezone implements Repository.
+func (r *repository) GetUserTimezone(userID uint) (string, error) {
+	var timezone string
+	err := r.db.Model(&user.User{}).Select("timezone").Where("id = ?", userID).Scan(&timezone).Error
+	return timezone, err
+}
+
+// GetUserLocale implements Repository.
+func (r *repository) GetUserLocale(userID uint) (string, error) {
+	var locale string
+	err := r.db.Model(&user.User{}).Select("locale").Where("id = ?", userID).Scan(&locale).Error
+	return locale, err
+}
+
+// IsReminderMuted implements Repository.
+func (r *repository) IsReminderMuted(userID uint, eventID uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&ReminderOptOut{}).Where("user_id = ? AND event_id = ?", userID, eventID).Count(&count).Error
+	return count > 0, err
+}
+
+// GetMutedEventIDs implements Repository.
+func (r *repository) GetMutedEventIDs(userID uint) ([]uint, error) {
+	ids := []uint{}
+	err := r.db.Model(&ReminderOptOut{}).Where("user_id = ?", userID).Order("event_id").Pluck("event_id", &ids).Error
+	return ids, err
+}
+
+// MuteReminders implements Repository.
+func (r *repository) MuteReminders(userID uint, eventID uint) error {
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&ReminderOptOut{UserID: userID, EventID: eventID}).Error
+}
+
+// UnmuteReminders implements Repository.
+func (r *repository) UnmuteReminders(userID uint, eventID uint) error {
+	return r.db.Where("user_id = ? AND event_id = ?", userID, eventID).Delete(&ReminderOptOut{}).Error
+}
+
+// CreateDeferredEmail implements Repository.
+func (r *repository) CreateDeferredEmail(deferred *DeferredEmail) error {
+	return r.db.Create(deferred).Error
+}
+
+// FindDueDeferredEmails implements Repository.
+func (r *repository) FindDueDeferredEmails(now time.Time, limit int) ([]DeferredEmail, error) {
+	var emails []DeferredEmail
+	err := r.db.Where("send_after <= ?", now).Order("send_after").Limit(limit).Find(&emails).Error
+	return emails, err
+}
+
+// DeleteDeferredEmail implements Repository.
+func (r *repository) DeleteDeferredEmail(id uint) error {
+	return r.db.Delete(&DeferredEmail{}, id).Error
+}
+
+// FindDigestRecipients implements Repository.
+func (r *repository) FindDigestRecipients() ([]DigestRecipient, error) {
+	var recipients []DigestRecipient
+	err := r.db.Model(&NotificationSettings{}).
+		Select("notification_settings.user_id, notification_settings.digest, notification_settings.last_digest_at, users.email, users.name, users.timezone, users.locale").
+		Joins("JOIN users ON users.id = notification_settings.user_id").
+		Where("notification_settings.digest IN ?", []DigestFrequency{DigestDaily, DigestWeekly}).
+		Scan(&recipients).Error
+	return recipients, err
+}
+
+// GetUnreadSince implements Repository.
+// Notifikasi belum dibaca setelah since (paling baru dulu, maksimal limit) dan jumlah totalnya
+func (r *repository) GetUnreadSince(userID uint, since time.Time, limit int) ([]Notification, int64, error) {
+	query := r.db.Model(&Notification{}).Where("user_id = ? AND is_read = ? AND sent_at > ?", userID, false, since)
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var notifications []Notification
+	if total == 0 {
+		return notifications, 0, nil
+	}
+	err := query.Session(&gorm.Session{}).Order("sent_at desc").Limit(limit).Find(&notifications).Error
+	return notifications, total, err
+}
+
+// SetLastDigestAt implements Repository.
+func (r *repository) SetLastDigestAt(userID uint, at time.Time) error {
+	return r.db.Model(&NotificationSettings{}).Where("user_id = ?", userID).Update("last_digest_at", at).Error
+}
+
 func Newrepository(db *gorm.DB) Repository {
 	return &repository{db: db}
 }
@@ -2,20 +2,41 @@
 package notification
 
 import (
+	"go-event/internal/audit"
 	"go-event/pkg/config"
 	"go-event/pkg/middlewares"
 
 	"github.com/gofiber/fiber/v2"
 )
 
-func SetupNotificationRoutes(app *fiber.App, ctrl *Controller, cfg *config.Config) {
+func SetupNotificationRoutes(app *fiber.App, ctrl *Controller, cfg *config.Config, auditLog *audit.Recorder) {
 	notif := app.Group("/api/notification")
 
 	// Semua user yang authenticated bisa mengakses notifikasi mereka
 	notif.Get("/", middlewares.Authenticate(cfg), ctrl.GetNotifications)
-	notif.Put("/:id/read", middlewares.Authenticate(cfg), ctrl.MarkAsRead)
-	notif.Delete("/:id", middlewares.Authenticate(cfg), ctrl.DeleteNotification)
+	notif.Get("/unread-count", middlewares.Authenticate(cfg), ctrl.GetUnreadCount)
+	notif.Put("/read-all", middlewares.Authenticate(cfg), auditLog.Record("notification.read_all", audit.Bulk("notification")), ctrl.MarkAllAsRead)
+	notif.Post("/bulk-delete", middlewares.Authenticate(cfg), auditLog.Record("notification.bulk_delete", audit.Bulk("notification")), ctrl.BulkDelete)
+	// Push realtime, autentikasi sama (header Bearer atau cookie token)
+	notif.Get("/stream", middlewares.Authenticate(cfg), ctrl.StreamEvents)
+	notif.Get("/ws", middlewares.Authenticate(cfg), ctrl.StreamWebSocket)
+	// Preferensi channel per tipe, quiet hours, dan opt-out reminder per event
+	notif.Get("/preferences", middlewares.Authenticate(cfg), ctrl.GetPreferences)
+	notif.Put("/preferences", middlewares.Authenticate(cfg), auditLog.Record("notification.update_preferences", audit.Self("notification_preferences")), ctrl.UpdatePreferences)
+	notif.Post("/preferences/events/:id/mute", middlewares.Authenticate(cfg), auditLog.Record("notification.mute_event", audit.Param("event", "id")), ctrl.MuteEventReminders)
+	notif.Delete("/preferences/events/:id/mute", middlewares.Authenticate(cfg), auditLog.Record("notification.unmute_event", audit.Param("event", "id")), ctrl.UnmuteEventReminders)
+
+	// Link unsubscribe di email, tanpa login (diautentikasi oleh token bertanda tangan)
+	notif.Get("/unsubscribe", ctrl.UnsubscribePage)
+	notif.Post("/unsubscribe", auditLog.Record("notification.unsubscribe", audit.Lookup("notification_preferences", ctrl.unsubscribeUserID)), ctrl.Unsubscribe)
+
+	notif.Put("/:id/read", middlewares.Authenticate(cfg), auditLog.Record("notification.read", audit.Param("notification", "id")), ctrl.MarkAsRead)
+	notif.Delete("/:id", middlewares.Authenticate(cfg), auditLog.Record("notification.delete", audit.Param("notification", "id")), ctrl.DeleteNotification)
 
 	// Hanya admin yang bisa create notifikasi (untuk testing atau manual trigger)
-	notif.Post("/", middlewares.Authenticate(cfg), middlewares.Authorize("admin"), ctrl.CreateNotification)
+	notif.Post("/", middlewares.Authenticate(cfg), middlewares.Authorize("admin"), auditLog.Record("notification.create", audit.Created("notification", "notification.id")), ctrl.CreateNotification)
+
+	// Preview template email dengan data contoh (admin), untuk mengecek template override
+	notif.Get("/email-templates", middlewares.Authenticate(cfg), middlewares.Authorize("admin"), ctrl.GetEmailTemplates)
+	notif.Get("/email-templates/:name/preview", middlewares.Authenticate(cfg), middlewares.Authorize("admin"), ctrl.PreviewEmailTemplate)
 }
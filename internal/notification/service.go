@@ -5,20 +5,53 @@ import (
 	"errors"
 	"fmt"
 	"go-event/internal/event"
+	"go-event/internal/eventbus"
 	"go-event/internal/notification/email"
+	"go-event/internal/participant"
+	"go-event/internal/user"
+	"go-event/pkg/apperror"
 	"go-event/pkg/config"
+	"go-event/pkg/i18n"
+	"go-event/pkg/pagination"
+	"go-event/pkg/timezone"
+	"go-event/pkg/validation"
+	"log"
+	"strconv"
 	"strings"
 	"time"
+
+	"gorm.io/gorm"
 )
 
 type Service interface {
 	CreateNotification(req *CreateNotificationRequest) (*NotificationResponse, error)
 	CreateNotificationWithEmail(req *CreateNotificationRequest, userEmail, userName string) (*NotificationResponse, error)
 	SendNotificationWithEmail(userID uint, eventID uint, notifType NotifType, message, userEmail, userName string) error
-	SendNotificationWithEmailByString(userID uint, eventID uint, notifTypeStr string, message, userEmail, userName string) error
-	GetNotificationsByUserID(userID uint) ([]NotificationResponse, error)
+	// locale guest mengikuti user yang mendaftarkannya
+	SendGuestEmail(eventID uint, notifType NotifType, message, guestEmail, guestName string, locale i18n.Locale) error
+	ListNotifications(userID uint, query *ListQuery, page pagination.Params) (*NotificationPage, error)
+	GetUnreadCount(userID uint) (*UnreadCountResponse, error)
+	GetNotificationsSince(userID uint, lastID uint) ([]NotificationResponse, error)
+	Subscribe(userID uint) *Subscription
 	MarkNotificationAsRead(notificationID uint, userID uint) error
 	DeleteNotification(notificationID uint, userID uint) error
+	MarkAllAsRead(userID uint, query *ListQuery) (int64, error)
+	BulkDelete(userID uint, req *BulkDeleteRequest) (int64, error)
+	PurgeReadNotifications(now time.Time) error
+	GetPreferences(userID uint) (*PreferencesResponse, error)
+	UpdatePreferences(userID uint, req *UpdatePreferencesRequest) (*PreferencesResponse, error)
+	MuteEventReminders(userID uint, eventID uint) error
+	UnmuteEventReminders(userID uint, eventID uint) error
+	VerifyUnsubscribeToken(token string) (uint, string, error)
+	Unsubscribe(token string) (string, error)
+	SendDeferredEmails(now time.Time) error
+	SendDigests(now time.Time) error
+	ListEmailTemplates() []string
+	PreviewEmailTemplate(name string, locale i18n.Locale) (*email.Rendered, error)
+	NotifyEventUpdate(eventData *event.Event, changes []event.FieldChange) error
+	// Subscriber domain event (lihat subscriber.go)
+	OnEventCancelled(e eventbus.EventCancelled) error
+	OnParticipantReviewed(e eventbus.ParticipantReviewed) error
 }
 
 type service struct {
@@ -26,28 +59,30 @@ type service struct {
 	eventRepo    event.Repository
 	cfg          *config.Config
 	emailService email.Service
+	hub          Hub
+	// participantRepo dan userRepo dipakai untuk mengabari semua participant event
+	participantRepo participant.Repository
+	userRepo        user.Repository
+	// retention adalah umur notifikasi yang sudah dibaca sebelum dihapus, 0 = disimpan selamanya
+	retention time.Duration
 }
 
+const (
+	// maxResumeBacklog adalah jumlah notifikasi terlewat yang dikirim ulang saat reconnect
+	maxResumeBacklog = 100
+	// deferredEmailBatch adalah jumlah email tertunda yang dikirim per putaran scheduler
+	deferredEmailBatch = 200
+	// purgeBatch adalah jumlah notifikasi yang dihapus per query saat retention
+	purgeBatch = 1000
+	// defaultRetentionDays dipakai jika NOTIFICATION_RETENTION_DAYS tidak valid
+	defaultRetentionDays = 90
+)
+
 // CreateNotification implements Service.
 func (s *service) CreateNotification(req *CreateNotificationRequest) (*NotificationResponse, error) {
-	// ================================
-	// VALIDASI DIPINDAHKAN KE SERVICE
-	// ================================
-
-	// user id wajib
-	if req.UserID == 0 {
-		return nil, errors.New("user_id is required")
-	}
-
-	// message wajib
-	if strings.TrimSpace(req.Message) == "" {
-		return nil, errors.New("message is required")
-	}
-
-	// type wajib dan harus valid
-	notifType, ok := ParseNotifType(req.Type)
-	if !ok {
-		return nil, errors.New("invalid notification type")
+	notifType, err := validateNotification(req)
+	if err != nil {
+		return nil, err
 	}
 
 	// Buat model
@@ -62,68 +97,212 @@ func (s *service) CreateNotification(req *CreateNotificationRequest) (*Notificat
 
 	// Simpan ke database
 	if err := s.repo.Create(notification); err != nil {
-		return nil, fmt.Errorf("failed to create notification: %w", err)
+		return nil, apperror.Internal(fmt.Errorf("failed to create notification: %w", err))
 	}
 
-	// Response ke controller
-	return &NotificationResponse{
-		ID:      notification.ID,
-		Type:    notification.Type,
-		Message: notification.Message,
-		IsRead:  notification.IsRead,
-		SentAt:  notification.SentAt,
-		EventID: notification.EventID,
-	}, nil
+	// Response ke controller, sekaligus dikirim ke koneksi realtime user
+	response := notification.ToResponse()
+	s.hub.Publish(notification.UserID, *response)
+	return response, nil
+}
+
+// validateNotification memvalidasi request notifikasi dan mengembalikan tipenya
+func validateNotification(req *CreateNotificationRequest) (NotifType, error) {
+	// user id wajib
+	if req.UserID == 0 {
+		return "", validation.NewError("user_id", "required", "", "is required")
+	}
+
+	// message wajib
+	if strings.TrimSpace(req.Message) == "" {
+		return "", validation.NewError("message", "required", "", "is required")
+	}
+
+	// type wajib dan harus valid
+	notifType, ok := ParseNotifType(req.Type)
+	if !ok {
+		return "", validation.NewError("type", "oneof", "reminder update cancellation", "must be one of: reminder, update, cancellation")
+	}
+	return notifType, nil
 }
 
 // CreateNotificationWithEmail implements Service.
+// Preferensi user dicek sebelum dikirim: channel per tipe, opt-out reminder per event,
+// dan quiet hours (email ditunda sampai quiet hours selesai). Response nil jika
+// notifikasi in-app tidak dibuat
 func (s *service) CreateNotificationWithEmail(req *CreateNotificationRequest, userEmail, userName string) (*NotificationResponse, error) {
-	// Buat notifikasi di database terlebih dahulu
-	notification, err := s.CreateNotification(req)
+	notifType, err := validateNotification(req)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := s.planDelivery(req.UserID, notifType, req.EventID, time.Now())
 	if err != nil {
 		return nil, err
 	}
 
-	// Kirim email berdasarkan tipe notifikasi (async, tidak block jika gagal)
-	go func() {
-		var emailErr error
-
-		// Ambil detail event jika ada
-		var eventTitle string
-		var eventDate string
-		if req.EventID != nil {
-			eventData, err := s.eventRepo.GetByID(*req.EventID)
-			if err == nil {
-				eventTitle = eventData.Title
-				eventDate = eventData.StartTime.Format("02 Jan 2006 15:04")
-			} else {
-				eventTitle = "Event"
-				eventDate = "segera"
-			}
-		} else {
-			eventTitle = "Event"
-			eventDate = "segera"
+	var notification *NotificationResponse
+	if plan.InApp {
+		if notification, err = s.CreateNotification(req); err != nil {
+			return nil, err
 		}
+	}
+	if !plan.Email {
+		return notification, nil
+	}
 
-		notifType, _ := ParseNotifType(req.Type)
-		switch notifType {
-		case NotifReminder:
-			emailErr = s.emailService.SendReminderEmail(userEmail, userName, eventTitle, eventDate)
-		case NotifCancellation:
-			emailErr = s.emailService.SendCancellationEmail(userEmail, userName, eventTitle)
-		case NotifUpdate:
-			emailErr = s.emailService.SendUpdateEmail(userEmail, userName, eventTitle, req.Message)
+	if !plan.emailAt.IsZero() {
+		deferred := &DeferredEmail{
+			UserID:     req.UserID,
+			EventID:    req.EventID,
+			EventTitle: req.EventTitle,
+			Type:       notifType,
+			Message:    req.Message,
+			ToEmail:    userEmail,
+			ToName:     userName,
+			SendAfter:  plan.emailAt,
+		}
+		if err := s.repo.CreateDeferredEmail(deferred); err != nil {
+			return nil, apperror.Internal(fmt.Errorf("failed to defer email: %w", err))
 		}
+		return notification, nil
+	}
 
-		if emailErr != nil {
-			// Log error tapi tidak mempengaruhi response
-			// Bisa tambahkan logging di sini
+	// Kirim email berdasarkan tipe notifikasi (async, tidak block jika gagal)
+	go func() {
+		mailer := s.emailService.WithUnsubscribe(s.unsubscribeURL(req.UserID, string(notifType)))
+		locale, userTimezone := s.userRegion(req.UserID)
+		if err := s.sendEmail(mailer, locale, userTimezone, req.EventID, req.EventTitle, notifType, req.Message, userEmail, userName); err != nil {
+			log.Printf("Failed to send %s email to %s: %v", notifType, userEmail, err)
 		}
 	}()
 
 	return notification, nil
 }
 
+// deliveryPlan adalah channel yang dipakai untuk satu notifikasi
+type deliveryPlan struct {
+	ChannelPreference
+	emailAt time.Time // zero = kirim sekarang
+}
+
+// planDelivery membaca preferensi user untuk satu notifikasi. Quiet hours hanya menunda
+// email, notifikasi in-app tetap dibuat karena tidak berbunyi
+func (s *service) planDelivery(userID uint, notifType NotifType, eventID *uint, now time.Time) (*deliveryPlan, error) {
+	if notifType == NotifReminder && eventID != nil {
+		muted, err := s.repo.IsReminderMuted(userID, *eventID)
+		if err != nil {
+			return nil, apperror.Internal(err)
+		}
+		if muted {
+			return &deliveryPlan{}, nil
+		}
+	}
+
+	channels, err := s.channels(userID)
+	if err != nil {
+		return nil, err
+	}
+	plan := &deliveryPlan{ChannelPreference: channels[notifType]}
+	if !plan.Email {
+		return plan, nil
+	}
+
+	quiet, userTimezone, err := s.quietHours(userID)
+	if err != nil {
+		return nil, err
+	}
+	if quiet != nil {
+		if until, ok := quiet.Until(now, timezone.Load(userTimezone)); ok {
+			plan.emailAt = until
+		}
+	}
+	return plan, nil
+}
+
+// channels mengembalikan preferensi channel untuk semua tipe (default jika belum diatur)
+func (s *service) channels(userID uint) (map[NotifType]ChannelPreference, error) {
+	prefs, err := s.repo.GetPreferences(userID)
+	if err != nil {
+		return nil, apperror.Internal(err)
+	}
+	channels := make(map[NotifType]ChannelPreference, len(NotifTypes))
+	for _, t := range NotifTypes {
+		channels[t] = defaultChannels
+	}
+	for _, p := range prefs {
+		channels[p.Type] = ChannelPreference{InApp: p.InApp, Email: p.Email, Push: p.Push}
+	}
+	return channels, nil
+}
+
+// quietHours mengembalikan quiet hours (nil jika tidak aktif) dan timezone user
+func (s *service) quietHours(userID uint) (*QuietHours, string, error) {
+	settings, err := s.repo.GetSettings(userID)
+	if err != nil {
+		return nil, "", apperror.Internal(err)
+	}
+	userTimezone, err := s.repo.GetUserTimezone(userID)
+	if err != nil {
+		return nil, "", apperror.Internal(err)
+	}
+	if settings == nil || settings.QuietHoursStart == "" || settings.QuietHoursEnd == "" {
+		return nil, userTimezone, nil
+	}
+	return &QuietHours{Start: settings.QuietHoursStart, End: settings.QuietHoursEnd}, userTimezone, nil
+}
+
+// SendGuestEmail implements Service.
+// Guest (attendee dari group registration) tidak punya akun sehingga hanya dikirimi email
+// tanpa record notifikasi in-app
+func (s *service) SendGuestEmail(eventID uint, notifType NotifType, message, guestEmail, guestName string, locale i18n.Locale) error {
+	// Guest tidak punya preferensi timezone, waktu event ditulis dalam timezone event
+	return s.sendEmail(s.emailService, locale, "", &eventID, "", notifType, message, guestEmail, guestName)
+}
+
+// sendEmail mengirim email sesuai tipe notifikasi dengan detail event (jika ada) dalam
+// bahasa dan timezone penerima (kosong = timezone event). mailer adalah emailService,
+// atau turunannya yang membawa link unsubscribe. eventTitle dipakai jika event sudah
+// tidak ada (dihapus), kosong = judul fallback
+func (s *service) sendEmail(mailer email.Service, locale i18n.Locale, userTimezone string, eventID *uint, eventTitle string, notifType NotifType, message, toEmail, toName string) error {
+	mailer = mailer.WithLocale(locale)
+	if eventTitle == "" {
+		eventTitle = i18n.T(locale, "email.event_fallback")
+	}
+	eventDate := i18n.T(locale, "email.date_fallback")
+	if eventID != nil {
+		if eventData, err := s.eventRepo.GetByID(*eventID); err == nil {
+			eventTitle = eventData.Title
+			eventDate = i18n.FormatDateTime(locale, eventData.StartTime.In(timezone.Resolve(userTimezone, eventData.Timezone)))
+		}
+	}
+
+	switch notifType {
+	case NotifReminder:
+		return mailer.SendReminderEmail(toEmail, toName, eventTitle, eventDate, message)
+	case NotifCancellation:
+		return mailer.SendCancellationEmail(toEmail, toName, eventTitle)
+	case NotifUpdate, NotifApproval, NotifRejection:
+		return mailer.SendUpdateEmail(toEmail, toName, eventTitle, message)
+	case NotifAnnouncement:
+		return mailer.SendAnnouncementEmail(toEmail, toName, eventTitle, message)
+	}
+	return nil
+}
+
+// userRegion membaca bahasa dan timezone user untuk email, fallback ke default
+// (timezone kosong = timezone event) jika gagal
+func (s *service) userRegion(userID uint) (i18n.Locale, string) {
+	locale, err := s.repo.GetUserLocale(userID)
+	if err != nil {
+		log.Printf("Failed to get locale of user %d: %v", userID, err)
+	}
+	userTimezone, err := s.repo.GetUserTimezone(userID)
+	if err != nil {
+		log.Printf("Failed to get timezone of user %d: %v", userID, err)
+	}
+	return i18n.Resolve(locale), userTimezone
+}
+
 // SendNotificationWithEmail adalah helper method untuk mengirim notifikasi dari package lain
 func (s *service) SendNotificationWithEmail(userID uint, eventID uint, notifType NotifType, message, userEmail, userName string) error {
 	req := &CreateNotificationRequest{
@@ -137,105 +316,383 @@ func (s *service) SendNotificationWithEmail(userID uint, eventID uint, notifType
 	return err
 }
 
-// SendNotificationWithEmailByString adalah wrapper yang menerima string type untuk digunakan dari package lain
-func (s *service) SendNotificationWithEmailByString(userID uint, eventID uint, notifTypeStr string, message, userEmail, userName string) error {
-	var notifType NotifType
-	
-	switch notifTypeStr {
-	case "cancellation":
-		notifType = NotifCancellation
-	case "update":
-		notifType = NotifUpdate
-	case "reminder":
-		notifType = NotifReminder
-	default:
-		notifType = NotifUpdate
+// ListNotifications implements Service.
+func (s *service) ListNotifications(userID uint, query *ListQuery, page pagination.Params) (*NotificationPage, error) {
+	page.Normalize()
+	filter := newListFilter(query)
+	filter.Offset, filter.Limit = page.Offset(), page.Limit()
+
+	notifications, total, err := s.repo.List(userID, filter)
+	if err != nil {
+		return nil, apperror.Internal(fmt.Errorf("failed to retrieve notifications: %w", err))
 	}
-	
-	return s.SendNotificationWithEmail(userID, eventID, notifType, message, userEmail, userName)
+	unread, err := s.GetUnreadCount(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &NotificationPage{
+		Notifications: make([]NotificationResponse, 0, len(notifications)),
+		Pagination:    pagination.NewMeta(page, total),
+		UnreadCount:   unread.Total,
+	}
+	for i := range notifications {
+		result.Notifications = append(result.Notifications, *notifications[i].ToResponse())
+	}
+	return result, nil
 }
 
-// GetNotificationsByUserID implements Service.
-func (s *service) GetNotificationsByUserID(userID uint) ([]NotificationResponse, error) {
-	notifications, err := s.repo.GetByUserID(userID)
+// newListFilter mengubah query yang sudah divalidasi menjadi filter repository
+func newListFilter(query *ListQuery) ListFilter {
+	filter := ListFilter{Type: NotifType(query.Type), EventID: query.EventID}
+	if query.IsRead != "" {
+		isRead, _ := strconv.ParseBool(query.IsRead) // sudah divalidasi oneof
+		filter.IsRead = &isRead
+	}
+	return filter
+}
+
+// GetUnreadCount implements Service.
+func (s *service) GetUnreadCount(userID uint) (*UnreadCountResponse, error) {
+	counts, err := s.repo.CountUnreadByType(userID)
 	if err != nil {
-		return nil, errors.New("failed to retrieve notifications: " + err.Error())
+		return nil, apperror.Internal(err)
 	}
-	var responses []NotificationResponse
-	for _, notif := range notifications {
-		responses = append(responses, NotificationResponse{
-			ID:      notif.ID,
-			Type:    notif.Type,
-			Message: notif.Message,
-			IsRead:  notif.IsRead,
-			SentAt:  notif.SentAt,
-			EventID: notif.EventID,
-		})
+	result := &UnreadCountResponse{ByType: make(map[NotifType]int64, len(NotifTypes))}
+	for _, t := range NotifTypes {
+		result.ByType[t] = counts[t]
+		result.Total += counts[t]
 	}
+	return result, nil
+}
 
+// GetNotificationsSince implements Service.
+// Notifikasi setelah lastID (urut lama ke baru) yang terlewat saat koneksi realtime
+// putus. Jika lebih dari maxResumeBacklog, hanya yang terbaru yang dikirim
+func (s *service) GetNotificationsSince(userID uint, lastID uint) ([]NotificationResponse, error) {
+	notifications, err := s.repo.GetByUserIDAfter(userID, lastID, maxResumeBacklog)
+	if err != nil {
+		return nil, apperror.Internal(fmt.Errorf("failed to retrieve notifications: %w", err))
+	}
+	responses := make([]NotificationResponse, 0, len(notifications))
+	for i := len(notifications) - 1; i >= 0; i-- {
+		responses = append(responses, *notifications[i].ToResponse())
+	}
 	return responses, nil
 }
 
+// Subscribe implements Service.
+func (s *service) Subscribe(userID uint) *Subscription {
+	return s.hub.Subscribe(userID)
+}
+
 // MarkNotificationAsRead implements Service.
 func (s *service) MarkNotificationAsRead(notificationID uint, userID uint) error {
-	// Get notification untuk validasi ownership
-	notifications, err := s.repo.GetByUserID(userID)
+	// Ambil notifikasi sekaligus validasi ownership
+	notification, err := s.repo.GetByIDForUser(notificationID, userID)
 	if err != nil {
-		return errors.New("failed to retrieve notifications")
-	}
-
-	// Cek apakah notifikasi milik user
-	var found bool
-	for _, notif := range notifications {
-		if notif.ID == notificationID {
-			found = true
-			break
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNotificationNotFound
 		}
+		return apperror.Internal(err)
 	}
-
-	if !found {
-		return errors.New("notification not found or unauthorized")
+	if notification.IsRead {
+		return nil
 	}
 
 	if err := s.repo.MarkAsRead(notificationID); err != nil {
-		return errors.New("failed to mark notification as read: " + err.Error())
+		return apperror.Internal(fmt.Errorf("failed to mark notification as read: %w", err))
 	}
 
 	return nil
 }
 
 // DeleteNotification implements Service.
+// Hanya menghapus notifikasi milik user, selain itu dianggap tidak ditemukan
 func (s *service) DeleteNotification(notificationID uint, userID uint) error {
-	// Get notification untuk validasi ownership
-	notifications, err := s.repo.GetByUserID(userID)
+	deleted, err := s.repo.DeleteForUser(userID, []uint{notificationID})
+	if err != nil {
+		return apperror.Internal(fmt.Errorf("failed to delete notification: %w", err))
+	}
+	if deleted == 0 {
+		return ErrNotificationNotFound
+	}
+	return nil
+}
+
+// MarkAllAsRead implements Service.
+// Filter type/event_id opsional, mengembalikan jumlah notifikasi yang diubah
+func (s *service) MarkAllAsRead(userID uint, query *ListQuery) (int64, error) {
+	updated, err := s.repo.MarkAllAsRead(userID, newListFilter(query))
 	if err != nil {
-		return errors.New("failed to retrieve notifications")
+		return 0, apperror.Internal(fmt.Errorf("failed to mark notifications as read: %w", err))
 	}
+	return updated, nil
+}
 
-	// Cari notifikasi yang akan dihapus
-	var targetNotif *Notification
-	for i := range notifications {
-		if notifications[i].ID == notificationID {
-			targetNotif = &notifications[i]
-			break
+// BulkDelete implements Service.
+// ID yang bukan milik user dilewati, mengembalikan jumlah yang benar-benar dihapus
+func (s *service) BulkDelete(userID uint, req *BulkDeleteRequest) (int64, error) {
+	if req.AllRead && len(req.IDs) > 0 {
+		return 0, validation.NewError("ids", "excluded_with", "all_read", "cannot be combined with all_read")
+	}
+
+	var deleted int64
+	var err error
+	if req.AllRead {
+		deleted, err = s.repo.DeleteReadForUser(userID)
+	} else {
+		deleted, err = s.repo.DeleteForUser(userID, req.IDs)
+	}
+	if err != nil {
+		return 0, apperror.Internal(fmt.Errorf("failed to delete notifications: %w", err))
+	}
+	return deleted, nil
+}
+
+// PurgeReadNotifications implements Service.
+// Dipanggil scheduler, menghapus notifikasi yang sudah dibaca dan lebih tua dari retention
+func (s *service) PurgeReadNotifications(now time.Time) error {
+	if s.retention <= 0 {
+		return nil
+	}
+	before := now.Add(-s.retention)
+	for {
+		deleted, err := s.repo.PurgeRead(before, purgeBatch)
+		if err != nil {
+			return err
+		}
+		if deleted < purgeBatch {
+			return nil
+		}
+	}
+}
+
+// GetPreferences implements Service.
+func (s *service) GetPreferences(userID uint) (*PreferencesResponse, error) {
+	channels, err := s.channels(userID)
+	if err != nil {
+		return nil, err
+	}
+	quiet, userTimezone, err := s.quietHours(userID)
+	if err != nil {
+		return nil, err
+	}
+	muted, err := s.repo.GetMutedEventIDs(userID)
+	if err != nil {
+		return nil, apperror.Internal(err)
+	}
+	settings, err := s.repo.GetSettings(userID)
+	if err != nil {
+		return nil, apperror.Internal(err)
+	}
+	digest := DigestOff
+	if settings != nil && settings.Digest.Enabled() {
+		digest = settings.Digest
+	}
+	return &PreferencesResponse{
+		Timezone:            userTimezone,
+		QuietHours:          quiet,
+		Channels:            channels,
+		MutedReminderEvents: muted,
+		Digest:              digest,
+	}, nil
+}
+
+// UpdatePreferences implements Service.
+// Hanya field yang dikirim yang diubah; timezone diatur lewat profil user
+func (s *service) UpdatePreferences(userID uint, req *UpdatePreferencesRequest) (*PreferencesResponse, error) {
+	if req.QuietHours != nil || req.Digest != nil {
+		settings, err := s.settings(userID)
+		if err != nil {
+			return nil, err
+		}
+		if req.QuietHours != nil {
+			settings.QuietHoursStart = req.QuietHours.Start
+			settings.QuietHoursEnd = req.QuietHours.End
+		}
+		if req.Digest != nil {
+			digest := DigestFrequency(*req.Digest)
+			if digest.Enabled() && digest != settings.Digest {
+				// Digest pertama dikirim di jadwal berikutnya, berisi notifikasi setelah ini
+				now := time.Now()
+				settings.LastDigestAt = &now
+			}
+			settings.Digest = digest
+		}
+		if err := s.repo.SaveSettings(settings); err != nil {
+			return nil, apperror.Internal(err)
+		}
+	}
+
+	if len(req.Channels) > 0 {
+		channels, err := s.channels(userID)
+		if err != nil {
+			return nil, err
+		}
+		prefs := make([]NotificationPreference, 0, len(req.Channels))
+		for rawType, change := range req.Channels {
+			notifType, _ := ParseNotifType(rawType) // sudah divalidasi oneof
+			current := channels[notifType]
+			if change.InApp != nil {
+				current.InApp = *change.InApp
+			}
+			if change.Email != nil {
+				current.Email = *change.Email
+			}
+			if change.Push != nil {
+				current.Push = *change.Push
+			}
+			prefs = append(prefs, newPreference(userID, notifType, current))
+		}
+		if err := s.repo.SavePreferences(prefs); err != nil {
+			return nil, apperror.Internal(err)
 		}
 	}
 
-	if targetNotif == nil {
-		return errors.New("notification not found or unauthorized")
+	return s.GetPreferences(userID)
+}
+
+// settings mengambil pengaturan user, atau pengaturan kosong jika belum pernah disimpan
+func (s *service) settings(userID uint) (*NotificationSettings, error) {
+	settings, err := s.repo.GetSettings(userID)
+	if err != nil {
+		return nil, apperror.Internal(err)
+	}
+	if settings == nil {
+		settings = &NotificationSettings{UserID: userID}
+	}
+	return settings, nil
+}
+
+func newPreference(userID uint, notifType NotifType, channels ChannelPreference) NotificationPreference {
+	return NotificationPreference{
+		UserID: userID,
+		Type:   notifType,
+		InApp:  channels.InApp,
+		Email:  channels.Email,
+		Push:   channels.Push,
+	}
+}
+
+// MuteEventReminders implements Service.
+func (s *service) MuteEventReminders(userID uint, eventID uint) error {
+	if _, err := s.eventRepo.GetByID(eventID); err != nil {
+		return ErrEventNotFound
 	}
+	if err := s.repo.MuteReminders(userID, eventID); err != nil {
+		return apperror.Internal(err)
+	}
+	return nil
+}
 
-	if err := s.repo.Delete(targetNotif); err != nil {
-		return errors.New("failed to delete notification: " + err.Error())
+// UnmuteEventReminders implements Service.
+func (s *service) UnmuteEventReminders(userID uint, eventID uint) error {
+	if err := s.repo.UnmuteReminders(userID, eventID); err != nil {
+		return apperror.Internal(err)
 	}
 	return nil
 }
 
-func NewService(repo Repository, eventRepo event.Repository, emailService email.Service, cfg *config.Config) Service {
+// ListEmailTemplates implements Service.
+func (s *service) ListEmailTemplates() []string {
+	return s.emailService.TemplateNames()
+}
+
+// PreviewEmailTemplate implements Service.
+// Merender template (termasuk override dari EMAIL_TEMPLATE_DIR) dengan data contoh
+func (s *service) PreviewEmailTemplate(name string, locale i18n.Locale) (*email.Rendered, error) {
+	rendered, err := s.emailService.Preview(name, locale)
+	if errors.Is(err, email.ErrUnknownTemplate) {
+		return nil, ErrEmailTemplateNotFound
+	}
+	if err != nil {
+		return nil, apperror.Internal(err)
+	}
+	return rendered, nil
+}
+
+// VerifyUnsubscribeToken implements Service.
+// Mengembalikan user dan topic di token. Dipakai halaman konfirmasi (GET) yang tidak
+// boleh mengubah apa pun, dan audit log untuk mencari pemilik token
+func (s *service) VerifyUnsubscribeToken(token string) (uint, string, error) {
+	return parseUnsubscribeToken(s.unsubscribeSecret(), token)
+}
+
+// Unsubscribe implements Service.
+// Mematikan channel email untuk tipe di token (channel lain tidak berubah),
+// atau mematikan digest untuk token digest
+func (s *service) Unsubscribe(token string) (string, error) {
+	userID, topic, err := parseUnsubscribeToken(s.unsubscribeSecret(), token)
+	if err != nil {
+		return "", err
+	}
+	if topic == digestTopic {
+		settings, err := s.settings(userID)
+		if err != nil {
+			return "", err
+		}
+		settings.Digest = DigestOff
+		if err := s.repo.SaveSettings(settings); err != nil {
+			return "", apperror.Internal(err)
+		}
+		return topic, nil
+	}
+
+	notifType, _ := ParseNotifType(topic) // sudah divalidasi parseUnsubscribeToken
+	channels, err := s.channels(userID)
+	if err != nil {
+		return "", err
+	}
+	current := channels[notifType]
+	current.Email = false
+	if err := s.repo.SavePreferences([]NotificationPreference{newPreference(userID, notifType, current)}); err != nil {
+		return "", apperror.Internal(err)
+	}
+	return topic, nil
+}
+
+// SendDeferredEmails implements Service.
+// Dipanggil scheduler. Preferensi dicek ulang, jadi email yang tipenya sudah
+// di-unsubscribe selama quiet hours tidak terkirim
+func (s *service) SendDeferredEmails(now time.Time) error {
+	emails, err := s.repo.FindDueDeferredEmails(now, deferredEmailBatch)
+	if err != nil {
+		return err
+	}
+	for _, e := range emails {
+		channels, err := s.channels(e.UserID)
+		if err != nil {
+			return err
+		}
+		if channels[e.Type].Email {
+			mailer := s.emailService.WithUnsubscribe(s.unsubscribeURL(e.UserID, string(e.Type)))
+			locale, userTimezone := s.userRegion(e.UserID)
+			if err := s.sendEmail(mailer, locale, userTimezone, e.EventID, e.EventTitle, e.Type, e.Message, e.ToEmail, e.ToName); err != nil {
+				log.Printf("Failed to send deferred %s email to %s: %v", e.Type, e.ToEmail, err)
+			}
+		}
+		if err := s.repo.DeleteDeferredEmail(e.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func NewService(repo Repository, eventRepo event.Repository, participantRepo participant.Repository, userRepo user.Repository, emailService email.Service, hub Hub, cfg *config.Config) Service {
+	retentionDays, err := strconv.Atoi(cfg.NotificationRetentionDays)
+	if err != nil || retentionDays < 0 {
+		log.Printf("notification: invalid NOTIFICATION_RETENTION_DAYS %q, using %d", cfg.NotificationRetentionDays, defaultRetentionDays)
+		retentionDays = defaultRetentionDays
+	}
 	return &service{
-		repo:         repo,
-		eventRepo:    eventRepo,
-		emailService: emailService,
-		cfg:          cfg,
+		repo:            repo,
+		eventRepo:       eventRepo,
+		participantRepo: participantRepo,
+		userRepo:        userRepo,
+		emailService:    emailService,
+		hub:             hub,
+		cfg:             cfg,
+		retention:       time.Duration(retentionDays) * 24 * time.Hour,
 	}
 }
@@ -0,0 +1,120 @@
+package notification
+
+import (
+	"go-event/internal/event"
+	"go-event/internal/eventbus"
+	"go-event/pkg/i18n"
+	"go-event/pkg/timezone"
+	"log"
+	"time"
+)
+
+// OnEventCancelled mengabari semua participant bahwa event dibatalkan (subscriber EventCancelled).
+// Event yang dihapus sudah tidak ada di database, judul diambil dari snapshot
+func (s *service) OnEventCancelled(e eventbus.EventCancelled) error {
+	title := e.Event.Title
+	return s.notifyParticipants(e.Event.ID, e.Event.Timezone, title, NotifCancellation, func(locale i18n.Locale, _ *time.Location) string {
+		return i18n.T(locale, "notification.event_cancelled", title)
+	})
+}
+
+// OnParticipantReviewed mengabari pendaftar hasil approval (subscriber ParticipantReviewed)
+func (s *service) OnParticipantReviewed(e eventbus.ParticipantReviewed) error {
+	eventData, err := s.eventRepo.GetByID(e.EventID)
+	if err != nil {
+		return err
+	}
+	p := e.Participant
+	locale, _ := s.userRegion(p.UserID)
+	notifType := NotifApproval
+	message := i18n.T(locale, "notification.registration_approved", eventData.Title)
+	if !e.Approved {
+		notifType = NotifRejection
+		message = i18n.T(locale, "notification.registration_rejected", eventData.Title)
+		if p.OrderID != nil {
+			message += " " + i18n.T(locale, "notification.payment_refunded")
+		}
+	}
+	if p.ReviewMessage != "" {
+		message += "\n\n" + i18n.T(locale, "notification.organizer_message", p.ReviewMessage)
+	}
+	return s.SendNotificationWithEmail(p.UserID, eventData.ID, notifType, message, p.UserEmail, p.UserName)
+}
+
+// NotifyEventUpdate implements Service.
+// Dipanggil scheduler saat jendela debounce notifikasi update selesai
+func (s *service) NotifyEventUpdate(eventData *event.Event, changes []event.FieldChange) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	return s.notifyParticipants(eventData.ID, eventData.Timezone, eventData.Title, NotifUpdate, func(locale i18n.Locale, loc *time.Location) string {
+		updateMessage := i18n.T(locale, "notification.event_updated") + "\n"
+		for _, change := range changes {
+			updateMessage += "- " + change.Render(locale, loc) + "\n"
+		}
+		return updateMessage
+	})
+}
+
+// messageKey membedakan pesan notifyParticipants per bahasa dan timezone penerima
+type messageKey struct {
+	locale i18n.Locale
+	loc    *time.Location
+}
+
+// notifyParticipants mengirim notifikasi + email ke semua participant aktif event.
+// Pesan ditulis dalam bahasa dan timezone masing-masing participant (timezone event jika
+// belum dipilih); guest memakai bahasa participant-nya dan timezone event. eventTitle
+// dipakai email jika event sudah dihapus saat email dikirim
+func (s *service) notifyParticipants(eventID uint, eventTimezone, eventTitle string, notifType NotifType, render func(locale i18n.Locale, loc *time.Location) string) error {
+	participants, err := s.participantRepo.FindByEventID(eventID)
+	if err != nil {
+		return err
+	}
+
+	messages := make(map[messageKey]string)
+	message := func(locale i18n.Locale, loc *time.Location) string {
+		key := messageKey{locale: locale, loc: loc}
+		msg, ok := messages[key]
+		if !ok {
+			msg = render(locale, loc)
+			messages[key] = msg
+		}
+		return msg
+	}
+	eventLoc := timezone.Load(eventTimezone)
+	for _, p := range participants {
+		// Pendaftar yang ditolak / sudah membatalkan tidak terkait lagi dengan event
+		if !p.Status.IsActive() {
+			continue
+		}
+		userInfo, err := s.userRepo.GetByID(p.UserID)
+		if err != nil {
+			log.Printf("Failed to get user %d: %v", p.UserID, err)
+			continue
+		}
+		locale := i18n.Resolve(userInfo.Locale)
+		userMessage := message(locale, timezone.Resolve(userInfo.Timezone, eventTimezone))
+		req := &CreateNotificationRequest{
+			UserID:     p.UserID,
+			EventID:    &eventID,
+			Type:       string(notifType),
+			Message:    userMessage,
+			EventTitle: eventTitle,
+		}
+		if _, err := s.CreateNotificationWithEmail(req, userInfo.Email, userInfo.Name); err != nil {
+			log.Printf("Failed to send %s notification to user %d: %v", notifType, p.UserID, err)
+		}
+		// Guest dari pendaftar pending belum menerima tiket
+		if !p.Status.IsConfirmed() {
+			continue
+		}
+		guestMessage := message(locale, eventLoc)
+		for _, g := range p.Guests {
+			if err := s.sendEmail(s.emailService, locale, "", &eventID, eventTitle, notifType, guestMessage, g.Email, g.Name); err != nil {
+				log.Printf("Failed to send %s email to guest %d: %v", notifType, g.ID, err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package notification
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Token unsubscribe: base64url("<user_id>:<topic>") + "." + base64url(HMAC-SHA256).
+// Topic adalah tipe notifikasi atau digestTopic.
+// Tidak punya masa berlaku agar link di email lama tetap bisa dipakai
+
+// digestTopic adalah topic token untuk mematikan email digest
+const digestTopic = "digest"
+
+// signUnsubscribeToken membuat token untuk mematikan email satu topic
+func signUnsubscribeToken(secret string, userID uint, topic string) string {
+	payload := fmt.Sprintf("%d:%s", userID, topic)
+	return base64.RawURLEncoding.EncodeToString([]byte(payload)) + "." +
+		base64.RawURLEncoding.EncodeToString(unsubscribeMAC(secret, payload))
+}
+
+// parseUnsubscribeToken memverifikasi signature dan membaca isi token
+func parseUnsubscribeToken(secret, token string) (uint, string, error) {
+	encodedPayload, encodedMAC, ok := strings.Cut(token, ".")
+	if !ok {
+		return 0, "", ErrInvalidUnsubscribeToken
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return 0, "", ErrInvalidUnsubscribeToken
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(encodedMAC)
+	if err != nil || !hmac.Equal(mac, unsubscribeMAC(secret, string(payload))) {
+		return 0, "", ErrInvalidUnsubscribeToken
+	}
+
+	rawID, topic, ok := strings.Cut(string(payload), ":")
+	if !ok {
+		return 0, "", ErrInvalidUnsubscribeToken
+	}
+	userID, err := strconv.ParseUint(rawID, 10, 32)
+	if err != nil || userID == 0 {
+		return 0, "", ErrInvalidUnsubscribeToken
+	}
+	if _, ok := ParseNotifType(topic); !ok && topic != digestTopic {
+		return 0, "", ErrInvalidUnsubscribeToken
+	}
+	return uint(userID), topic, nil
+}
+
+func unsubscribeMAC(secret, payload string) []byte {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte("unsubscribe:" + payload))
+	return h.Sum(nil)
+}
+
+// unsubscribeSecret memakai UNSUBSCRIBE_SECRET, fallback ke JWT secret
+func (s *service) unsubscribeSecret() string {
+	if s.cfg.UnsubscribeSecret != "" {
+		return s.cfg.UnsubscribeSecret
+	}
+	return s.cfg.JWTSecret
+}
+
+// unsubscribeURL adalah link one-click unsubscribe untuk email notifikasi atau digest
+func (s *service) unsubscribeURL(userID uint, topic string) string {
+	token := signUnsubscribeToken(s.unsubscribeSecret(), userID, topic)
+	return strings.TrimRight(s.cfg.AppBaseURL, "/") + "/api/notification/unsubscribe?token=" + url.QueryEscape(token)
+}
@@ -1,7 +1,16 @@
 package participant
 
 import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"go-event/pkg/apperror"
 	"go-event/pkg/config"
+	"go-event/pkg/export"
+	"go-event/pkg/pagination"
+	"go-event/pkg/validation"
+	"io"
+	"log"
 	"strconv"
 
 	"github.com/gofiber/fiber/v2"
@@ -9,110 +18,360 @@ import (
 
 type Controller struct {
 	service Service
-	cfg 	 config.Config
+	cfg     config.Config
 }
 
 func NewController(service Service, cfg config.Config) *Controller {
 	return &Controller{
 		service: service,
-		cfg: cfg,
+		cfg:     cfg,
 	}
 }
 
 func (ctrl *Controller) RegisterParticipant(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uint)
-	id := c.Params("id")
 
-	eventID, err := strconv.ParseUint(id, 10, 32)
+	eventID, err := parseEventID(c)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"message": "invalid event ID",
-		})
+		return err
 	}
-	
-	req := RegisterParticipantRequest{
-		EventID: uint(eventID),
-		UserID:  userID,
+
+	// Body opsional: guests untuk group registration dan answers untuk registration form
+	var req RegisterParticipantRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return apperror.ErrInvalidBody
+		}
+	}
+	req.EventID = eventID
+	req.UserID = userID
+	if err := validation.Struct(&req); err != nil {
+		return err
 	}
 
 	participant, err := ctrl.service.RegisterParticipant(&req)
 	if err != nil {
-		statusCode := fiber.StatusBadRequest
-		if err.Error() == "participant already registered" {
-			statusCode = fiber.StatusConflict
-		} else if err.Error() == "event not found" {
-			statusCode = fiber.StatusNotFound
-		} else if err.Error() == "user not found" {
-			statusCode = fiber.StatusNotFound
-		}
-
-		return c.Status(statusCode).JSON(fiber.Map{
-			"message": err.Error(),
-		})
+		return err
 	}
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
 		"message":     "participant registered successfully",
 		"participant": participant,
 	})
-
 }
 
 func (ctrl *Controller) CancelParticipant(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uint)
-	id := c.Params("id")
-	eventID, err := strconv.ParseUint(id, 10, 32)
 
+	eventID, err := parseEventID(c)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"message": "invalid event ID",
-		})
+		return err
 	}
-	err = ctrl.service.CancelParticipant(uint(eventID), userID)
-	if err != nil {
-		statusCode := fiber.StatusBadRequest
-		if err.Error() == "participant not found" {
-			statusCode = fiber.StatusNotFound
-		} else if err.Error() == "event not found" {
-			statusCode = fiber.StatusNotFound
-		} else if err.Error() == "unauthorized to cancel this participant" {
-			statusCode = fiber.StatusUnauthorized
+
+	// Body opsional, hanya berisi alasan pembatalan
+	var req CancelParticipantRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return apperror.ErrInvalidBody
 		}
+	}
+	if err := validation.Struct(&req); err != nil {
+		return err
+	}
 
-		return c.Status(statusCode).JSON(fiber.Map{
-			"message": err.Error(),
-		})
+	if err := ctrl.service.CancelParticipant(eventID, userID, &req); err != nil {
+		return err
 	}
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"message": "participant cancelled successfully",
 	})
 }
 
+// registrationID mencari ID pendaftaran user yang login di event :id, dipakai sebagai
+// target audit pembatalan. 0 jika tidak ditemukan
+func (ctrl *Controller) registrationID(c *fiber.Ctx) uint {
+	userID, _ := c.Locals("userID").(uint)
+	eventID, err := parseEventID(c)
+	if err != nil {
+		return 0
+	}
+	id, err := ctrl.service.GetRegistrationID(eventID, userID)
+	if err != nil {
+		return 0
+	}
+	return id
+}
+
 func (ctrl *Controller) GetParticipant(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
 	userRole := c.Locals("userRole").(string)
-	id := c.Params("id")
-	eventID, err := strconv.ParseUint(id, 10, 32)
 
+	eventID, err := parseEventID(c)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"message": "invalid event ID",
-		})
+		return err
 	}
-	
+
 	if userRole != "admin" && userRole != "organizer" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"message": "unauthorized to view participants",
-		})
+		return ErrViewForbidden
 	}
 
-	participants, err := ctrl.service.GetParticipantsByEventID(uint(eventID))
+	var page pagination.Params
+	if err := c.QueryParser(&page); err != nil {
+		return apperror.ErrInvalidParam
+	}
+	if err := validation.Struct(&page); err != nil {
+		return err
+	}
+	var query ListQuery
+	if err := c.QueryParser(&query); err != nil {
+		return apperror.ErrInvalidParam
+	}
+	if err := validation.Struct(&query); err != nil {
+		return err
+	}
+
+	result, err := ctrl.service.ListParticipants(userID, userRole, eventID, &query, page)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"message": err.Error(),
-		})
+		return err
 	}
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"message":      "participants retrieved successfully",
-		"participants": participants,
+		"participants": result.Participants,
+		"pagination":   result.Pagination,
+		"counts":       result.Counts,
+	})
+}
+
+// GetMyUpcoming - pendaftaran aktif user untuk event yang akan datang
+func (ctrl *Controller) GetMyUpcoming(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	registrations, err := ctrl.service.GetMyUpcoming(userID)
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message":       "upcoming registrations retrieved successfully",
+		"registrations": registrations,
+	})
+}
+
+// GetMyHistory - riwayat pendaftaran user (event lewat, dibatalkan, ditolak)
+func (ctrl *Controller) GetMyHistory(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	registrations, err := ctrl.service.GetMyHistory(userID)
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message":       "registration history retrieved successfully",
+		"registrations": registrations,
+	})
+}
+
+// ExportParticipants - download participant event sebagai CSV/XLSX (streaming)
+func (ctrl *Controller) ExportParticipants(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	userRole := c.Locals("userRole").(string)
+
+	eventID, err := parseEventID(c)
+	if err != nil {
+		return err
+	}
+	var query ExportQuery
+	if err := c.QueryParser(&query); err != nil {
+		return apperror.ErrInvalidParam
+	}
+
+	exp, err := ctrl.service.PrepareExport(userID, userRole, eventID, &query)
+	if err != nil {
+		return err
+	}
+
+	c.Set(fiber.HeaderContentType, exp.Format.ContentType())
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, exp.Filename))
+	// Baris ditulis langsung ke koneksi per batch. Error di tengah stream hanya bisa
+	// di-log karena header sudah terkirim
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		writer, err := export.NewWriter(exp.Format, w)
+		if err != nil {
+			log.Printf("participant export event %d: %v", eventID, err)
+			return
+		}
+		if err := exp.WriteTo(writer); err != nil {
+			log.Printf("participant export event %d: %v", eventID, err)
+		}
+		if err := writer.Close(); err != nil {
+			log.Printf("participant export event %d: %v", eventID, err)
+		}
+		if err := w.Flush(); err != nil {
+			log.Printf("participant export event %d: %v", eventID, err)
+		}
+	})
+	return nil
+}
+
+// ImportParticipants - import participant dari CSV (multipart field `file` atau body text/csv)
+func (ctrl *Controller) ImportParticipants(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	userRole := c.Locals("userRole").(string)
+
+	eventID, err := parseEventID(c)
+	if err != nil {
+		return err
+	}
+	var opts ImportOptions
+	if err := c.QueryParser(&opts); err != nil {
+		return apperror.ErrInvalidParam
+	}
+
+	var file io.Reader = bytes.NewReader(c.Body())
+	if fh, err := c.FormFile("file"); err == nil {
+		f, err := fh.Open()
+		if err != nil {
+			return apperror.ErrInvalidBody
+		}
+		defer f.Close()
+		file = f
+	}
+
+	report, err := ctrl.service.ImportParticipants(userID, userRole, eventID, file, &opts)
+	if err != nil {
+		return err
+	}
+	message := "participants imported"
+	if opts.DryRun {
+		message = "import validated (dry run), nothing was saved"
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": message,
+		"report":  report,
+	})
+}
+
+// CheckInParticipant - tandai participant hadir
+func (ctrl *Controller) CheckInParticipant(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	userRole := c.Locals("userRole").(string)
+
+	eventID, err := parseEventID(c)
+	if err != nil {
+		return err
+	}
+	participantID, err := parseParticipantID(c)
+	if err != nil {
+		return err
+	}
+
+	participant, err := ctrl.service.CheckInParticipant(userID, userRole, eventID, participantID)
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message":     "participant checked in successfully",
+		"participant": participant,
 	})
 }
 
+// ApproveParticipants - approve pendaftar pending secara bulk
+func (ctrl *Controller) ApproveParticipants(c *fiber.Ctx) error {
+	return ctrl.reviewParticipants(c, true)
+}
+
+// RejectParticipants - tolak pendaftar pending secara bulk
+func (ctrl *Controller) RejectParticipants(c *fiber.Ctx) error {
+	return ctrl.reviewParticipants(c, false)
+}
+
+// ApproveParticipant - approve satu pendaftar (path :participantId)
+func (ctrl *Controller) ApproveParticipant(c *fiber.Ctx) error {
+	return ctrl.reviewParticipant(c, true)
+}
+
+// RejectParticipant - tolak satu pendaftar (path :participantId)
+func (ctrl *Controller) RejectParticipant(c *fiber.Ctx) error {
+	return ctrl.reviewParticipant(c, false)
+}
+
+func (ctrl *Controller) reviewParticipants(c *fiber.Ctx, approve bool) error {
+	var req ReviewRequest
+	if err := c.BodyParser(&req); err != nil {
+		return apperror.ErrInvalidBody
+	}
+	if err := validation.Struct(&req); err != nil {
+		return err
+	}
+
+	result, err := ctrl.review(c, &req, approve)
+	if err != nil {
+		return err
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "participants reviewed successfully",
+		"result":  result,
+	})
+}
+
+// reviewParticipant memakai alur bulk dengan satu ID, kegagalan dikembalikan sebagai error biasa
+func (ctrl *Controller) reviewParticipant(c *fiber.Ctx, approve bool) error {
+	participantID, err := parseParticipantID(c)
+	if err != nil {
+		return err
+	}
+
+	// Body opsional, hanya berisi message
+	var req ReviewRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return apperror.ErrInvalidBody
+		}
+	}
+	req.ParticipantIDs = []uint{participantID}
+	if err := validation.Struct(&req); err != nil {
+		return err
+	}
+
+	result, err := ctrl.review(c, &req, approve)
+	if err != nil {
+		return err
+	}
+	if len(result.Failed) > 0 {
+		return result.Failed[0].err
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message":     "participant reviewed successfully",
+		"participant": result.Reviewed[0],
+	})
+}
+
+func (ctrl *Controller) review(c *fiber.Ctx, req *ReviewRequest, approve bool) (*ReviewResult, error) {
+	userID := c.Locals("userID").(uint)
+	userRole := c.Locals("userRole").(string)
+
+	eventID, err := parseEventID(c)
+	if err != nil {
+		return nil, err
+	}
+	if approve {
+		return ctrl.service.ApproveParticipants(userID, userRole, eventID, req)
+	}
+	return ctrl.service.RejectParticipants(userID, userRole, eventID, req)
+}
+
+// parseParticipantID membaca path param :participantId
+func parseParticipantID(c *fiber.Ctx) (uint, error) {
+	participantID, err := strconv.ParseUint(c.Params("participantId"), 10, 32)
+	if err != nil {
+		return 0, apperror.InvalidParam("participant ID")
+	}
+	return uint(participantID), nil
+}
+
+// parseEventID membaca path param :id
+func parseEventID(c *fiber.Ctx) (uint, error) {
+	eventID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return 0, apperror.InvalidParam("event ID")
+	}
+	return uint(eventID), nil
+}
@@ -0,0 +1,18 @@
+package participant
+
+import "go-event/pkg/apperror"
+
+var (
+	ErrParticipantNotFound  = apperror.New(apperror.KindNotFound, "PARTICIPANT_NOT_FOUND", "participant not found")
+	ErrEventNotFound        = apperror.New(apperror.KindNotFound, "EVENT_NOT_FOUND", "event not found")
+	ErrRegistrationClosed   = apperror.New(apperror.KindConflict, "REGISTRATION_CLOSED", "event is not open for registration")
+	ErrAlreadyRegistered    = apperror.New(apperror.KindConflict, "PARTICIPANT_ALREADY_REGISTERED", "user already registered for this event")
+	ErrRegistrationRejected = apperror.New(apperror.KindConflict, "PARTICIPANT_REJECTED", "registration for this event was rejected by the organizer")
+	ErrNotCancellable       = apperror.New(apperror.KindConflict, "PARTICIPANT_NOT_CANCELLABLE", "registration can no longer be cancelled")
+	ErrTicketRequired       = apperror.New(apperror.KindConflict, "TICKET_REQUIRED", "this event requires a ticket, create an order instead")
+	ErrEventFull            = apperror.New(apperror.KindConflict, "EVENT_FULL", "event has reached its capacity")
+	ErrNotPendingApproval   = apperror.New(apperror.KindConflict, "PARTICIPANT_NOT_PENDING", "participant is not waiting for approval")
+	ErrNotCheckInable       = apperror.New(apperror.KindConflict, "PARTICIPANT_NOT_CHECKINABLE", "only registered participants can be checked in")
+	ErrManageForbidden      = apperror.New(apperror.KindForbidden, "PARTICIPANT_MANAGE_FORBIDDEN", "only the event organizer can manage participants")
+	ErrViewForbidden        = apperror.New(apperror.KindForbidden, "PARTICIPANT_VIEW_FORBIDDEN", "unauthorized to view participants")
+)
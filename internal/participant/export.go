@@ -0,0 +1,152 @@
+package participant
+
+import (
+	"fmt"
+	"go-event/pkg/export"
+	"go-event/pkg/regform"
+	"go-event/pkg/validation"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// exportBatchSize adalah jumlah participant yang dibaca per query saat export
+const exportBatchSize = 500
+
+// exportColumn adalah satu kolom file export
+type exportColumn struct {
+	key    string
+	header string
+	value  func(p *Participant) string
+}
+
+// baseExportColumns urutan default kolom export, jawaban registration form ditambahkan setelahnya
+var baseExportColumns = []exportColumn{
+	{"id", "ID", func(p *Participant) string { return strconv.FormatUint(uint64(p.ID), 10) }},
+	{"name", "Name", func(p *Participant) string { return p.User.Name }},
+	{"email", "Email", func(p *Participant) string { return p.User.Email }},
+	{"status", "Status", func(p *Participant) string { return string(p.Status) }},
+	{"registered_at", "Registered At", func(p *Participant) string { return formatTime(&p.CreatedAt) }},
+	{"checked_in_at", "Checked In At", func(p *Participant) string { return formatTime(p.CheckedInAt) }},
+	{"cancelled_at", "Cancelled At", func(p *Participant) string { return formatTime(p.CancelledAt) }},
+	{"cancel_reason", "Cancel Reason", func(p *Participant) string { return p.CancelReason }},
+	{"guests", "Guests", func(p *Participant) string { return strconv.Itoa(len(p.Guests)) }},
+}
+
+// ParticipantExport adalah export yang sudah divalidasi dan siap di-stream
+type ParticipantExport struct {
+	Filename string
+	Format   export.Format
+
+	repo     Repository
+	eventID  uint
+	statuses []StatusType
+	columns  []exportColumn
+}
+
+// WriteTo menulis header lalu participant per batch ke w. Close tetap tanggung jawab pemanggil
+func (e *ParticipantExport) WriteTo(w export.Writer) error {
+	header := make([]string, len(e.columns))
+	for i, col := range e.columns {
+		header[i] = col.header
+	}
+	if err := w.WriteRow(header); err != nil {
+		return err
+	}
+
+	return e.repo.EachByEventID(e.eventID, e.statuses, exportBatchSize, func(batch []Participant) error {
+		for i := range batch {
+			row := make([]string, len(e.columns))
+			for j, col := range e.columns {
+				row[j] = col.value(&batch[i])
+			}
+			if err := w.WriteRow(row); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// exportColumns memilih kolom dari query `columns` (dipisah koma). Kosong = semua kolom,
+// `answers` = semua jawaban, `answers.<key>` = satu jawaban
+func exportColumns(selected string, questions []regform.Question) ([]exportColumn, error) {
+	answerColumns := make([]exportColumn, 0, len(questions))
+	for _, q := range questions {
+		key := q.Key
+		answerColumns = append(answerColumns, exportColumn{
+			key:    "answers." + key,
+			header: q.Label,
+			value:  func(p *Participant) string { return regform.FormatAnswer(p.Answers[key]) },
+		})
+	}
+	all := append(append([]exportColumn{}, baseExportColumns...), answerColumns...)
+	if strings.TrimSpace(selected) == "" {
+		return all, nil
+	}
+
+	byKey := make(map[string]exportColumn, len(all))
+	for _, col := range all {
+		byKey[col.key] = col
+	}
+	var columns []exportColumn
+	seen := make(map[string]bool)
+	add := func(col exportColumn) {
+		if !seen[col.key] {
+			seen[col.key] = true
+			columns = append(columns, col)
+		}
+	}
+	for _, key := range splitList(selected) {
+		if key == "answers" {
+			for _, col := range answerColumns {
+				add(col)
+			}
+			continue
+		}
+		col, ok := byKey[key]
+		if !ok {
+			return nil, validation.NewError("columns", "oneof", key, fmt.Sprintf("contains unknown column: %s", key))
+		}
+		add(col)
+	}
+	return columns, nil
+}
+
+// parseStatuses membaca filter `status` (dipisah koma), kosong = semua status.
+// Dipakai juga oleh listing participant
+func parseStatuses(selected string) ([]StatusType, error) {
+	var statuses []StatusType
+	for _, s := range splitList(selected) {
+		status := StatusType(s)
+		valid := false
+		for _, k := range AllStatuses {
+			if status == k {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return nil, validation.NewError("status", "oneof", s, fmt.Sprintf("contains unknown status: %s", s))
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.ToLower(strings.TrimSpace(item)); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,68 @@
+package participant
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"go-event/internal/notification/email"
+	"log"
+	"strings"
+	"time"
+)
+
+// NewGuests membuat record Guest dengan ticket code unik untuk setiap attendee tambahan
+func NewGuests(eventID uint, reqs []GuestRequest) ([]Guest, error) {
+	guests := make([]Guest, 0, len(reqs))
+	for _, r := range reqs {
+		code, err := newTicketCode()
+		if err != nil {
+			return nil, err
+		}
+		guests = append(guests, Guest{
+			EventID:    eventID,
+			Name:       strings.TrimSpace(r.Name),
+			Email:      strings.ToLower(strings.TrimSpace(r.Email)),
+			TicketCode: code,
+			CreatedAt:  time.Now(),
+		})
+	}
+	return guests, nil
+}
+
+// newTicketCode menghasilkan kode tiket acak, contoh: G-3F9A1C2B7E
+func newTicketCode() (string, error) {
+	b := make([]byte, 5)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate ticket code: %w", err)
+	}
+	return "G-" + strings.ToUpper(hex.EncodeToString(b)), nil
+}
+
+// SendGuestTickets mengirim email tiket ke setiap guest (dipanggil async oleh pemanggil)
+func SendGuestTickets(emailService email.Service, guests []Guest, eventTitle, eventDate, eventLocation, registeredBy string) {
+	for _, g := range guests {
+		if err := emailService.SendGuestTicketEmail(g.Email, g.Name, eventTitle, eventDate, eventLocation, g.TicketCode, registeredBy); err != nil {
+			log.Printf("Failed to send guest ticket email to %s: %v", g.Email, err)
+		}
+	}
+}
+
+func (g *Guest) ToResponse() GuestResponse {
+	return GuestResponse{
+		ID:         g.ID,
+		Name:       g.Name,
+		Email:      g.Email,
+		TicketCode: g.TicketCode,
+	}
+}
+
+func guestResponses(guests []Guest) []GuestResponse {
+	if len(guests) == 0 {
+		return nil
+	}
+	responses := make([]GuestResponse, 0, len(guests))
+	for i := range guests {
+		responses = append(responses, guests[i].ToResponse())
+	}
+	return responses
+}
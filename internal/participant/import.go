@@ -0,0 +1,192 @@
+package participant
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"go-event/internal/user"
+	"go-event/pkg/apperror"
+	"go-event/pkg/validation"
+	"io"
+	"log"
+	"net/mail"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// MaxImportRows adalah jumlah baris data maksimal per file import
+const MaxImportRows = 1000
+
+// ImportOptions adalah query param import participant
+type ImportOptions struct {
+	DryRun      bool `query:"dry_run"`
+	CreateUsers bool `query:"create_users"`
+}
+
+type ImportRowStatus string
+
+const (
+	ImportRegistered    ImportRowStatus = "registered"
+	ImportWouldRegister ImportRowStatus = "would_register" // dry run
+	ImportFailed        ImportRowStatus = "failed"
+)
+
+// ImportRowResult adalah hasil satu baris CSV. Row adalah nomor baris di file (header = 1)
+type ImportRowResult struct {
+	Row           int             `json:"row"`
+	Email         string          `json:"email"`
+	Status        ImportRowStatus `json:"status"`
+	UserCreated   bool            `json:"user_created"`
+	ParticipantID uint            `json:"participant_id,omitempty"`
+	Code          string          `json:"code,omitempty"`
+	Message       string          `json:"message,omitempty"`
+	Details       interface{}     `json:"details,omitempty"`
+}
+
+type ImportReport struct {
+	DryRun       bool              `json:"dry_run"`
+	Total        int               `json:"total"`
+	Succeeded    int               `json:"succeeded"`
+	Failed       int               `json:"failed"`
+	UsersCreated int               `json:"users_created"`
+	Rows         []ImportRowResult `json:"rows"`
+}
+
+var (
+	ErrImportUserNotFound = apperror.New(apperror.KindNotFound, "USER_NOT_FOUND", "no user with this email, enable create_users to invite")
+	ErrImportDuplicateRow = apperror.New(apperror.KindConflict, "DUPLICATE_ROW", "email already appears in an earlier row")
+)
+
+// importRow adalah satu baris CSV yang sudah dipetakan ke kolom
+type importRow struct {
+	line    int
+	email   string
+	name    string
+	answers map[string]string
+}
+
+// parseImportCSV membaca seluruh file (maksimal MaxImportRows baris). Kolom wajib `email`,
+// kolom `name` opsional, kolom lain dicocokkan dengan key registration form
+func parseImportCSV(r io.Reader) ([]importRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, validation.NewError("file", "required", "", "is empty")
+	}
+	if err != nil {
+		return nil, validation.NewError("file", "csv", "", fmt.Sprintf("is not a valid CSV: %v", err))
+	}
+	columns := make([]string, len(header))
+	emailCol := -1
+	for i, h := range header {
+		columns[i] = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))
+		if columns[i] == "email" {
+			emailCol = i
+		}
+	}
+	if emailCol < 0 {
+		return nil, validation.NewError("file", "required", "email", "must have an email column")
+	}
+
+	var rows []importRow
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, validation.NewError("file", "csv", "", fmt.Sprintf("is not a valid CSV: %v", err))
+		}
+		if isEmptyRecord(record) {
+			continue
+		}
+		if len(rows) == MaxImportRows {
+			return nil, validation.NewError("file", "max", fmt.Sprint(MaxImportRows), fmt.Sprintf("must have at most %d rows", MaxImportRows))
+		}
+
+		row := importRow{line: line, answers: map[string]string{}}
+		for i, value := range record {
+			if i >= len(columns) {
+				break
+			}
+			switch columns[i] {
+			case "email":
+				row.email = strings.ToLower(strings.TrimSpace(value))
+			case "name":
+				row.name = strings.TrimSpace(value)
+			default:
+				row.answers[columns[i]] = value
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func isEmptyRecord(record []string) bool {
+	for _, v := range record {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// validateImportRow mengecek format email dan panjang nama sebelum menyentuh database
+func validateImportRow(row importRow) error {
+	if row.email == "" {
+		return validation.NewError("email", "required", "", "is required")
+	}
+	if addr, err := mail.ParseAddress(row.email); err != nil || addr.Address != row.email || len(row.email) > 191 {
+		return validation.NewError("email", "email", "", "must be a valid email address")
+	}
+	if len(row.name) > 100 {
+		return validation.NewError("name", "max", "100", "must be at most 100 characters")
+	}
+	return nil
+}
+
+// placeholderUser membuat akun invited untuk email yang belum terdaftar
+func placeholderUser(row importRow) *user.User {
+	name := row.name
+	if name == "" {
+		name = row.email[:strings.Index(row.email, "@")]
+	}
+	return &user.User{
+		Name:    name,
+		Email:   row.email,
+		Role:    user.RoleParticipant,
+		Invited: true,
+	}
+}
+
+// findUserByEmail mengembalikan nil jika email belum terdaftar
+func (s *service) findUserByEmail(email string) (*user.User, error) {
+	u, err := s.userRepo.FindByEmail(email)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return u, err
+}
+
+// importRowError mengubah error menjadi kode + pesan untuk laporan per baris
+func importRowError(result *ImportRowResult, err error) {
+	result.Status = ImportFailed
+	var verrs validation.Errors
+	if errors.As(err, &verrs) {
+		result.Code, result.Message, result.Details = "VALIDATION_FAILED", "validation failed", verrs
+		return
+	}
+	appErr, ok := apperror.As(err)
+	if !ok {
+		appErr = apperror.Internal(err)
+	}
+	if appErr.Kind == apperror.KindInternal {
+		log.Printf("participant import row %d (%s): %v", result.Row, result.Email, err)
+	}
+	result.Code, result.Message, result.Details = appErr.Code, appErr.Message, appErr.Details
+}
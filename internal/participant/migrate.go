@@ -0,0 +1,69 @@
+package participant
+
+import (
+	"log"
+
+	"gorm.io/gorm"
+)
+
+// uniqueEventUserIndex adalah unique index (event_id, user_id) di Participant
+const uniqueEventUserIndex = "idx_participants_event_user"
+
+// DedupeBeforeMigrate menghapus baris participant ganda per (event_id, user_id) agar
+// AutoMigrate bisa membuat unique index. Per pasangan yang dipertahankan adalah baris
+// berstatus aktif terbaru, atau baris terbaru jika tidak ada yang aktif. Guest milik
+// baris yang dihapus ikut dihapus. Tidak melakukan apa-apa jika index sudah ada
+func DedupeBeforeMigrate(db *gorm.DB) error {
+	migrator := db.Migrator()
+	if !migrator.HasTable(&Participant{}) || migrator.HasIndex(&Participant{}, uniqueEventUserIndex) {
+		return nil
+	}
+
+	var pairs []struct {
+		EventID uint
+		UserID  uint
+	}
+	err := db.Model(&Participant{}).
+		Select("event_id, user_id").
+		Group("event_id, user_id").
+		Having("COUNT(*) > 1").
+		Scan(&pairs).Error
+	if err != nil || len(pairs) == 0 {
+		return err
+	}
+
+	removed := 0
+	for _, pair := range pairs {
+		var rows []Participant
+		if err := db.Where("event_id = ? AND user_id = ?", pair.EventID, pair.UserID).
+			Order("id desc").Find(&rows).Error; err != nil {
+			return err
+		}
+		keep := rows[0].ID
+		for _, row := range rows {
+			if row.Status.IsActive() {
+				keep = row.ID
+				break
+			}
+		}
+		ids := make([]uint, 0, len(rows)-1)
+		for _, row := range rows {
+			if row.ID != keep {
+				ids = append(ids, row.ID)
+			}
+		}
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Where("participant_id IN ?", ids).Delete(&Guest{}).Error; err != nil {
+				return err
+			}
+			return tx.Where("id IN ?", ids).Delete(&Participant{}).Error
+		})
+		if err != nil {
+			return err
+		}
+		removed += len(ids)
+	}
+	log.Printf("participant: removed %d duplicate rows for %d (event_id, user_id) pairs", removed, len(pairs))
+	return nil
+}
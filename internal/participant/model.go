@@ -1,41 +1,243 @@
 package participant
 
 import (
+	"go-event/internal/eventbus"
 	"go-event/internal/user"
+	"go-event/pkg/pagination"
+	"go-event/pkg/regform"
 	"time"
 )
 
 type StatusType string
 
 const (
-	StatusRegistered  StatusType = "registered"
-	StatusAttended  	StatusType = "attended"
-	StatusCancelled 	StatusType = "cancelled"
+	StatusRegistered StatusType = "registered"
+	StatusAttended   StatusType = "attended"
+	StatusCancelled  StatusType = "cancelled"
+	// Status untuk event dengan approval mode
+	StatusPendingApproval StatusType = "pending_approval"
+	StatusRejected        StatusType = "rejected"
 )
 
+// AllStatuses dipakai untuk validasi filter dan counts per status
+var AllStatuses = []StatusType{StatusRegistered, StatusAttended, StatusCancelled, StatusPendingApproval, StatusRejected}
+
+// ConfirmedStatuses adalah status participant yang dihitung ke capacity dan menerima reminder
+var ConfirmedStatuses = []StatusType{StatusRegistered, StatusAttended}
+
+// ActiveStatuses adalah status pendaftaran yang masih berlaku (lihat IsActive)
+var ActiveStatuses = []StatusType{StatusRegistered, StatusAttended, StatusPendingApproval}
+
+// IsActive mengecek apakah pendaftaran masih berlaku (termasuk yang menunggu approval).
+// Pendaftaran cancelled boleh didaftarkan ulang
+func (s StatusType) IsActive() bool {
+	return s.IsConfirmed() || s == StatusPendingApproval
+}
+
+// IsConfirmed mengecek apakah participant sudah terdaftar (bukan pending/rejected/cancelled)
+func (s StatusType) IsConfirmed() bool {
+	for _, confirmed := range ConfirmedStatuses {
+		if s == confirmed {
+			return true
+		}
+	}
+	return false
+}
+
 // 🧱 Entity (database model)
 type Participant struct {
-	ID        uint      	`json:"id" gorm:"primaryKey"`
-	EventID   uint      	`json:"event_id"`
-	UserID    uint      	`json:"user_id"`
-	Status    StatusType	`json:"status"`
-	CreatedAt time.Time 	`json:"created_at"`
+	ID uint `json:"id" gorm:"primaryKey"`
+	// Index (event_id, status) dan (event_id, created_at) untuk listing per event.
+	// Unique (event_id, user_id): satu user hanya punya satu baris per event, re-registrasi
+	// setelah cancel memakai baris yang sama
+	EventID uint            `json:"event_id" gorm:"uniqueIndex:idx_participants_event_user,priority:1;index:idx_participants_event_status,priority:1;index:idx_participants_event_created,priority:1"`
+	UserID  uint            `json:"user_id" gorm:"uniqueIndex:idx_participants_event_user,priority:2;index"`
+	Status  StatusType      `json:"status" gorm:"size:32;index:idx_participants_event_status,priority:2"`
+	OrderID *uint           `json:"order_id" gorm:"index"`                    // nil untuk event gratis tanpa ticket
+	Answers regform.Answers `json:"answers" gorm:"serializer:json;type:text"` // jawaban registration form event
+	// Diisi saat organizer approve/reject pendaftaran
+	ReviewedAt    *time.Time `json:"reviewed_at"`
+	ReviewMessage string     `json:"review_message" gorm:"size:1000"`
+	// Pembatalan tidak menghapus baris agar organizer bisa melihat churn
+	CancelledAt  *time.Time `json:"cancelled_at"`
+	CancelReason string     `json:"cancel_reason" gorm:"size:500"`
+	CheckedInAt  *time.Time `json:"checked_in_at"` // diisi saat status menjadi attended
+	CreatedAt    time.Time  `json:"created_at" gorm:"index:idx_participants_event_created,priority:2"`
 
 	// Removed direct references to avoid import cycle
 	// Event event.Event `json:"event" gorm:"foreignKey:EventID"`
-	User  user.User   `json:"user" gorm:"foreignKey:UserID"`
+	User   user.User `json:"user" gorm:"foreignKey:UserID"`
+	Guests []Guest   `json:"guests,omitempty" gorm:"foreignKey:ParticipantID"`
+}
+
+// MaxGroupSize adalah jumlah attendee maksimal per pendaftaran, termasuk pendaftar
+const MaxGroupSize = 10
+
+// Guest adalah attendee tambahan dari group registration. Guest tidak punya akun,
+// tiket & reminder dikirim ke email-nya
+type Guest struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	ParticipantID uint      `json:"participant_id" gorm:"index"`
+	EventID       uint      `json:"event_id" gorm:"index"`
+	Name          string    `json:"name" gorm:"size:100"`
+	Email         string    `json:"email" gorm:"size:255"`
+	TicketCode    string    `json:"ticket_code" gorm:"size:32;uniqueIndex"`
+	CreatedAt     time.Time `json:"created_at"`
 }
 
 // 📩 Request structs
 type RegisterParticipantRequest struct {
-	EventID uint `json:"event_id" validate:"required"`
-	UserID  uint `json:"user_id" validate:"required"`
+	EventID uint            `json:"event_id" validate:"required"`
+	UserID  uint            `json:"user_id" validate:"required"`
+	Guests  []GuestRequest  `json:"guests" validate:"max=9,dive"` // MaxGroupSize - 1
+	Answers regform.Answers `json:"answers"`
+}
+
+type CancelParticipantRequest struct {
+	Reason string `json:"reason" validate:"omitempty,max=500"`
+}
+
+// ListQuery adalah filter & sort listing participant. Status dipisah koma,
+// search mencari di nama atau email user. Sort dengan prefix - untuk descending
+type ListQuery struct {
+	Status string `json:"status" query:"status"`
+	Search string `json:"search" query:"search" validate:"omitempty,max=100"`
+	Sort   string `json:"sort" query:"sort" validate:"omitempty,oneof=created_at -created_at name -name email -email status -status checked_in_at -checked_in_at"`
+}
+
+// ListFilter adalah ListQuery yang sudah di-parse untuk repository
+type ListFilter struct {
+	Statuses []StatusType
+	Search   string
+	Sort     string
+	Offset   int
+	Limit    int
+}
+
+// ExportQuery adalah query param export participant. Columns dan Status dipisah koma
+type ExportQuery struct {
+	Format  string `query:"format"`
+	Columns string `query:"columns"`
+	Status  string `query:"status"`
+}
+
+// ReviewRequest dipakai untuk approve/reject. ParticipantIDs diisi dari path untuk
+// review satu participant
+type ReviewRequest struct {
+	ParticipantIDs []uint `json:"participant_ids" validate:"required,min=1,max=100,dive,gt=0"`
+	Message        string `json:"message" validate:"omitempty,max=1000"`
+}
+
+type GuestRequest struct {
+	Name  string `json:"name" validate:"required,notblank,max=100"`
+	Email string `json:"email" validate:"required,email,max=255"`
 }
 
 // 📤 Response structs
 type ParticipantResponse struct {
-	ID      uint              `json:"id"`
-	Status  string            `json:"status"`
-	User    user.UserResponse `json:"user"`
-	EventID uint              `json:"event_id"`
+	ID            uint              `json:"id"`
+	Status        string            `json:"status"`
+	User          user.UserResponse `json:"user"`
+	EventID       uint              `json:"event_id"`
+	OrderID       *uint             `json:"order_id,omitempty"`
+	Guests        []GuestResponse   `json:"guests,omitempty"`
+	Answers       regform.Answers   `json:"answers,omitempty"`
+	ReviewedAt    *time.Time        `json:"reviewed_at,omitempty"`
+	ReviewMessage string            `json:"review_message,omitempty"`
+	CancelledAt   *time.Time        `json:"cancelled_at,omitempty"`
+	CancelReason  string            `json:"cancel_reason,omitempty"`
+	CheckedInAt   *time.Time        `json:"checked_in_at,omitempty"`
+	CreatedAt     time.Time         `json:"created_at"`
+}
+
+// ParticipantPage adalah satu halaman listing participant. Counts berisi jumlah per
+// status untuk seluruh event (tidak terpengaruh filter) ditambah "total"
+type ParticipantPage struct {
+	Participants []ParticipantResponse `json:"participants"`
+	Pagination   pagination.Meta       `json:"pagination"`
+	Counts       map[string]int64      `json:"counts"`
+}
+
+// MyRegistrationResponse adalah pendaftaran milik user beserta ringkasan event-nya
+type MyRegistrationResponse struct {
+	ID           uint            `json:"id"`
+	Status       string          `json:"status"`
+	OrderID      *uint           `json:"order_id,omitempty"`
+	Guests       []GuestResponse `json:"guests,omitempty"`
+	CancelledAt  *time.Time      `json:"cancelled_at,omitempty"`
+	CancelReason string          `json:"cancel_reason,omitempty"`
+	CreatedAt    time.Time       `json:"created_at"`
+	Event        MyEventSummary  `json:"event"`
+}
+
+type MyEventSummary struct {
+	ID        uint      `json:"id"`
+	Title     string    `json:"title"`
+	Location  string    `json:"location"`
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+	Status    string    `json:"status"`
+}
+
+// Snapshot adalah data participant untuk domain event. User harus sudah dimuat
+func (p *Participant) Snapshot() eventbus.ParticipantSnapshot {
+	guests := make([]eventbus.GuestSnapshot, 0, len(p.Guests))
+	for _, g := range p.Guests {
+		guests = append(guests, eventbus.GuestSnapshot{ID: g.ID, Name: g.Name, Email: g.Email})
+	}
+	return eventbus.ParticipantSnapshot{
+		ID:            p.ID,
+		EventID:       p.EventID,
+		UserID:        p.UserID,
+		UserName:      p.User.Name,
+		UserEmail:     p.User.Email,
+		Status:        string(p.Status),
+		OrderID:       p.OrderID,
+		Guests:        guests,
+		Answers:       p.Answers,
+		ReviewMessage: p.ReviewMessage,
+		CancelReason:  p.CancelReason,
+		CheckedInAt:   p.CheckedInAt,
+		CreatedAt:     p.CreatedAt,
+	}
+}
+
+func (p *Participant) ToResponse() ParticipantResponse {
+	return ParticipantResponse{
+		ID:            p.ID,
+		Status:        string(p.Status),
+		User:          *p.User.ToResponse(),
+		EventID:       p.EventID,
+		OrderID:       p.OrderID,
+		Guests:        guestResponses(p.Guests),
+		Answers:       p.Answers,
+		ReviewedAt:    p.ReviewedAt,
+		ReviewMessage: p.ReviewMessage,
+		CancelledAt:   p.CancelledAt,
+		CancelReason:  p.CancelReason,
+		CheckedInAt:   p.CheckedInAt,
+		CreatedAt:     p.CreatedAt,
+	}
+}
+
+// ReviewResult adalah hasil approve/reject bulk. Participant yang gagal tidak
+// membatalkan participant lain
+type ReviewResult struct {
+	Reviewed []ParticipantResponse `json:"reviewed"`
+	Failed   []ReviewFailure       `json:"failed"`
+}
+
+type ReviewFailure struct {
+	ParticipantID uint   `json:"participant_id"`
+	Code          string `json:"code"`
+	Message       string `json:"message"`
+
+	err error // error asli, dikembalikan apa adanya untuk review satu participant
+}
+
+type GuestResponse struct {
+	ID         uint   `json:"id"`
+	Name       string `json:"name"`
+	Email      string `json:"email"`
+	TicketCode string `json:"ticket_code"`
 }
@@ -2,24 +2,106 @@ package participant
 
 import (
 	"errors"
+	"go-event/internal/user"
+	"strings"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type Repository interface {
 	Register(participant *Participant) error
+	CreateUser(u *user.User) error
 	FindByEventAndUser(eventID uint, userID uint) (*Participant, error)
 	FindByEventID(eventID uint) ([]Participant, error)
-	Delete(participant *Participant) error
+	ListByEventID(eventID uint, filter ListFilter) ([]Participant, int64, error)
+	CountByStatus(eventID uint) (map[StatusType]int64, error)
+	FindByUserID(userID uint) ([]Participant, error)
+	EachByEventID(eventID uint, statuses []StatusType, batchSize int, fn func(batch []Participant) error) error
+	FindByID(id uint) (*Participant, error)
+	FindConfirmedByEventID(eventID uint) ([]Participant, error)
+	CountConfirmedSeats(eventID uint) (int64, error)
+	CountActiveByEventID(eventID uint) (int64, error)
+	UpdateStatus(participant *Participant) error
+	WithEventLock(eventID uint, fn func(repo Repository) error) error
 }
 
 type repository struct {
 	db *gorm.DB
 }
 
-// Delete implements Repository.
-func (r *repository) Delete(participant *Participant) error {
-	return r.db.Delete(participant).Error
+// FindByID implements Repository.
+func (r *repository) FindByID(id uint) (*Participant, error) {
+	var participant Participant
+	err := r.db.Preload("User").Preload("Guests").Where("id = ?", id).First(&participant).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &participant, err
+}
+
+// FindConfirmedByEventID implements Repository.
+// Dipakai untuk reminder: pendaftar yang masih pending atau ditolak tidak ikut
+func (r *repository) FindConfirmedByEventID(eventID uint) ([]Participant, error) {
+	var participants []Participant
+	err := r.db.Preload("User").Preload("Guests").
+		Where("event_id = ? AND status IN ?", eventID, ConfirmedStatuses).
+		Find(&participants).Error
+	return participants, err
+}
+
+// CountConfirmedSeats implements Repository.
+// Jumlah kursi terpakai = participant terkonfirmasi + guest mereka
+func (r *repository) CountConfirmedSeats(eventID uint) (int64, error) {
+	var participants, guests int64
+	if err := r.db.Model(&Participant{}).
+		Where("event_id = ? AND status IN ?", eventID, ConfirmedStatuses).
+		Count(&participants).Error; err != nil {
+		return 0, err
+	}
+	if err := r.db.Model(&Guest{}).
+		Joins("JOIN participants ON participants.id = guests.participant_id").
+		Where("guests.event_id = ? AND participants.status IN ?", eventID, ConfirmedStatuses).
+		Count(&guests).Error; err != nil {
+		return 0, err
+	}
+	return participants + guests, nil
+}
+
+// CountActiveByEventID implements Repository.
+// Jumlah pendaftaran yang masih berlaku (lihat StatusType.IsActive), tanpa guest
+func (r *repository) CountActiveByEventID(eventID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&Participant{}).
+		Where("event_id = ? AND status IN ?", eventID, ActiveStatuses).
+		Count(&count).Error
+	return count, err
+}
+
+// UpdateStatus implements Repository.
+func (r *repository) UpdateStatus(participant *Participant) error {
+	return r.db.Model(&Participant{}).Where("id = ?", participant.ID).Updates(map[string]interface{}{
+		"status":         participant.Status,
+		"reviewed_at":    participant.ReviewedAt,
+		"review_message": participant.ReviewMessage,
+		"cancelled_at":   participant.CancelledAt,
+		"cancel_reason":  participant.CancelReason,
+		"checked_in_at":  participant.CheckedInAt,
+	}).Error
+}
+
+// WithEventLock implements Repository.
+// Mengunci baris event (SELECT ... FOR UPDATE) selama fn berjalan agar cek capacity
+// + simpan participant tidak bisa melebihi capacity saat request paralel
+func (r *repository) WithEventLock(eventID uint, fn func(repo Repository) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var locked struct{ ID uint }
+		if err := tx.Table("events").Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id").Where("id = ?", eventID).Take(&locked).Error; err != nil {
+			return err
+		}
+		return fn(&repository{db: tx})
+	})
 }
 
 // FindByEventAndUser implements Repository.
@@ -32,16 +114,121 @@ func (r *repository) FindByEventAndUser(eventID uint, userID uint) (*Participant
 	return &participant, err
 }
 
+// listSortColumns memetakan nilai sort dari query ke kolom SQL (allowlist)
+var listSortColumns = map[string]string{
+	"created_at":    "participants.created_at",
+	"name":          "users.name",
+	"email":         "users.email",
+	"status":        "participants.status",
+	"checked_in_at": "participants.checked_in_at",
+}
+
+// ListByEventID implements Repository.
+// Filter dan sort dijalankan di database (join ke users untuk nama/email), hanya
+// satu halaman yang di-preload
+func (r *repository) ListByEventID(eventID uint, filter ListFilter) ([]Participant, int64, error) {
+	query := r.db.Model(&Participant{}).
+		Joins("JOIN users ON users.id = participants.user_id").
+		Where("participants.event_id = ?", eventID)
+	if len(filter.Statuses) > 0 {
+		query = query.Where("participants.status IN ?", filter.Statuses)
+	}
+	if filter.Search != "" {
+		like := "%" + escapeLike(filter.Search) + "%"
+		query = query.Where("users.name LIKE ? OR users.email LIKE ?", like, like)
+	}
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	sort, desc := strings.TrimPrefix(filter.Sort, "-"), strings.HasPrefix(filter.Sort, "-")
+	column, ok := listSortColumns[sort]
+	if !ok {
+		column = listSortColumns["created_at"]
+	}
+	var participants []Participant
+	err := query.Session(&gorm.Session{}).
+		Preload("User").Preload("Guests").
+		Order(clause.OrderByColumn{Column: clause.Column{Name: column, Raw: true}, Desc: desc}).
+		Order("participants.id").
+		Offset(filter.Offset).Limit(filter.Limit).
+		Find(&participants).Error
+	return participants, total, err
+}
+
+// CountByStatus implements Repository.
+func (r *repository) CountByStatus(eventID uint) (map[StatusType]int64, error) {
+	var rows []struct {
+		Status StatusType
+		Count  int64
+	}
+	err := r.db.Model(&Participant{}).
+		Select("status, COUNT(*) AS count").
+		Where("event_id = ?", eventID).
+		Group("status").
+		Scan(&rows).Error
+	counts := make(map[StatusType]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+	return counts, err
+}
+
+// escapeLike meng-escape wildcard LIKE agar input search dicari apa adanya
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
 // FindByEventID implements Repository.
 func (r *repository) FindByEventID(eventID uint) ([]Participant, error) {
 	var participants []Participant
-	err := r.db.Preload("User").Where("event_id = ?", eventID).Find(&participants).Error
+	err := r.db.Preload("User").Preload("Guests").Where("event_id = ?", eventID).Find(&participants).Error
+	return participants, err
+}
+
+// EachByEventID implements Repository.
+// Membaca participant per batch (urut ID) untuk export, jadi seluruh daftar tidak
+// pernah dimuat sekaligus. statuses kosong = semua status
+func (r *repository) EachByEventID(eventID uint, statuses []StatusType, batchSize int, fn func(batch []Participant) error) error {
+	query := r.db.Preload("User").Preload("Guests").Where("event_id = ?", eventID)
+	if len(statuses) > 0 {
+		query = query.Where("status IN ?", statuses)
+	}
+	var batch []Participant
+	return query.FindInBatches(&batch, batchSize, func(tx *gorm.DB, _ int) error {
+		return fn(batch)
+	}).Error
+}
+
+// FindByUserID implements Repository.
+func (r *repository) FindByUserID(userID uint) ([]Participant, error) {
+	var participants []Participant
+	err := r.db.Preload("Guests").Where("user_id = ?", userID).Find(&participants).Error
 	return participants, err
 }
 
 // Register implements Repository.
+// Guests di participant.Guests ikut dibuat oleh GORM (association).
+// Participant dengan ID adalah re-registrasi setelah cancel: baris lama dipakai
+// ulang dan guest lama diganti
 func (r *repository) Register(participant *Participant) error {
-	return r.db.Create(participant).Error
+	if participant.ID == 0 {
+		return r.db.Create(participant).Error
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("participant_id = ?", participant.ID).Delete(&Guest{}).Error; err != nil {
+			return err
+		}
+		return tx.Save(participant).Error
+	})
+}
+
+// CreateUser implements Repository.
+// Membuat akun di transaksi yang sama dengan pendaftaran (akun placeholder dari import)
+func (r *repository) CreateUser(u *user.User) error {
+	return user.NewRepository(r.db).Create(u)
 }
 
 func Newrepository(db *gorm.DB) Repository {
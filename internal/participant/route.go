@@ -1,16 +1,32 @@
 package participant
 
 import (
+	"go-event/internal/audit"
 	"go-event/pkg/config"
+	"go-event/pkg/idempotency"
 	"go-event/pkg/middlewares"
 
 	"github.com/gofiber/fiber/v2"
 )
 
-func SetupParticipantRoute(app *fiber.App, ctrl *Controller, cfg *config.Config) {
+func SetupParticipantRoute(app *fiber.App, ctrl *Controller, cfg *config.Config, idempotencyStore idempotency.Repository, auditLog *audit.Recorder) {
 	PR := app.Group("/api/participant/")
 
-	PR.Post(":id", middlewares.Authenticate(cfg), ctrl.RegisterParticipant)
-	PR.Delete(":id",middlewares.Authenticate(cfg), ctrl.CancelParticipant)
-	PR.Get(":id",middlewares.Authenticate(cfg),  ctrl.GetParticipant)
-}
\ No newline at end of file
+	// Self-service, didaftarkan sebelum :id
+	PR.Get("my/upcoming", middlewares.Authenticate(cfg), ctrl.GetMyUpcoming)
+	PR.Get("my/history", middlewares.Authenticate(cfg), ctrl.GetMyHistory)
+
+	// Idempotency-Key opsional: retry dari client tidak membuat pendaftaran ganda
+	PR.Post(":id", middlewares.Authenticate(cfg), middlewares.Idempotency(idempotencyStore), auditLog.Record("participant.register", audit.Created("participant", "participant.id")), ctrl.RegisterParticipant)
+	PR.Delete(":id", middlewares.Authenticate(cfg), auditLog.Record("participant.cancel", audit.Lookup("participant", ctrl.registrationID)), ctrl.CancelParticipant)
+	PR.Get(":id", middlewares.Authenticate(cfg), ctrl.GetParticipant)
+
+	// Approval mode: review pendaftar oleh organizer pemilik event (atau admin)
+	PR.Post(":id/approve", middlewares.Authenticate(cfg), middlewares.Authorize("organizer", "admin"), auditLog.Record("participant.approve", audit.BulkFrom("participant", "result.reviewed.id")), ctrl.ApproveParticipants)
+	PR.Post(":id/reject", middlewares.Authenticate(cfg), middlewares.Authorize("organizer", "admin"), auditLog.Record("participant.reject", audit.BulkFrom("participant", "result.reviewed.id")), ctrl.RejectParticipants)
+	PR.Post(":id/:participantId/approve", middlewares.Authenticate(cfg), middlewares.Authorize("organizer", "admin"), auditLog.Record("participant.approve", audit.Param("participant", "participantId")), ctrl.ApproveParticipant)
+	PR.Post(":id/import", middlewares.Authenticate(cfg), middlewares.Authorize("organizer", "admin"), auditLog.Record("participant.import", audit.BulkFrom("participant", "report.rows.participant_id")), ctrl.ImportParticipants)
+	PR.Get(":id/export", middlewares.Authenticate(cfg), middlewares.Authorize("organizer", "admin"), ctrl.ExportParticipants)
+	PR.Post(":id/:participantId/check-in", middlewares.Authenticate(cfg), middlewares.Authorize("organizer", "admin"), auditLog.Record("participant.check_in", audit.Param("participant", "participantId")), ctrl.CheckInParticipant)
+	PR.Post(":id/:participantId/reject", middlewares.Authenticate(cfg), middlewares.Authorize("organizer", "admin"), auditLog.Record("participant.reject", audit.Param("participant", "participantId")), ctrl.RejectParticipant)
+}
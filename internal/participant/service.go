@@ -2,129 +2,751 @@ package participant
 
 import (
 	"errors"
+	"fmt"
+	"go-event/internal/event"
+	"go-event/internal/eventbus"
 	"go-event/internal/notification/email"
 	"go-event/internal/user"
+	"go-event/pkg/apperror"
 	"go-event/pkg/config"
+	"go-event/pkg/export"
+	"go-event/pkg/i18n"
+	"go-event/pkg/pagination"
+	"go-event/pkg/regform"
+	"go-event/pkg/timezone"
+	"go-event/pkg/validation"
+	"io"
 	"log"
+	"sort"
+	"strings"
 	"time"
+
+	"gorm.io/gorm"
 )
 
 type Service interface {
 	RegisterParticipant(req *RegisterParticipantRequest) (*ParticipantResponse, error)
-	CancelParticipant(eventID uint, userID uint) error
-	GetParticipantsByEventID(eventID uint) ([]ParticipantResponse, error)
+	CancelParticipant(eventID uint, userID uint, req *CancelParticipantRequest) error
+	GetRegistrationID(eventID, userID uint) (uint, error)
+	GetMyUpcoming(userID uint) ([]MyRegistrationResponse, error)
+	GetMyHistory(userID uint) ([]MyRegistrationResponse, error)
+	ListParticipants(requesterID uint, requesterRole string, eventID uint, query *ListQuery, page pagination.Params) (*ParticipantPage, error)
+	ApproveParticipants(reviewerID uint, reviewerRole string, eventID uint, req *ReviewRequest) (*ReviewResult, error)
+	RejectParticipants(reviewerID uint, reviewerRole string, eventID uint, req *ReviewRequest) (*ReviewResult, error)
+	CheckInParticipant(requesterID uint, requesterRole string, eventID, participantID uint) (*ParticipantResponse, error)
+	PrepareExport(requesterID uint, requesterRole string, eventID uint, query *ExportQuery) (*ParticipantExport, error)
+	ImportParticipants(requesterID uint, requesterRole string, eventID uint, file io.Reader, opts *ImportOptions) (*ImportReport, error)
 }
 
 type service struct {
 	repo         Repository
 	cfg          *config.Config
-	eventRepo    EventRepository
+	eventRepo    event.Repository
 	userRepo     user.Repository
 	emailService email.Service
+	tickets      TicketGateway
+	bus          eventbus.Publisher
 }
 
 // CancelParticipant implements Service.
-func (s *service) CancelParticipant(eventID uint, userID uint) error {
+// Pendaftaran tidak dihapus, hanya ditandai cancelled agar organizer bisa melihat
+// churn dan user bisa mendaftar ulang
+func (s *service) CancelParticipant(eventID uint, userID uint, req *CancelParticipantRequest) error {
 	participant, err := s.repo.FindByEventAndUser(eventID, userID)
 	if err != nil {
-		return err
+		return apperror.Internal(err)
 	}
 	if participant == nil {
-		return errors.New("Participant not found")
+		return ErrParticipantNotFound
+	}
+	// Sudah hadir, sudah dibatalkan, atau ditolak tidak bisa dibatalkan lagi
+	if !participant.Status.IsActive() || participant.Status == StatusAttended {
+		return ErrNotCancellable
+	}
+
+	now := time.Now()
+	participant.Status = StatusCancelled
+	participant.CancelledAt = &now
+	participant.CancelReason = strings.TrimSpace(req.Reason)
+	if err := s.repo.UpdateStatus(participant); err != nil {
+		return apperror.Internal(err)
+	}
+	s.refundOrder(participant)
+	if events, err := s.eventRepo.GetByID(eventID); err == nil {
+		s.publish(eventbus.ParticipantCancelled{Meta: participantMeta(userID, events), Participant: s.snapshot(participant)})
+	}
+	return nil
+}
+
+// GetRegistrationID implements Service.
+// ID pendaftaran user di event (termasuk yang sudah dibatalkan), 0 jika belum pernah mendaftar
+func (s *service) GetRegistrationID(eventID, userID uint) (uint, error) {
+	participant, err := s.repo.FindByEventAndUser(eventID, userID)
+	if err != nil || participant == nil {
+		return 0, err
+	}
+	return participant.ID, nil
+}
+
+// GetMyUpcoming implements Service.
+// Pendaftaran aktif (registered/pending) untuk event yang belum selesai, urut dari yang terdekat
+func (s *service) GetMyUpcoming(userID uint) ([]MyRegistrationResponse, error) {
+	registrations, err := s.myRegistrations(userID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	upcoming := []MyRegistrationResponse{}
+	for _, r := range registrations {
+		if isUpcoming(r, now) {
+			upcoming = append(upcoming, r)
+		}
 	}
-	return s.repo.Delete(participant)
+	sort.Slice(upcoming, func(i, j int) bool {
+		return upcoming[i].Event.StartTime.Before(upcoming[j].Event.StartTime)
+	})
+	return upcoming, nil
 }
 
-// GetParticipantsByEventID implements Service.
-func (s *service) GetParticipantsByEventID(eventID uint) ([]ParticipantResponse, error) {
-	participants, err := s.repo.FindByEventID(eventID)
+// GetMyHistory implements Service.
+// Semua pendaftaran yang tidak termasuk upcoming: event yang sudah lewat beserta
+// status kehadirannya, juga pendaftaran yang dibatalkan/ditolak. Urut dari yang terbaru
+func (s *service) GetMyHistory(userID uint) ([]MyRegistrationResponse, error) {
+	registrations, err := s.myRegistrations(userID)
 	if err != nil {
 		return nil, err
 	}
+	now := time.Now()
+	history := []MyRegistrationResponse{}
+	for _, r := range registrations {
+		if !isUpcoming(r, now) {
+			history = append(history, r)
+		}
+	}
+	sort.Slice(history, func(i, j int) bool {
+		return history[i].Event.StartTime.After(history[j].Event.StartTime)
+	})
+	return history, nil
+}
+
+// myRegistrations mengambil semua pendaftaran user beserta ringkasan event-nya
+func (s *service) myRegistrations(userID uint) ([]MyRegistrationResponse, error) {
+	participants, err := s.repo.FindByUserID(userID)
+	if err != nil {
+		return nil, apperror.Internal(err)
+	}
+	eventIDs := make([]uint, 0, len(participants))
+	for _, p := range participants {
+		eventIDs = append(eventIDs, p.EventID)
+	}
+	events, err := s.eventRepo.GetByIDs(eventIDs)
+	if err != nil {
+		return nil, apperror.Internal(err)
+	}
+	eventsByID := make(map[uint]*event.Event, len(events))
+	for _, e := range events {
+		eventsByID[e.ID] = e
+	}
 
-	var response []ParticipantResponse
+	registrations := make([]MyRegistrationResponse, 0, len(participants))
 	for _, p := range participants {
-		response = append(response, ParticipantResponse{
-			ID: p.ID,
-			Status: string(p.Status),
-			EventID: p.EventID,
-			User: user.UserResponse{
-				ID:    p.User.ID,
-				Name:  p.User.Name,
-				Email: p.User.Email,
-				Role:  string(p.User.Role),
+		e, ok := eventsByID[p.EventID]
+		if !ok {
+			// Event sudah dihapus organizer
+			continue
+		}
+		registrations = append(registrations, MyRegistrationResponse{
+			ID:           p.ID,
+			Status:       string(p.Status),
+			OrderID:      p.OrderID,
+			Guests:       guestResponses(p.Guests),
+			CancelledAt:  p.CancelledAt,
+			CancelReason: p.CancelReason,
+			CreatedAt:    p.CreatedAt,
+			Event: MyEventSummary{
+				ID:        e.ID,
+				Title:     e.Title,
+				Location:  e.Location,
+				StartTime: e.StartTime,
+				EndTime:   e.EndTime,
+				Status:    string(e.Status),
 			},
 		})
 	}
-	return response,  nil
+	return registrations, nil
 }
 
+// isUpcoming true untuk pendaftaran aktif pada event yang belum selesai / dibatalkan
+func isUpcoming(r MyRegistrationResponse, now time.Time) bool {
+	if !StatusType(r.Status).IsActive() {
+		return false
+	}
+	if r.Event.Status == string(event.StatusCompleted) || r.Event.Status == string(event.StatusCancelled) {
+		return false
+	}
+	return r.Event.EndTime.After(now)
+}
 
+// ListParticipants implements Service.
+func (s *service) ListParticipants(requesterID uint, requesterRole string, eventID uint, query *ListQuery, page pagination.Params) (*ParticipantPage, error) {
+	if _, err := s.getManagedEvent(requesterID, requesterRole, eventID); err != nil {
+		return nil, err
+	}
+	statuses, err := parseStatuses(query.Status)
+	if err != nil {
+		return nil, err
+	}
+	page.Normalize()
 
+	participants, total, err := s.repo.ListByEventID(eventID, ListFilter{
+		Statuses: statuses,
+		Search:   strings.TrimSpace(query.Search),
+		Sort:     query.Sort,
+		Offset:   page.Offset(),
+		Limit:    page.Limit(),
+	})
+	if err != nil {
+		return nil, apperror.Internal(err)
+	}
+	byStatus, err := s.repo.CountByStatus(eventID)
+	if err != nil {
+		return nil, apperror.Internal(err)
+	}
+
+	result := &ParticipantPage{
+		Participants: make([]ParticipantResponse, 0, len(participants)),
+		Pagination:   pagination.NewMeta(page, total),
+		Counts:       map[string]int64{"total": 0},
+	}
+	for i := range participants {
+		result.Participants = append(result.Participants, participants[i].ToResponse())
+	}
+	for _, status := range AllStatuses {
+		result.Counts[string(status)] = byStatus[status]
+		result.Counts["total"] += byStatus[status]
+	}
+	return result, nil
+}
 
 // RegisterParticipant implements Service.
 func (s *service) RegisterParticipant(req *RegisterParticipantRequest) (*ParticipantResponse, error) {
-	events, err := s.eventRepo.GetByID(req.EventID)
+	events, err := s.getEvent(req.EventID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkRegistrationOpen(events); err != nil {
+		return nil, err
+	}
+
+	participant, err := s.newRegistration(events, req.UserID, req.Answers, req.Guests)
+	if err != nil {
+		return nil, err
+	}
+	// Event dengan approval mode: pendaftar menunggu review organizer dan belum
+	// menempati kursi, capacity baru dicek saat approve
+	if events.RequiresApproval {
+		participant.Status = StatusPendingApproval
+	}
+	if err := s.saveRegistration(events, participant, nil); err != nil {
+		return nil, err
+	}
+
+	users, err := s.userRepo.GetByID(req.UserID)
+	if err != nil {
+		return nil, apperror.Internal(err)
+	}
+	participant.User = *users
+	s.publish(eventbus.ParticipantRegistered{Meta: participantMeta(req.UserID, events), Participant: participant.Snapshot()})
+
+	// Pendaftar yang masih pending dikabari lewat notifikasi approval/rejection
+	if participant.Status.IsConfirmed() {
+		s.sendConfirmation(events, participant, users)
+	}
+
+	response := &ParticipantResponse{
+		ID:      participant.ID,
+		Status:  string(participant.Status),
+		User:    *users.ToResponse(),
+		EventID: participant.EventID,
+		Guests:  guestResponses(participant.Guests),
+		Answers: participant.Answers,
+	}
+	return response, nil
+
+}
+
+// checkRegistrationOpen memastikan event menerima pendaftaran langsung (tanpa order)
+func (s *service) checkRegistrationOpen(events *event.Event) error {
+	// Pendaftaran hanya dibuka saat event published dan belum dimulai
+	if events.Status != event.StatusPublished || !events.StartTime.After(time.Now()) {
+		return ErrRegistrationClosed
+	}
+	// Event yang punya ticket type hanya bisa didaftari lewat order
+	ticketed, err := s.tickets.HasTicketTypes(events.ID)
 	if err != nil {
-		return nil, errors.New("event not found")
+		return apperror.Internal(err)
+	}
+	if ticketed {
+		return ErrTicketRequired
 	}
-	existing, err := s.repo.FindByEventAndUser(req.EventID, req.UserID)
+	return nil
+}
+
+// newRegistration memvalidasi pendaftaran (duplikat, jawaban form) dan menyiapkan
+// participant berstatus registered tanpa menyimpannya
+func (s *service) newRegistration(events *event.Event, userID uint, rawAnswers regform.Answers, guestReqs []GuestRequest) (*Participant, error) {
+	existing, err := s.repo.FindByEventAndUser(events.ID, userID)
 	if err != nil {
-		return nil, err	}
-	
+		return nil, apperror.Internal(err)
+	}
 	if existing != nil {
-		return nil, errors.New("user already registered for this event")
+		switch {
+		case existing.Status == StatusRejected:
+			return nil, ErrRegistrationRejected
+		case existing.Status.IsActive():
+			return nil, ErrAlreadyRegistered
+		}
+	}
+
+	// Jawaban divalidasi terhadap registration form event
+	answers, err := regform.ValidateAnswers(events.RegistrationForm, rawAnswers)
+	if err != nil {
+		return nil, err
+	}
+
+	guests, err := NewGuests(events.ID, guestReqs)
+	if err != nil {
+		return nil, apperror.Internal(err)
 	}
 
 	participant := &Participant{
-		EventID: 		events.ID,
-		UserID:  		req.UserID,
-		Status: 		StatusRegistered,
-		CreatedAt: 	time.Now(),
+		EventID:   events.ID,
+		UserID:    userID,
+		Status:    StatusRegistered,
+		CreatedAt: time.Now(),
+		Guests:    guests,
+		Answers:   answers,
 	}
-	
-	if err := s.repo.Register(participant); err != nil{
-		return nil, errors.New("failed to register participant: " + err.Error())
+	// Re-registrasi setelah cancel memakai baris yang sama
+	if existing != nil {
+		participant.ID = existing.ID
 	}
+	return participant, nil
+}
 
-	users, err := s.userRepo.GetByID(req.UserID)
+// saveRegistration menyimpan participant, capacity dicek di dalam lock event
+// untuk participant yang langsung terkonfirmasi
+func (s *service) saveRegistration(events *event.Event, participant *Participant, newUser *user.User) error {
+	err := s.repo.WithEventLock(events.ID, func(repo Repository) error {
+		// Akun baru dibuat di transaksi yang sama agar tidak tertinggal jika pendaftaran gagal
+		if newUser != nil {
+			if err := repo.CreateUser(newUser); err != nil {
+				return apperror.Internal(fmt.Errorf("failed to create user: %w", err))
+			}
+			participant.UserID = newUser.ID
+		}
+		// Cek duplikat diulang di dalam lock: request paralel dari user yang sama bisa
+		// sama-sama lolos pengecekan di newRegistration
+		current, err := repo.FindByEventAndUser(events.ID, participant.UserID)
+		if err != nil {
+			return err
+		}
+		if current != nil && (current.ID != participant.ID || current.Status.IsActive()) {
+			return ErrAlreadyRegistered
+		}
+		if participant.Status.IsConfirmed() {
+			if err := CheckCapacity(repo, events, participant); err != nil {
+				return err
+			}
+		}
+		return repo.Register(participant)
+	})
 	if err != nil {
-		return nil, errors.New("failed to get user info")
+		// Unique index (event_id, user_id) sebagai jaminan terakhir
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return ErrAlreadyRegistered
+		}
+		if _, ok := apperror.As(err); ok {
+			return err
+		}
+		return apperror.Internal(fmt.Errorf("failed to register participant: %w", err))
 	}
-	
-	// Kirim email konfirmasi pendaftaran (async, tidak block jika gagal)
+	return nil
+}
+
+// sendConfirmation mengirim email konfirmasi pendaftaran dan tiket guest (async, tidak block jika gagal)
+func (s *service) sendConfirmation(events *event.Event, participant *Participant, users *user.User) {
 	go func() {
-		eventDate := events.StartTime.Format("02 Jan 2006 15:04")
-		if err := s.emailService.SendRegistrationConfirmationEmail(
-			users.Email, 
-			users.Name, 
-			events.Title, 
-			eventDate, 
+		locale := i18n.Resolve(users.Locale)
+		mailer := s.emailService.WithLocale(locale)
+		eventDate := i18n.FormatDateTime(locale, events.StartTime.In(timezone.Resolve(users.Timezone, events.Timezone)))
+		if err := mailer.SendRegistrationConfirmationEmail(
+			users.Email,
+			users.Name,
+			events.Title,
+			eventDate,
 			events.Location,
 		); err != nil {
 			log.Printf("Failed to send registration confirmation email to %s: %v", users.Email, err)
 		}
+		// Guest tidak punya preferensi timezone, waktu ditulis dalam timezone event
+		guestDate := i18n.FormatDateTime(locale, events.StartTime.In(timezone.Load(events.Timezone)))
+		SendGuestTickets(mailer, participant.Guests, events.Title, guestDate, events.Location, users.Name)
 	}()
-	
-	response := &ParticipantResponse{
-		ID:         participant.ID,
-		Status:			string(participant.Status),
-		User: 			*users.ToResponse(),
-		EventID: 		participant.EventID,
+}
+
+// ApproveParticipants implements Service.
+func (s *service) ApproveParticipants(reviewerID uint, reviewerRole string, eventID uint, req *ReviewRequest) (*ReviewResult, error) {
+	return s.reviewParticipants(reviewerID, reviewerRole, eventID, req, true)
+}
+
+// RejectParticipants implements Service.
+// Participant dari order berbayar di-refund sebelum statusnya diubah
+func (s *service) RejectParticipants(reviewerID uint, reviewerRole string, eventID uint, req *ReviewRequest) (*ReviewResult, error) {
+	return s.reviewParticipants(reviewerID, reviewerRole, eventID, req, false)
+}
+
+// reviewParticipants memproses approve/reject satu per satu. Kegagalan satu participant
+// (misalnya capacity penuh) dicatat di Failed tanpa membatalkan yang lain
+func (s *service) reviewParticipants(reviewerID uint, reviewerRole string, eventID uint, req *ReviewRequest, approve bool) (*ReviewResult, error) {
+	events, err := s.getManagedEvent(reviewerID, reviewerRole, eventID)
+	if err != nil {
+		return nil, err
 	}
-	return response, nil
-	
+	// Approve hanya selama event belum dimulai; reject tetap boleh kapan saja
+	if approve && (!events.StartTime.After(time.Now()) ||
+		(events.Status != event.StatusPublished && events.Status != event.StatusRegistrationClosed)) {
+		return nil, ErrRegistrationClosed
+	}
+
+	result := &ReviewResult{Reviewed: []ParticipantResponse{}, Failed: []ReviewFailure{}}
+	seen := make(map[uint]bool, len(req.ParticipantIDs))
+	for _, id := range req.ParticipantIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		participant, err := s.review(events, id, req.Message, approve)
+		if err != nil {
+			appErr, ok := apperror.As(err)
+			if !ok {
+				log.Printf("Failed to review participant %d: %v", id, err)
+				appErr = apperror.Internal(err)
+			}
+			result.Failed = append(result.Failed, ReviewFailure{ParticipantID: id, Code: appErr.Code, Message: appErr.Message, err: appErr})
+			continue
+		}
+		s.notifyReview(reviewerID, events, participant, approve)
+		result.Reviewed = append(result.Reviewed, participant.ToResponse())
+	}
+	return result, nil
+}
+
+// review mengubah status satu participant pending di dalam lock event
+func (s *service) review(events *event.Event, participantID uint, message string, approve bool) (*Participant, error) {
+	var reviewed *Participant
+	err := s.repo.WithEventLock(events.ID, func(repo Repository) error {
+		participant, err := repo.FindByID(participantID)
+		if err != nil {
+			return err
+		}
+		if participant == nil || participant.EventID != events.ID {
+			return ErrParticipantNotFound
+		}
+		if participant.Status != StatusPendingApproval {
+			return ErrNotPendingApproval
+		}
+
+		if approve {
+			if err := CheckCapacity(repo, events, participant); err != nil {
+				return err
+			}
+			participant.Status = StatusRegistered
+		} else {
+			participant.Status = StatusRejected
+		}
+
+		now := time.Now()
+		participant.ReviewedAt = &now
+		participant.ReviewMessage = message
+		if err := repo.UpdateStatus(participant); err != nil {
+			return err
+		}
+		reviewed = participant
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !approve {
+		s.refundOrder(reviewed)
+	}
+	return reviewed, nil
 }
 
-	
+// refundOrder mengembalikan dana order berbayar participant. Dipanggil setelah perubahan
+// status tersimpan; refund yang gagal tercatat refund_pending dan dicoba ulang scheduler
+func (s *service) refundOrder(participant *Participant) {
+	if participant.OrderID == nil {
+		return
+	}
+	if err := s.tickets.RefundOrder(*participant.OrderID); err != nil {
+		log.Printf("Failed to refund order %d for participant %d, will retry: %v", *participant.OrderID, participant.ID, err)
+	}
+}
+
+// notifyReview mengirim hasil review ke event bus, notifikasi ke pendaftar dikirim
+// subscriber ParticipantReviewed. Guest baru menerima tiket setelah approve
+func (s *service) notifyReview(reviewerID uint, events *event.Event, participant *Participant, approve bool) {
+	s.publish(eventbus.ParticipantReviewed{Meta: participantMeta(reviewerID, events), Participant: participant.Snapshot(), Approved: approve})
+	if !approve {
+		return
+	}
+	go func() {
+		u := participant.User
+		locale := i18n.Resolve(u.Locale)
+		eventDate := i18n.FormatDateTime(locale, events.StartTime.In(timezone.Load(events.Timezone)))
+		SendGuestTickets(s.emailService.WithLocale(locale), participant.Guests, events.Title, eventDate, events.Location, u.Name)
+	}()
+}
 
+// CheckInParticipant implements Service.
+// Menandai participant hadir (attended) dan mencatat waktu check-in
+func (s *service) CheckInParticipant(requesterID uint, requesterRole string, eventID, participantID uint) (*ParticipantResponse, error) {
+	events, err := s.getManagedEvent(requesterID, requesterRole, eventID)
+	if err != nil {
+		return nil, err
+	}
+	if events.Status == event.StatusCancelled || events.Status == event.StatusCompleted {
+		return nil, ErrRegistrationClosed
+	}
+
+	participant, err := s.repo.FindByID(participantID)
+	if err != nil {
+		return nil, apperror.Internal(err)
+	}
+	if participant == nil || participant.EventID != eventID {
+		return nil, ErrParticipantNotFound
+	}
+	if participant.Status != StatusRegistered {
+		return nil, ErrNotCheckInable
+	}
+
+	now := time.Now()
+	participant.Status = StatusAttended
+	participant.CheckedInAt = &now
+	if err := s.repo.UpdateStatus(participant); err != nil {
+		return nil, apperror.Internal(err)
+	}
+	s.publish(eventbus.ParticipantCheckedIn{Meta: participantMeta(requesterID, events), Participant: s.snapshot(participant)})
+	response := participant.ToResponse()
+	return &response, nil
+}
+
+// PrepareExport implements Service.
+// Validasi (akses, format, kolom, status) dilakukan di sini sebelum response di-stream,
+// karena setelah streaming dimulai status HTTP tidak bisa diubah lagi
+func (s *service) PrepareExport(requesterID uint, requesterRole string, eventID uint, query *ExportQuery) (*ParticipantExport, error) {
+	events, err := s.getManagedEvent(requesterID, requesterRole, eventID)
+	if err != nil {
+		return nil, err
+	}
+	format, ok := export.ParseFormat(query.Format)
+	if !ok {
+		return nil, validation.NewError("format", "oneof", "csv xlsx", "must be one of: csv, xlsx")
+	}
+	columns, err := exportColumns(query.Columns, events.RegistrationForm)
+	if err != nil {
+		return nil, err
+	}
+	statuses, err := parseStatuses(query.Status)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ParticipantExport{
+		Filename: fmt.Sprintf("event-%d-participants.%s", eventID, format.Extension()),
+		Format:   format,
+		repo:     s.repo,
+		eventID:  eventID,
+		statuses: statuses,
+		columns:  columns,
+	}, nil
+}
+
+// ImportParticipants implements Service.
+// Setiap baris diproses dengan aturan yang sama seperti RegisterParticipant (event harus
+// buka, tanpa ticket, tidak duplikat, jawaban form valid, capacity). Participant hasil
+// import langsung registered walau event memakai approval mode karena ditambahkan
+// oleh organizer sendiri. Baris yang gagal tidak membatalkan baris lain
+func (s *service) ImportParticipants(requesterID uint, requesterRole string, eventID uint, file io.Reader, opts *ImportOptions) (*ImportReport, error) {
+	events, err := s.getManagedEvent(requesterID, requesterRole, eventID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkRegistrationOpen(events); err != nil {
+		return nil, err
+	}
+	rows, err := parseImportCSV(file)
+	if err != nil {
+		return nil, err
+	}
+
+	// Sisa kursi dihitung sekali agar dry run juga memperhitungkan baris sebelumnya.
+	// Saat menulis, capacity tetap dicek ulang di dalam lock oleh saveRegistration
+	// (nil = tanpa batas)
+	var available *int
+	if events.Capacity > 0 {
+		seats, err := s.repo.CountConfirmedSeats(events.ID)
+		if err != nil {
+			return nil, apperror.Internal(err)
+		}
+		remaining := events.Capacity - int(seats)
+		available = &remaining
+	}
+
+	report := &ImportReport{DryRun: opts.DryRun, Total: len(rows), Rows: make([]ImportRowResult, 0, len(rows))}
+	seen := make(map[string]bool, len(rows))
+	for _, row := range rows {
+		result := ImportRowResult{Row: row.line, Email: row.email}
+		if err := s.importRow(requesterID, events, row, opts, seen, available, &result); err != nil {
+			importRowError(&result, err)
+			report.Failed++
+		} else {
+			report.Succeeded++
+			if result.UserCreated {
+				report.UsersCreated++
+			}
+		}
+		report.Rows = append(report.Rows, result)
+	}
+	return report, nil
+}
+
+// importRow memproses satu baris. User placeholder baru dibuat di transaksi yang sama
+// dengan pendaftarannya agar baris yang gagal tidak meninggalkan akun
+func (s *service) importRow(actorID uint, events *event.Event, row importRow, opts *ImportOptions, seen map[string]bool, available *int, result *ImportRowResult) error {
+	if err := validateImportRow(row); err != nil {
+		return err
+	}
+	if seen[row.email] {
+		return ErrImportDuplicateRow
+	}
+	seen[row.email] = true
+
+	u, err := s.findUserByEmail(row.email)
+	if err != nil {
+		return apperror.Internal(err)
+	}
+	var newUser *user.User
+	if u == nil {
+		if !opts.CreateUsers {
+			return ErrImportUserNotFound
+		}
+		newUser = placeholderUser(row)
+		u = newUser
+	}
+
+	answers := regform.ParseTextAnswers(events.RegistrationForm, row.answers)
+	participant, err := s.newRegistration(events, u.ID, answers, nil)
+	if err != nil {
+		return err
+	}
+	if available != nil && *available <= 0 {
+		return ErrEventFull.WithDetails(map[string]int{"available": 0})
+	}
+
+	if opts.DryRun {
+		result.Status = ImportWouldRegister
+	} else {
+		if err := s.saveRegistration(events, participant, newUser); err != nil {
+			return err
+		}
+		result.Status = ImportRegistered
+		result.ParticipantID = participant.ID
+		participant.User = *u
+		s.publish(eventbus.ParticipantRegistered{Meta: participantMeta(actorID, events), Participant: participant.Snapshot()})
+		s.sendConfirmation(events, participant, u)
+	}
+	result.UserCreated = newUser != nil
+	if available != nil {
+		*available--
+	}
+	return nil
+}
+
+// publish mengirim domain event participant ke event bus
+func (s *service) publish(e eventbus.Event) {
+	if err := s.bus.Publish(e); err != nil {
+		log.Printf("Failed to handle %s for event %d: %v", e.Topic(), e.Metadata().EventID, err)
+	}
+}
+
+// snapshot memuat user participant jika belum ada agar payload lengkap
+func (s *service) snapshot(participant *Participant) eventbus.ParticipantSnapshot {
+	if participant.User.ID == 0 {
+		if u, err := s.userRepo.GetByID(participant.UserID); err == nil {
+			participant.User = *u
+		}
+	}
+	return participant.Snapshot()
+}
+
+// participantMeta adalah Meta domain event participant pada event events
+func participantMeta(actorID uint, events *event.Event) eventbus.Meta {
+	return eventbus.NewMeta(actorID, events.ID, events.OrganizerID)
+}
+
+// getEvent mengambil event, hanya record not found yang menjadi 404
+func (s *service) getEvent(eventID uint) (*event.Event, error) {
+	events, err := s.eventRepo.GetByID(eventID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrEventNotFound
+		}
+		return nil, apperror.Internal(err)
+	}
+	return events, nil
+}
+
+// getManagedEvent memastikan event ada dan requester adalah organizer-nya (atau admin)
+func (s *service) getManagedEvent(requesterID uint, requesterRole string, eventID uint) (*event.Event, error) {
+	events, err := s.getEvent(eventID)
+	if err != nil {
+		return nil, err
+	}
+	if requesterRole != "admin" && events.OrganizerID != requesterID {
+		return nil, ErrManageForbidden
+	}
+	return events, nil
+}
+
+// CheckCapacity memastikan participant (beserta guest-nya) masih muat. Harus dipanggil
+// di dalam WithEventLock (juga dipakai ticket saat order lunas)
+func CheckCapacity(repo Repository, events *event.Event, participant *Participant) error {
+	if events.Capacity <= 0 {
+		return nil
+	}
+	seats, err := repo.CountConfirmedSeats(events.ID)
+	if err != nil {
+		return err
+	}
+	needed := 1 + len(participant.Guests)
+	if available := events.Capacity - int(seats); available < needed {
+		return ErrEventFull.WithDetails(map[string]int{"available": available})
+	}
+	return nil
+}
 
-func NewService(repo Repository, eventRepo EventRepository, userRepo user.Repository, emailService email.Service, cfg *config.Config) Service {
+func NewService(repo Repository, eventRepo event.Repository, userRepo user.Repository, emailService email.Service, tickets TicketGateway, bus eventbus.Publisher, cfg *config.Config) Service {
 	return &service{
 		repo:         repo,
 		cfg:          cfg,
 		eventRepo:    eventRepo,
 		userRepo:     userRepo,
 		emailService: emailService,
+		tickets:      tickets,
+		bus:          bus,
 	}
 }
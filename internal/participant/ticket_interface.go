@@ -0,0 +1,11 @@
+package participant
+
+// TicketGateway interface ke package ticket untuk menghindari circular dependency
+// (ticket membuat Participant saat order lunas)
+type TicketGateway interface {
+	// HasTicketTypes true jika event berbayar / memakai ticket, pendaftaran harus lewat order
+	HasTicketTypes(eventID uint) (bool, error)
+	// RefundOrder mengembalikan dana order saat participant membatalkan pendaftaran atau
+	// ditolak. Refund yang gagal dicatat di order dan dicoba ulang oleh package ticket
+	RefundOrder(orderID uint) error
+}
@@ -0,0 +1,90 @@
+package payment
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// FakeDeclineToken membuat FakeProvider menolak charge, untuk testing alur gagal bayar
+const FakeDeclineToken = "tok_decline"
+
+// errFakeUnavailable meniru gangguan gateway, lihat FailRefunds
+var errFakeUnavailable = errors.New("payment: fake provider unavailable")
+
+// FakeProvider adalah Provider in-memory untuk development dan testing.
+// Semua charge berhasil kecuali memakai FakeDeclineToken
+type FakeProvider struct {
+	mu       sync.Mutex
+	seq      int
+	charges  map[string]int64 // chargeID -> amount
+	refunded map[string]int64 // chargeID -> total refund
+	// failRefunds adalah jumlah refund berikutnya yang dibuat gagal
+	failRefunds int
+}
+
+func NewFakeProvider() *FakeProvider {
+	return &FakeProvider{
+		charges:  make(map[string]int64),
+		refunded: make(map[string]int64),
+	}
+}
+
+// Charge implements Provider.
+func (p *FakeProvider) Charge(req ChargeRequest) (*Charge, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.seq++
+	id := fmt.Sprintf("fake_ch_%d_%d", req.OrderID, p.seq)
+	if req.PaymentToken == FakeDeclineToken {
+		return &Charge{ID: id, Status: ChargeFailed, FailureReason: "card declined"}, nil
+	}
+
+	p.charges[id] = req.Amount
+	return &Charge{ID: id, Status: ChargeSucceeded}, nil
+}
+
+// Refund implements Provider.
+func (p *FakeProvider) Refund(chargeID string, amount int64) (*Refund, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.failRefunds > 0 {
+		p.failRefunds--
+		return nil, errFakeUnavailable
+	}
+
+	charged, ok := p.charges[chargeID]
+	if !ok {
+		// Data in-memory hilang saat restart, charge lama buatan fake tetap bisa di-refund
+		if !strings.HasPrefix(chargeID, "fake_ch_") {
+			return nil, ErrChargeNotFound
+		}
+		charged = amount + p.refunded[chargeID]
+		p.charges[chargeID] = charged
+	}
+	if p.refunded[chargeID]+amount > charged {
+		return nil, ErrRefundExceedsCharge
+	}
+
+	p.seq++
+	p.refunded[chargeID] += amount
+	return &Refund{ID: fmt.Sprintf("fake_re_%d", p.seq), ChargeID: chargeID, Amount: amount}, nil
+}
+
+// FailRefunds membuat n refund berikutnya gagal dengan error teknis, untuk testing
+// retry refund
+func (p *FakeProvider) FailRefunds(n int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.failRefunds = n
+}
+
+// Refunded mengembalikan total refund untuk charge
+func (p *FakeProvider) Refunded(chargeID string) int64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.refunded[chargeID]
+}
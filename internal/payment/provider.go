@@ -0,0 +1,50 @@
+// Package payment mendefinisikan kontrak ke payment gateway.
+// Service lain (ticket) hanya bergantung pada interface Provider sehingga
+// gateway asli bisa diganti tanpa mengubah alur order.
+package payment
+
+import "errors"
+
+type ChargeStatus string
+
+const (
+	ChargeSucceeded ChargeStatus = "succeeded"
+	ChargeFailed    ChargeStatus = "failed"
+)
+
+// ChargeRequest berisi data tagihan. Amount dalam satuan terkecil mata uang
+// (contoh: sen untuk USD, rupiah untuk IDR)
+type ChargeRequest struct {
+	OrderID      uint
+	Amount       int64
+	Currency     string
+	Description  string
+	PaymentToken string // token metode pembayaran dari client / gateway
+}
+
+type Charge struct {
+	ID            string
+	Status        ChargeStatus
+	FailureReason string
+}
+
+type Refund struct {
+	ID       string
+	ChargeID string
+	Amount   int64
+}
+
+var (
+	// ErrChargeNotFound dikembalikan saat refund untuk charge yang tidak dikenal
+	ErrChargeNotFound = errors.New("payment: charge not found")
+	// ErrRefundExceedsCharge dikembalikan saat total refund melebihi nilai charge
+	ErrRefundExceedsCharge = errors.New("payment: refund amount exceeds charge")
+)
+
+// Provider adalah payment gateway. Charge yang ditolak (kartu ditolak, saldo kurang)
+// dikembalikan sebagai Charge dengan status failed, bukan error; error hanya untuk
+// kegagalan teknis (jaringan, konfigurasi)
+type Provider interface {
+	Charge(req ChargeRequest) (*Charge, error)
+	Refund(chargeID string, amount int64) (*Refund, error)
+}
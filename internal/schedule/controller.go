@@ -1,7 +1,9 @@
 package schedule
 
 import (
+	"go-event/pkg/apperror"
 	"go-event/pkg/config"
+	"go-event/pkg/validation"
 	"strconv"
 	"strings"
 
@@ -18,51 +20,30 @@ func NewController(service Service, cfg *config.Config) *Controller {
 }
 
 func (ctrl *Controller) CreateSchedule(c *fiber.Ctx) error {
-	id := c.Params("id")
+	userID := c.Locals("userID").(uint)
 
-	eventID, err := strconv.ParseUint(id, 10, 32)
+	eventID, err := parseID(c, "event ID")
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"message": "invalid event ID",
-		})
+		return err
 	}
 
 	var req CreateScheduleRequest
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "invalid request body",
-		})
+		return apperror.ErrInvalidBody
 	}
 
 	// Set event ID dari URL params
-	req.EventID = uint(eventID)
-
-	// Validasi job type
-	validJobTypes := []string{string(JobTypeReminder), string(JobTypeEndEvent)}
-	isValid := false
-	for _, jt := range validJobTypes {
-		if strings.ToLower(string(req.JobType)) == jt {
-			isValid = true
-			break
-		}
-	}
+	req.EventID = eventID
+	// Job type tidak case-sensitive
+	req.JobType = JobType(strings.ToLower(string(req.JobType)))
 
-	if !isValid {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"message": "invalid job type. must be 'reminder' or 'end_event'",
-		})
+	if err := validation.Struct(&req); err != nil {
+		return err
 	}
 
-	schedule, err := ctrl.service.CreateSchedule(&req)
+	schedule, err := ctrl.service.CreateSchedule(userID, &req)
 	if err != nil {
-		statusCode := fiber.StatusBadRequest
-		if err.Error() == "event not found" {
-			statusCode = fiber.StatusNotFound
-		}
-
-		return c.Status(statusCode).JSON(fiber.Map{
-			"message": err.Error(),
-		})
+		return err
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
@@ -71,26 +52,45 @@ func (ctrl *Controller) CreateSchedule(c *fiber.Ctx) error {
 	})
 }
 
+// PreviewTemplate - render template pesan dengan data event sebelum disimpan
+func (ctrl *Controller) PreviewTemplate(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	eventID, err := parseID(c, "event ID")
+	if err != nil {
+		return err
+	}
+
+	var req PreviewTemplateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return apperror.ErrInvalidBody
+	}
+	if err := validation.Struct(&req); err != nil {
+		return err
+	}
+
+	preview, err := ctrl.service.PreviewTemplate(userID, eventID, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "template preview rendered successfully",
+		"preview": preview,
+	})
+}
+
 func (ctrl *Controller) GetSchedules(c *fiber.Ctx) error {
-	id := c.Params("id")
+	userID := c.Locals("userID").(uint)
 
-	eventID, err := strconv.ParseUint(id, 10, 32)
+	eventID, err := parseID(c, "event ID")
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"message": "invalid event ID",
-		})
+		return err
 	}
 
-	schedules, err := ctrl.service.GetSchedulesByEventID(uint(eventID))
+	schedules, err := ctrl.service.GetSchedulesByEventID(userID, eventID)
 	if err != nil {
-		statusCode := fiber.StatusInternalServerError
-		if err.Error() == "event not found" {
-			statusCode = fiber.StatusNotFound
-		}
-
-		return c.Status(statusCode).JSON(fiber.Map{
-			"message": err.Error(),
-		})
+		return err
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
@@ -101,29 +101,14 @@ func (ctrl *Controller) GetSchedules(c *fiber.Ctx) error {
 
 func (ctrl *Controller) DeleteSchedule(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uint)
-	id := c.Params("id")
 
-	scheduleID, err := strconv.ParseUint(id, 10, 32)
+	scheduleID, err := parseID(c, "schedule ID")
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"message": "invalid schedule ID",
-		})
+		return err
 	}
 
-	err = ctrl.service.DeleteSchedule(uint(scheduleID), userID)
-	if err != nil {
-		statusCode := fiber.StatusInternalServerError
-		if err.Error() == "schedule not found" {
-			statusCode = fiber.StatusNotFound
-		} else if err.Error() == "event not found" {
-			statusCode = fiber.StatusNotFound
-		} else if err.Error() == "unauthorized to delete this schedule" {
-			statusCode = fiber.StatusForbidden
-		}
-
-		return c.Status(statusCode).JSON(fiber.Map{
-			"message": err.Error(),
-		})
+	if err := ctrl.service.DeleteSchedule(scheduleID, userID); err != nil {
+		return err
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
@@ -131,3 +116,11 @@ func (ctrl *Controller) DeleteSchedule(c *fiber.Ctx) error {
 	})
 }
 
+// parseID membaca path param :id, name dipakai untuk pesan error
+func parseID(c *fiber.Ctx, name string) (uint, error) {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return 0, apperror.InvalidParam(name)
+	}
+	return uint(id), nil
+}
@@ -0,0 +1,11 @@
+package schedule
+
+import "go-event/pkg/apperror"
+
+var (
+	ErrScheduleNotFound = apperror.New(apperror.KindNotFound, "SCHEDULE_NOT_FOUND", "schedule not found")
+	ErrEventNotFound    = apperror.New(apperror.KindNotFound, "EVENT_NOT_FOUND", "event not found")
+	ErrNotOrganizer     = apperror.New(apperror.KindForbidden, "SCHEDULE_FORBIDDEN", "unauthorized to delete this schedule")
+	ErrBroadcastJob     = apperror.New(apperror.KindConflict, "SCHEDULE_IS_BROADCAST", "broadcast schedules must be cancelled through the broadcast endpoint")
+	ErrEventFinalized   = apperror.New(apperror.KindConflict, "EVENT_FINALIZED", "cannot schedule jobs for a completed or cancelled event")
+)
@@ -11,6 +11,11 @@ type StatusType string
 const (
 	JobTypeReminder JobType = "reminder"
 	JobTypeEndEvent JobType = "end_event"
+	// JobTypeEventUpdate dibuat otomatis saat event diubah, berisi gabungan perubahan
+	// yang dikirim sebagai satu notifikasi setelah jendela debounce
+	JobTypeEventUpdate JobType = "event_update"
+	// JobTypeBroadcast mengirim broadcast organizer yang dijadwalkan (lihat package broadcast)
+	JobTypeBroadcast JobType = "broadcast"
 
 	StatusPending StatusType = "pending"
 	StatusDone    StatusType = "done"
@@ -19,28 +24,59 @@ const (
 
 // 🧱 Entity untuk database
 type ScheduleJob struct {
-	ID        uint       `json:"id" gorm:"primaryKey"`
-	EventID   uint       `json:"event_id"`
-	JobType   JobType    `json:"job_type"`
-	RunAt     time.Time  `json:"run_at"`
-	Status    StatusType `json:"status"`
-	CreatedAt time.Time  `json:"created_at"`
+	ID              uint       `json:"id" gorm:"primaryKey"`
+	EventID         uint       `json:"event_id"`
+	JobType         JobType    `json:"job_type"`
+	RunAt           time.Time  `json:"run_at"`
+	Status          StatusType `json:"status"`
+	MessageTemplate string     `json:"message_template" gorm:"type:text"` // kosong = pakai pesan default
+	// Perubahan event untuk job event_update
+	Changes []event.FieldChange `json:"changes,omitempty" gorm:"serializer:json;type:text"`
+	// Broadcast yang dikirim oleh job broadcast
+	BroadcastID *uint     `json:"broadcast_id,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
 
 	Event event.Event `json:"event" gorm:"foreignKey:EventID"`
 }
 
 // 📩 Request struct
 type CreateScheduleRequest struct {
-	EventID uint      `json:"event_id" validate:"required"`
-	JobType JobType   `json:"job_type" validate:"required,oneof=reminder end_event"`
-	RunAt   time.Time `json:"run_at" validate:"required"`
+	EventID         uint      `json:"event_id" validate:"required"`
+	JobType         JobType   `json:"job_type" validate:"required,oneof=reminder end_event"`
+	RunAt           time.Time `json:"run_at" validate:"required,future"`
+	MessageTemplate string    `json:"message_template" validate:"max=2000"`
+}
+
+type PreviewTemplateRequest struct {
+	MessageTemplate string `json:"message_template" validate:"required,max=2000"`
 }
 
 // 📤 Response struct
 type ScheduleResponse struct {
-	ID      uint       `json:"id"`
-	EventID uint       `json:"event_id"`
-	JobType JobType    `json:"job_type"`
-	RunAt   time.Time  `json:"run_at"`
-	Status  StatusType `json:"status"`
+	ID              uint                `json:"id"`
+	EventID         uint                `json:"event_id"`
+	JobType         JobType             `json:"job_type"`
+	RunAt           time.Time           `json:"run_at"`
+	Status          StatusType          `json:"status"`
+	MessageTemplate string              `json:"message_template,omitempty"`
+	Changes         []event.FieldChange `json:"changes,omitempty"`
+	BroadcastID     *uint               `json:"broadcast_id,omitempty"`
+}
+
+func (j *ScheduleJob) ToResponse() ScheduleResponse {
+	return ScheduleResponse{
+		ID:              j.ID,
+		EventID:         j.EventID,
+		JobType:         j.JobType,
+		RunAt:           j.RunAt,
+		Status:          j.Status,
+		MessageTemplate: j.MessageTemplate,
+		Changes:         j.Changes,
+		BroadcastID:     j.BroadcastID,
+	}
+}
+
+type PreviewTemplateResponse struct {
+	MessageTemplate string `json:"message_template"`
+	Preview         string `json:"preview"`
 }
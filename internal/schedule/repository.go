@@ -1,6 +1,13 @@
 package schedule
 
-import "gorm.io/gorm"
+import (
+	"errors"
+	"go-event/internal/event"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
 
 type Repository interface {
 	Create(job *ScheduleJob) error
@@ -10,6 +17,9 @@ type Repository interface {
 	Delete(id uint) error
 	FindPending() ([]ScheduleJob, error)
 	GetByID(id uint) (*ScheduleJob, error)
+	Claim(id uint, runAt time.Time) (bool, error)
+	FindPendingUpdate(eventID uint, after time.Time) (*ScheduleJob, error)
+	WithEventLock(eventID uint, fn func(repo Repository) error) error
 }
 
 type repository struct {
@@ -60,3 +70,41 @@ func (r *repository) GetByID(id uint) (*ScheduleJob, error) {
 	}
 	return &job, nil
 }
+
+// Claim menandai job pending sebagai done sebelum dijalankan. false jika job sudah
+// diambil proses lain atau run_at-nya baru saja dimundurkan
+func (r *repository) Claim(id uint, runAt time.Time) (bool, error) {
+	result := r.db.Model(&ScheduleJob{}).
+		Where("id = ? AND status = ? AND run_at = ?", id, StatusPending, runAt).
+		Update("status", StatusDone)
+	return result.RowsAffected == 1, result.Error
+}
+
+// FindPendingUpdate mengambil job event_update yang masih bisa digabung (run_at > after).
+// Row dikunci, jadi hanya dipanggil di dalam WithEventLock. nil jika tidak ada
+func (r *repository) FindPendingUpdate(eventID uint, after time.Time) (*ScheduleJob, error) {
+	var job ScheduleJob
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("event_id = ? AND job_type = ? AND status = ? AND run_at > ?", eventID, JobTypeEventUpdate, StatusPending, after).
+		Order("run_at desc").
+		Take(&job).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// WithEventLock menjalankan fn dalam transaksi dengan row event terkunci,
+// agar job event_update untuk satu event tidak dibuat dobel
+func (r *repository) WithEventLock(eventID uint, fn func(repo Repository) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id").Take(&event.Event{}, eventID).Error; err != nil {
+			return err
+		}
+		return fn(&repository{db: tx})
+	})
+}
@@ -1,17 +1,19 @@
 package schedule
 
 import (
+	"go-event/internal/audit"
 	"go-event/pkg/config"
 	"go-event/pkg/middlewares"
 
 	"github.com/gofiber/fiber/v2"
 )
 
-func SetupScheduleRoutes(app *fiber.App, ctrl *Controller, cfg *config.Config) {
+func SetupScheduleRoutes(app *fiber.App, ctrl *Controller, cfg *config.Config, auditLog *audit.Recorder) {
 	schedules := app.Group("/api/schedule/event")
-	schedules.Post("/:id", middlewares.Authenticate(cfg), middlewares.Authorize("organizer"), ctrl.CreateSchedule)
+	schedules.Post("/:id", middlewares.Authenticate(cfg), middlewares.Authorize("organizer"), auditLog.Record("schedule.create", audit.Created("schedule", "schedule.id")), ctrl.CreateSchedule)
 	schedules.Get("/:id", middlewares.Authenticate(cfg), middlewares.Authorize("organizer"), ctrl.GetSchedules)
+	schedules.Post("/:id/preview", middlewares.Authenticate(cfg), middlewares.Authorize("organizer"), ctrl.PreviewTemplate)
 
 	schedules2 := app.Group("/api/schedule")
-	schedules2.Delete("/:id", middlewares.Authenticate(cfg), middlewares.Authorize("organizer"), ctrl.DeleteSchedule)
+	schedules2.Delete("/:id", middlewares.Authenticate(cfg), middlewares.Authorize("organizer"), auditLog.Record("schedule.delete", audit.Param("schedule", "id")), ctrl.DeleteSchedule)
 }
\ No newline at end of file
@@ -2,9 +2,17 @@ package schedule
 
 import (
 	"fmt"
+	"go-event/internal/broadcast"
+	"go-event/internal/event"
+	"go-event/internal/eventbus"
 	"go-event/internal/notification"
 	"go-event/internal/participant"
+	"go-event/internal/ticket"
 	"go-event/internal/user"
+	"go-event/internal/webhook"
+	"go-event/pkg/i18n"
+	"go-event/pkg/idempotency"
+	"go-event/pkg/timezone"
 	"log"
 	"time"
 
@@ -12,11 +20,17 @@ import (
 )
 
 type Scheduler struct {
-	repo            Repository
-	notifService    notification.Service
-	participantRepo participant.Repository
-	userRepo        user.Repository
-	cron            *gocron.Scheduler
+	repo             Repository
+	notifService     notification.Service
+	participantRepo  participant.Repository
+	userRepo         user.Repository
+	eventService     event.Service
+	ticketService    ticket.Service
+	broadcastService broadcast.Service
+	webhookService   webhook.Service
+	idempotencyRepo  idempotency.Repository
+	bus              eventbus.Publisher
+	cron             *gocron.Scheduler
 }
 
 func NewScheduler(
@@ -24,19 +38,49 @@ func NewScheduler(
 	notifService notification.Service,
 	participantRepo participant.Repository,
 	userRepo user.Repository,
+	eventService event.Service,
+	ticketService ticket.Service,
+	broadcastService broadcast.Service,
+	webhookService webhook.Service,
+	idempotencyRepo idempotency.Repository,
+	bus eventbus.Publisher,
 ) *Scheduler {
 	return &Scheduler{
-		repo:            repo,
-		notifService:    notifService,
-		participantRepo: participantRepo,
-		userRepo:        userRepo,
-		cron:            gocron.NewScheduler(time.UTC),
+		repo:             repo,
+		notifService:     notifService,
+		participantRepo:  participantRepo,
+		userRepo:         userRepo,
+		eventService:     eventService,
+		ticketService:    ticketService,
+		broadcastService: broadcastService,
+		webhookService:   webhookService,
+		idempotencyRepo:  idempotencyRepo,
+		bus:              bus,
+		cron:             gocron.NewScheduler(time.UTC),
 	}
 }
 
 func (s *Scheduler) Start() {
 	// Jalankan setiap 1 menit untuk cek pending jobs
 	s.cron.Every(1).Minute().Do(s.processPendingJobs)
+	// Pindahkan status event ke ongoing/completed sesuai StartTime/EndTime
+	s.cron.Every(1).Minute().Do(s.processEventLifecycle)
+	// Lepas kursi dari order yang tidak dibayar sampai batas waktu
+	s.cron.Every(1).Minute().Do(s.processExpiredOrders)
+	// Coba ulang refund order yang sebelumnya gagal di payment provider
+	s.cron.Every(15).Minutes().Do(s.retryRefunds)
+	// Kirim email notifikasi yang ditahan karena quiet hours
+	s.cron.Every(1).Minute().Do(s.processDeferredEmails)
+	// Kirim digest harian/mingguan ke user yang mengaktifkannya
+	s.cron.Every(15).Minutes().Do(s.processDigests)
+	// Kirim ulang webhook yang gagal dan sudah jatuh tempo (backoff)
+	s.cron.Every(1).Minute().Do(s.retryWebhooks)
+	// Hapus notifikasi yang sudah dibaca dan melewati masa retention
+	s.cron.Every(1).Hour().Do(s.purgeReadNotifications)
+	// Bersihkan response Idempotency-Key yang sudah kadaluarsa
+	s.cron.Every(1).Hour().Do(s.purgeIdempotencyKeys)
+	// Hapus log delivery webhook yang sudah lama
+	s.cron.Every(1).Hour().Do(s.purgeWebhookDeliveries)
 	
 	log.Println("Scheduler started - checking jobs every 1 minute")
 	s.cron.StartAsync()
@@ -58,6 +102,16 @@ func (s *Scheduler) processPendingJobs() {
 	for _, job := range jobs {
 		// Cek apakah waktu run_at sudah lewat
 		if job.RunAt.Before(now) || job.RunAt.Equal(now) {
+			// Klaim job (status jadi done) sebelum dijalankan. Gagal jika job event_update
+			// baru saja digabung dengan perubahan lain, job tersebut diambil di putaran berikutnya
+			claimed, err := s.repo.Claim(job.ID, job.RunAt)
+			if err != nil {
+				log.Printf("scheduler: failed to claim job ID %d: %v", job.ID, err)
+				continue
+			}
+			if !claimed {
+				continue
+			}
 			log.Printf("scheduler: processing job ID %d, type: %s, event: %d", job.ID, job.JobType, job.EventID)
 			
 			if err := s.executeJob(&job); err != nil {
@@ -67,30 +121,101 @@ func (s *Scheduler) processPendingJobs() {
 					log.Printf("scheduler: failed to update job status to failed: %v", updateErr)
 				}
 			} else {
-				// Update status jadi done
-				if updateErr := s.repo.UpdateStatus(job.ID, StatusDone); updateErr != nil {
-					log.Printf("scheduler: failed to update job status to done: %v", updateErr)
-				}
 				log.Printf("scheduler: job ID %d executed successfully", job.ID)
 			}
 		}
 	}
 }
 
+func (s *Scheduler) processEventLifecycle() {
+	if err := s.eventService.AdvanceLifecycle(time.Now()); err != nil {
+		log.Printf("scheduler: failed to advance event lifecycle: %v", err)
+	}
+}
+
+func (s *Scheduler) processExpiredOrders() {
+	if err := s.ticketService.ExpireOrders(time.Now()); err != nil {
+		log.Printf("scheduler: failed to expire orders: %v", err)
+	}
+}
+
+func (s *Scheduler) retryRefunds() {
+	if err := s.ticketService.RetryRefunds(time.Now()); err != nil {
+		log.Printf("scheduler: failed to retry refunds: %v", err)
+	}
+}
+
+func (s *Scheduler) processDeferredEmails() {
+	if err := s.notifService.SendDeferredEmails(time.Now()); err != nil {
+		log.Printf("scheduler: failed to send deferred emails: %v", err)
+	}
+}
+
+func (s *Scheduler) processDigests() {
+	if err := s.notifService.SendDigests(time.Now()); err != nil {
+		log.Printf("scheduler: failed to send digests: %v", err)
+	}
+}
+
+func (s *Scheduler) purgeReadNotifications() {
+	if err := s.notifService.PurgeReadNotifications(time.Now()); err != nil {
+		log.Printf("scheduler: failed to purge read notifications: %v", err)
+	}
+}
+
+func (s *Scheduler) purgeIdempotencyKeys() {
+	if err := s.idempotencyRepo.Purge(time.Now()); err != nil {
+		log.Printf("scheduler: failed to purge idempotency keys: %v", err)
+	}
+}
+
+func (s *Scheduler) retryWebhooks() {
+	if err := s.webhookService.RetryDeliveries(time.Now()); err != nil {
+		log.Printf("scheduler: failed to retry webhook deliveries: %v", err)
+	}
+}
+
+func (s *Scheduler) purgeWebhookDeliveries() {
+	if err := s.webhookService.PurgeDeliveries(time.Now()); err != nil {
+		log.Printf("scheduler: failed to purge webhook deliveries: %v", err)
+	}
+}
+
 func (s *Scheduler) executeJob(job *ScheduleJob) error {
+	// Broadcast tetap dikirim untuk event yang dibatalkan, organizer bisa saja
+	// ingin menyampaikan informasi setelah pembatalan
+	if job.JobType == JobTypeBroadcast {
+		if job.BroadcastID == nil {
+			return fmt.Errorf("broadcast job %d has no broadcast", job.ID)
+		}
+		return s.broadcastService.Deliver(*job.BroadcastID)
+	}
+
+	// Event yang dibatalkan tidak perlu dikirimi reminder / notifikasi selesai
+	if job.Event.Status == event.StatusCancelled {
+		log.Printf("scheduler: skipping job ID %d, event %d is cancelled", job.ID, job.EventID)
+		return nil
+	}
+
 	switch job.JobType {
 	case JobTypeReminder:
 		return s.sendReminderNotification(job)
 	case JobTypeEndEvent:
 		return s.sendEndEventNotification(job)
+	case JobTypeEventUpdate:
+		// Event yang kembali ke draft tidak punya participant
+		if job.Event.Status == event.StatusDraft {
+			return nil
+		}
+		return s.notifService.NotifyEventUpdate(&job.Event, job.Changes)
 	default:
 		return fmt.Errorf("unknown job type: %s", job.JobType)
 	}
 }
 
 func (s *Scheduler) sendReminderNotification(job *ScheduleJob) error {
-	// Ambil semua participant dari event
-	participants, err := s.participantRepo.FindByEventID(job.EventID)
+	// Ambil participant terkonfirmasi (pendaftar pending/rejected tidak ikut)
+	participants, err := s.participantRepo.FindConfirmedByEventID(job.EventID)
 	if err != nil {
 		return fmt.Errorf("failed to get participants: %w", err)
 	}
@@ -100,10 +225,7 @@ func (s *Scheduler) sendReminderNotification(job *ScheduleJob) error {
 		return nil
 	}
 
-	// Format tanggal event
-	eventDate := job.Event.StartTime.Format("02 Jan 2006 15:04")
-
-	// Kirim notifikasi ke setiap participant
+	// Kirim notifikasi ke setiap participant dalam bahasanya
 	successCount := 0
 	for _, p := range participants {
 		// Ambil data user untuk email
@@ -113,9 +235,7 @@ func (s *Scheduler) sendReminderNotification(job *ScheduleJob) error {
 			continue
 		}
 
-		message := fmt.Sprintf("Reminder: Event '%s' akan dimulai segera pada %s", 
-			job.Event.Title, 
-			eventDate)
+		message := reminderMessage(job, userInfo.Name, i18n.Resolve(userInfo.Locale), timezone.Resolve(userInfo.Timezone, job.Event.Timezone))
 
 		req := &notification.CreateNotificationRequest{
 			UserID:  p.UserID,
@@ -133,12 +253,30 @@ func (s *Scheduler) sendReminderNotification(job *ScheduleJob) error {
 	}
 
 	log.Printf("scheduler: sent %d reminder notifications for event %d", successCount, job.EventID)
+	if err := s.bus.Publish(eventbus.ReminderSent{
+		Meta:       eventbus.NewMeta(0, job.EventID, job.Event.OrganizerID),
+		Event:      job.Event.Snapshot(),
+		ScheduleID: job.ID,
+		Recipients: successCount,
+	}); err != nil {
+		log.Printf("scheduler: failed to handle %s for event %d: %v", eventbus.TopicReminderSent, job.EventID, err)
+	}
+
+	s.notifyGuests(job, participants, notification.NotifReminder, reminderMessage)
 	return nil
 }
 
+// reminderMessage adalah pesan reminder default atau template organizer
+func reminderMessage(job *ScheduleJob, name string, locale i18n.Locale, loc *time.Location) string {
+	if job.MessageTemplate != "" {
+		return RenderTemplate(job.MessageTemplate, newTemplateData(&job.Event, name, locale, loc))
+	}
+	return i18n.T(locale, "notification.reminder", job.Event.Title, i18n.FormatDateTime(locale, job.Event.StartTime.In(loc)))
+}
+
 func (s *Scheduler) sendEndEventNotification(job *ScheduleJob) error {
-	// Ambil semua participant dari event
-	participants, err := s.participantRepo.FindByEventID(job.EventID)
+	// Ambil participant terkonfirmasi (pendaftar pending/rejected tidak ikut)
+	participants, err := s.participantRepo.FindConfirmedByEventID(job.EventID)
 	if err != nil {
 		return fmt.Errorf("failed to get participants: %w", err)
 	}
@@ -158,8 +296,7 @@ func (s *Scheduler) sendEndEventNotification(job *ScheduleJob) error {
 			continue
 		}
 
-		message := fmt.Sprintf("Event '%s' telah selesai. Terima kasih atas partisipasi Anda!", 
-			job.Event.Title)
+		message := endEventMessage(job, userInfo.Name, i18n.Resolve(userInfo.Locale), timezone.Resolve(userInfo.Timezone, job.Event.Timezone))
 
 		req := &notification.CreateNotificationRequest{
 			UserID:  p.UserID,
@@ -177,5 +314,36 @@ func (s *Scheduler) sendEndEventNotification(job *ScheduleJob) error {
 	}
 
 	log.Printf("scheduler: sent %d end event notifications for event %d", successCount, job.EventID)
+
+	s.notifyGuests(job, participants, notification.NotifUpdate, endEventMessage)
 	return nil
 }
+
+// endEventMessage adalah pesan event selesai default atau template organizer
+func endEventMessage(job *ScheduleJob, name string, locale i18n.Locale, loc *time.Location) string {
+	if job.MessageTemplate != "" {
+		return RenderTemplate(job.MessageTemplate, newTemplateData(&job.Event, name, locale, loc))
+	}
+	return i18n.T(locale, "notification.event_ended", job.Event.Title)
+}
+
+// notifyGuests mengirim email ke guest dari group registration (tidak punya akun / notifikasi in-app)
+// dalam bahasa participant yang mendaftarkannya dan timezone event
+func (s *Scheduler) notifyGuests(job *ScheduleJob, participants []participant.Participant, notifType notification.NotifType, buildMessage func(job *ScheduleJob, name string, locale i18n.Locale, loc *time.Location) string) {
+	total, successCount := 0, 0
+	loc := job.Event.Zone()
+	for _, p := range participants {
+		locale := i18n.Resolve(p.User.Locale)
+		for _, g := range p.Guests {
+			total++
+			if err := s.notifService.SendGuestEmail(job.EventID, notifType, buildMessage(job, g.Name, locale, loc), g.Email, g.Name, locale); err != nil {
+				log.Printf("scheduler: failed to send %s email to guest %d: %v", notifType, g.ID, err)
+				continue
+			}
+			successCount++
+		}
+	}
+	if total > 0 {
+		log.Printf("scheduler: sent %d %s emails to guests of event %d", successCount, notifType, job.EventID)
+	}
+}
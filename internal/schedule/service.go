@@ -2,87 +2,127 @@ package schedule
 
 import (
 	"errors"
+	"fmt"
 	"go-event/internal/event"
+	"go-event/internal/eventbus"
+	"go-event/pkg/apperror"
 	"go-event/pkg/config"
+	"go-event/pkg/i18n"
+	"go-event/pkg/validation"
+	"log"
 	"time"
+
+	"gorm.io/gorm"
 )
 
 type Service interface {
-	CreateSchedule(req *CreateScheduleRequest) (*ScheduleResponse, error)
-	GetSchedulesByEventID(eventID uint) ([]ScheduleResponse, error)
+	CreateSchedule(userID uint, req *CreateScheduleRequest) (*ScheduleResponse, error)
+	GetSchedulesByEventID(userID, eventID uint) ([]ScheduleResponse, error)
 	DeleteSchedule(scheduleID uint, userID uint) error
+	PreviewTemplate(userID, eventID uint, req *PreviewTemplateRequest) (*PreviewTemplateResponse, error)
+	OnEventUpdated(e eventbus.EventUpdated) error
+	ScheduleBroadcast(eventID, broadcastID uint, runAt time.Time) error
 }
 
+// previewParticipantName dipakai sebagai contoh nama participant saat preview
+const previewParticipantName = "Budi Santoso"
+
+// Default jendela debounce notifikasi update jika konfigurasi tidak valid
+const (
+	defaultUpdateDebounce = 5 * time.Minute
+	defaultUpdateMaxDelay = 30 * time.Minute
+)
+
 type service struct {
 	repo      Repository
 	eventRepo event.Repository
 	cfg       *config.Config
+
+	updateDebounce time.Duration
+	updateMaxDelay time.Duration
 }
 
 // CreateSchedule implements Service.
-func (s *service) CreateSchedule(req *CreateScheduleRequest) (*ScheduleResponse, error) {
-	// Validasi event exists
-	events, err := s.eventRepo.GetByID(req.EventID)
+// Hanya organizer pemilik event, karena pesan custom dikirim ke semua participant event
+func (s *service) CreateSchedule(userID uint, req *CreateScheduleRequest) (*ScheduleResponse, error) {
+	events, err := s.getOwnedEvent(userID, req.EventID, "unauthorized to schedule jobs for this event")
 	if err != nil {
-		return nil, errors.New("event not found")
+		return nil, err
+	}
+
+	// Event yang sudah selesai / dibatalkan tidak bisa dijadwalkan lagi
+	if events.Status.IsFinal() {
+		return nil, ErrEventFinalized
 	}
 
 	// Validasi waktu run_at tidak boleh sebelum waktu sekarang
 	if req.RunAt.Before(time.Now()) {
-		return nil, errors.New("run_at must be in the future")
+		return nil, validation.NewError("run_at", "future", "", "must be in the future")
 	}
 
 	// Validasi waktu run_at tidak boleh setelah event selesai
 	if req.RunAt.After(events.EndTime) {
-		return nil, errors.New("run_at cannot be after event end time")
+		return nil, validation.NewError("run_at", "ltefield", "end_time", "cannot be after event end_time")
+	}
+
+	// Validasi template pesan custom jika diisi
+	if req.MessageTemplate != "" {
+		if err := ValidateTemplate(req.MessageTemplate); err != nil {
+			return nil, validation.NewError("message_template", "template", "", err.Error())
+		}
 	}
 
 	// Buat schedule job
 	job := &ScheduleJob{
-		EventID:   req.EventID,
-		JobType:   req.JobType,
-		RunAt:     req.RunAt,
-		Status:    StatusPending,
-		CreatedAt: time.Now(),
+		EventID:         req.EventID,
+		JobType:         req.JobType,
+		RunAt:           req.RunAt,
+		Status:          StatusPending,
+		MessageTemplate: req.MessageTemplate,
+		CreatedAt:       time.Now(),
 	}
 
 	if err := s.repo.Create(job); err != nil {
-		return nil, errors.New("failed to create schedule: " + err.Error())
+		return nil, apperror.Internal(fmt.Errorf("failed to create schedule: %w", err))
+	}
+
+	response := job.ToResponse()
+	return &response, nil
+}
+
+// PreviewTemplate implements Service.
+// Hanya organizer pemilik event, preview berisi judul, lokasi, dan waktu event
+func (s *service) PreviewTemplate(userID, eventID uint, req *PreviewTemplateRequest) (*PreviewTemplateResponse, error) {
+	events, err := s.getOwnedEvent(userID, eventID, "unauthorized to preview templates for this event")
+	if err != nil {
+		return nil, err
 	}
 
-	response := &ScheduleResponse{
-		ID:      job.ID,
-		EventID: job.EventID,
-		JobType: job.JobType,
-		RunAt:   job.RunAt,
-		Status:  job.Status,
+	if err := ValidateTemplate(req.MessageTemplate); err != nil {
+		return nil, validation.NewError("message_template", "template", "", err.Error())
 	}
 
-	return response, nil
+	return &PreviewTemplateResponse{
+		MessageTemplate: req.MessageTemplate,
+		Preview:         RenderTemplate(req.MessageTemplate, newTemplateData(events, previewParticipantName, i18n.Default, events.Zone())),
+	}, nil
 }
 
 // GetSchedulesByEventID implements Service.
-func (s *service) GetSchedulesByEventID(eventID uint) ([]ScheduleResponse, error) {
-	// Validasi event exists
-	_, err := s.eventRepo.GetByID(eventID)
-	if err != nil {
-		return nil, errors.New("event not found")
+// Hanya organizer pemilik event, karena response berisi template pesan
+func (s *service) GetSchedulesByEventID(userID, eventID uint) ([]ScheduleResponse, error) {
+	if _, err := s.getOwnedEvent(userID, eventID, "unauthorized to view schedules for this event"); err != nil {
+		return nil, err
 	}
 
 	jobs, err := s.repo.FindByEventID(eventID)
 	if err != nil {
-		return nil, errors.New("failed to retrieve schedules: " + err.Error())
+		return nil, apperror.Internal(fmt.Errorf("failed to retrieve schedules: %w", err))
 	}
 
 	var responses []ScheduleResponse
 	for _, job := range jobs {
-		responses = append(responses, ScheduleResponse{
-			ID:      job.ID,
-			EventID: job.EventID,
-			JobType: job.JobType,
-			RunAt:   job.RunAt,
-			Status:  job.Status,
-		})
+		responses = append(responses, job.ToResponse())
 	}
 
 	return responses, nil
@@ -93,31 +133,145 @@ func (s *service) DeleteSchedule(scheduleID uint, userID uint) error {
 	// Cari schedule berdasarkan ID
 	job, err := s.repo.GetByID(scheduleID)
 	if err != nil || job == nil {
-		return errors.New("schedule not found")
+		return ErrScheduleNotFound
 	}
 
 	// Validasi user adalah organizer dari event tersebut
 	events, err := s.eventRepo.GetByID(job.EventID)
 	if err != nil {
-		return errors.New("event not found")
+		return ErrEventNotFound
 	}
 
 	if events.OrganizerID != userID {
-		return errors.New("unauthorized to delete this schedule")
+		return ErrNotOrganizer
+	}
+
+	// Broadcast dibatalkan lewat endpoint broadcast agar statusnya ikut berubah
+	if job.JobType == JobTypeBroadcast {
+		return ErrBroadcastJob
 	}
 
 	// Delete schedule
 	if err := s.repo.Delete(scheduleID); err != nil {
-		return errors.New("failed to delete schedule: " + err.Error())
+		return apperror.Internal(fmt.Errorf("failed to delete schedule: %w", err))
 	}
 
 	return nil
 }
 
+// getOwnedEvent mengambil event dan memastikan userID adalah organizer-nya.
+// message dipakai sebagai pesan error forbidden
+func (s *service) getOwnedEvent(userID, eventID uint, message string) (*event.Event, error) {
+	events, err := s.eventRepo.GetByID(eventID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrEventNotFound
+		}
+		return nil, apperror.Internal(err)
+	}
+	if events.OrganizerID != userID {
+		return nil, ErrNotOrganizer.WithMessage(message)
+	}
+	return events, nil
+}
+
+// OnEventUpdated implements Service (subscriber EventUpdated).
+// Perubahan yang perlu dikabarkan ke participant dijadwalkan sebagai job event_update.
+// Event draft belum punya participant
+func (s *service) OnEventUpdated(e eventbus.EventUpdated) error {
+	if e.Event.Status == string(event.StatusDraft) {
+		return nil
+	}
+	changes := event.NotifiableChanges(e.Changes)
+	if len(changes) == 0 {
+		return nil
+	}
+	return s.queueUpdateNotification(e.Event.ID, changes)
+}
+
+// queueUpdateNotification menggabungkan perubahan ke job event_update yang masih pending;
+// run_at dimundurkan setiap ada perubahan baru, tapi tidak lebih dari updateMaxDelay sejak
+// perubahan pertama. Debounce 0 = dikirim pada putaran scheduler berikutnya
+func (s *service) queueUpdateNotification(eventID uint, changes []event.FieldChange) error {
+	err := s.repo.WithEventLock(eventID, func(repo Repository) error {
+		now := time.Now()
+		job, err := repo.FindPendingUpdate(eventID, now)
+		if err != nil {
+			return err
+		}
+		if job == nil {
+			return repo.Create(&ScheduleJob{
+				EventID:   eventID,
+				JobType:   JobTypeEventUpdate,
+				RunAt:     now.Add(s.updateDebounce),
+				Status:    StatusPending,
+				Changes:   changes,
+				CreatedAt: now,
+			})
+		}
+
+		job.Changes = mergeChanges(job.Changes, changes)
+		job.RunAt = now.Add(s.updateDebounce)
+		if deadline := job.CreatedAt.Add(s.updateMaxDelay); job.RunAt.After(deadline) {
+			job.RunAt = deadline
+		}
+		return repo.Update(job)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to queue update notification: %w", err)
+	}
+	return nil
+}
+
+// ScheduleBroadcast implements Service (broadcast.Scheduler).
+func (s *service) ScheduleBroadcast(eventID, broadcastID uint, runAt time.Time) error {
+	if err := s.repo.Create(&ScheduleJob{
+		EventID:     eventID,
+		JobType:     JobTypeBroadcast,
+		RunAt:       runAt,
+		Status:      StatusPending,
+		BroadcastID: &broadcastID,
+	}); err != nil {
+		return fmt.Errorf("failed to schedule broadcast: %w", err)
+	}
+	return nil
+}
+
+// mergeChanges menimpa perubahan untuk field yang sama dan menambahkan field baru di akhir
+func mergeChanges(current, incoming []event.FieldChange) []event.FieldChange {
+	merged := append([]event.FieldChange(nil), current...)
+	for _, change := range incoming {
+		replaced := false
+		for i := range merged {
+			if merged[i].Field == change.Field {
+				merged[i] = change
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			merged = append(merged, change)
+		}
+	}
+	return merged
+}
+
+// parseDuration membaca durasi dari konfigurasi, fallback ke def jika tidak valid
+func parseDuration(name, value string, def time.Duration) time.Duration {
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		log.Printf("schedule: invalid %s %q, using %s", name, value, def)
+		return def
+	}
+	return d
+}
+
 func NewService(repo Repository, eventRepo event.Repository, cfg *config.Config) Service {
 	return &service{
-		repo:      repo,
-		eventRepo: eventRepo,
-		cfg:       cfg,
+		repo:           repo,
+		eventRepo:      eventRepo,
+		cfg:            cfg,
+		updateDebounce: parseDuration("UPDATE_NOTIFICATION_DEBOUNCE", cfg.UpdateNotificationDebounce, defaultUpdateDebounce),
+		updateMaxDelay: parseDuration("UPDATE_NOTIFICATION_MAX_DELAY", cfg.UpdateNotificationMaxDelay, defaultUpdateMaxDelay),
 	}
 }
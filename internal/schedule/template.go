@@ -0,0 +1,95 @@
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"go-event/internal/event"
+	"go-event/pkg/i18n"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// MaxTemplateLength batas panjang template pesan yang ditulis organizer
+const MaxTemplateLength = 2000
+
+// Placeholder yang boleh dipakai di template pesan
+const (
+	PlaceholderParticipantName = "participant.name"
+	PlaceholderEventTitle      = "event.title"
+	PlaceholderEventStartTime  = "event.start_time"
+	PlaceholderEventLocation   = "event.location"
+)
+
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([a-zA-Z0-9_.]*)\s*\}\}`)
+
+var allowedPlaceholders = map[string]bool{
+	PlaceholderParticipantName: true,
+	PlaceholderEventTitle:      true,
+	PlaceholderEventStartTime:  true,
+	PlaceholderEventLocation:   true,
+}
+
+// TemplateData berisi nilai untuk mengisi placeholder template
+type TemplateData struct {
+	ParticipantName string
+	EventTitle      string
+	EventStartTime  string
+	EventLocation   string
+}
+
+// newTemplateData menyiapkan data template dari event dan nama participant.
+// Waktu event ditulis sesuai locale dan timezone penerima
+func newTemplateData(ev *event.Event, participantName string, locale i18n.Locale, loc *time.Location) TemplateData {
+	return TemplateData{
+		ParticipantName: participantName,
+		EventTitle:      ev.Title,
+		EventStartTime:  i18n.FormatDateTime(locale, ev.StartTime.In(loc)),
+		EventLocation:   ev.Location,
+	}
+}
+
+// ValidateTemplate memastikan template hanya memakai placeholder yang dikenal
+// dan tidak memiliki kurung kurawal yang tidak berpasangan
+func ValidateTemplate(tmpl string) error {
+	if strings.TrimSpace(tmpl) == "" {
+		return errors.New("message template cannot be empty")
+	}
+	if utf8.RuneCountInString(tmpl) > MaxTemplateLength {
+		return fmt.Errorf("message template cannot exceed %d characters", MaxTemplateLength)
+	}
+
+	for _, match := range placeholderPattern.FindAllStringSubmatch(tmpl, -1) {
+		if !allowedPlaceholders[match[1]] {
+			return fmt.Errorf("unknown placeholder %s", match[0])
+		}
+	}
+
+	// Setelah semua placeholder valid dihapus, tidak boleh ada sisa "{{" atau "}}"
+	rest := placeholderPattern.ReplaceAllString(tmpl, "")
+	if strings.Contains(rest, "{{") || strings.Contains(rest, "}}") {
+		return errors.New("message template has unbalanced braces")
+	}
+	return nil
+}
+
+// RenderTemplate mengganti placeholder dengan data. Hasilnya plain text,
+// escaping HTML dilakukan oleh email service saat menyisipkan ke body email
+func RenderTemplate(tmpl string, data TemplateData) string {
+	return placeholderPattern.ReplaceAllStringFunc(tmpl, func(token string) string {
+		key := placeholderPattern.FindStringSubmatch(token)[1]
+		switch key {
+		case PlaceholderParticipantName:
+			return data.ParticipantName
+		case PlaceholderEventTitle:
+			return data.EventTitle
+		case PlaceholderEventStartTime:
+			return data.EventStartTime
+		case PlaceholderEventLocation:
+			return data.EventLocation
+		default:
+			return token
+		}
+	})
+}
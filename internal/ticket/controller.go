@@ -0,0 +1,325 @@
+package ticket
+
+import (
+	"go-event/pkg/apperror"
+	"go-event/pkg/config"
+	"go-event/pkg/validation"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type Controller struct {
+	service Service
+	cfg     *config.Config
+}
+
+func NewController(service Service, cfg *config.Config) *Controller {
+	return &Controller{service: service, cfg: cfg}
+}
+
+func (ctrl *Controller) CreateTicketType(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	eventID, err := parseID(c, "event ID")
+	if err != nil {
+		return err
+	}
+
+	var req CreateTicketTypeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return apperror.ErrInvalidBody
+	}
+	if err := validation.Struct(&req); err != nil {
+		return err
+	}
+
+	ticketType, err := ctrl.service.CreateTicketType(userID, eventID, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message":     "ticket type created successfully",
+		"ticket_type": ticketType,
+	})
+}
+
+func (ctrl *Controller) GetTicketTypes(c *fiber.Ctx) error {
+	eventID, err := parseID(c, "event ID")
+	if err != nil {
+		return err
+	}
+
+	ticketTypes, err := ctrl.service.GetTicketTypes(eventID)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message":      "ticket types retrieved successfully",
+		"ticket_types": ticketTypes,
+	})
+}
+
+func (ctrl *Controller) UpdateTicketType(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	ticketTypeID, err := parseID(c, "ticket type ID")
+	if err != nil {
+		return err
+	}
+
+	var req UpdateTicketTypeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return apperror.ErrInvalidBody
+	}
+	if err := validation.Struct(&req); err != nil {
+		return err
+	}
+
+	ticketType, err := ctrl.service.UpdateTicketType(userID, ticketTypeID, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message":     "ticket type updated successfully",
+		"ticket_type": ticketType,
+	})
+}
+
+func (ctrl *Controller) DeleteTicketType(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	ticketTypeID, err := parseID(c, "ticket type ID")
+	if err != nil {
+		return err
+	}
+
+	if err := ctrl.service.DeleteTicketType(userID, ticketTypeID); err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "ticket type deleted successfully",
+	})
+}
+
+func (ctrl *Controller) CreateOrder(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var req CreateOrderRequest
+	if err := c.BodyParser(&req); err != nil {
+		return apperror.ErrInvalidBody
+	}
+	if err := validation.Struct(&req); err != nil {
+		return err
+	}
+
+	order, err := ctrl.service.CreateOrder(userID, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "order created successfully",
+		"order":   order,
+	})
+}
+
+// QuoteOrder - hitung harga order (termasuk promo) tanpa menahan kursi
+func (ctrl *Controller) QuoteOrder(c *fiber.Ctx) error {
+	var req CreateOrderRequest
+	if err := c.BodyParser(&req); err != nil {
+		return apperror.ErrInvalidBody
+	}
+	if err := validation.Struct(&req); err != nil {
+		return err
+	}
+
+	quote, err := ctrl.service.QuoteOrder(&req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "order quote calculated successfully",
+		"quote":   quote,
+	})
+}
+
+func (ctrl *Controller) PayOrder(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	orderID, err := parseID(c, "order ID")
+	if err != nil {
+		return err
+	}
+
+	var req PayOrderRequest
+	if err := c.BodyParser(&req); err != nil {
+		return apperror.ErrInvalidBody
+	}
+	if err := validation.Struct(&req); err != nil {
+		return err
+	}
+
+	order, err := ctrl.service.PayOrder(userID, orderID, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "order paid successfully",
+		"order":   order,
+	})
+}
+
+func (ctrl *Controller) CancelOrder(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	orderID, err := parseID(c, "order ID")
+	if err != nil {
+		return err
+	}
+
+	order, err := ctrl.service.CancelOrder(userID, orderID)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "order cancelled successfully",
+		"order":   order,
+	})
+}
+
+func (ctrl *Controller) GetOrder(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	orderID, err := parseID(c, "order ID")
+	if err != nil {
+		return err
+	}
+
+	order, err := ctrl.service.GetOrder(userID, orderID)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "order retrieved successfully",
+		"order":   order,
+	})
+}
+
+func (ctrl *Controller) GetMyOrders(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	orders, err := ctrl.service.GetOrdersByUserID(userID)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "orders retrieved successfully",
+		"orders":  orders,
+	})
+}
+
+func (ctrl *Controller) CreatePromoCode(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	userRole := c.Locals("userRole").(string)
+
+	var req CreatePromoCodeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return apperror.ErrInvalidBody
+	}
+	if err := validation.Struct(&req); err != nil {
+		return err
+	}
+
+	promo, err := ctrl.service.CreatePromoCode(userID, userRole, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message":    "promo code created successfully",
+		"promo_code": promo,
+	})
+}
+
+// GetPromoCodes - ?event_id=1 untuk promo event, tanpa event_id untuk promo global (admin)
+func (ctrl *Controller) GetPromoCodes(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	userRole := c.Locals("userRole").(string)
+
+	var eventID *uint
+	if raw := c.Query("event_id"); raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 32)
+		if err != nil {
+			return apperror.InvalidParam("event_id")
+		}
+		v := uint(id)
+		eventID = &v
+	}
+
+	promos, err := ctrl.service.GetPromoCodes(userID, userRole, eventID)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message":     "promo codes retrieved successfully",
+		"promo_codes": promos,
+	})
+}
+
+func (ctrl *Controller) DeactivatePromoCode(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	userRole := c.Locals("userRole").(string)
+
+	promoID, err := parseID(c, "promo code ID")
+	if err != nil {
+		return err
+	}
+
+	promo, err := ctrl.service.DeactivatePromoCode(userID, userRole, promoID)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message":    "promo code deactivated successfully",
+		"promo_code": promo,
+	})
+}
+
+func (ctrl *Controller) DeletePromoCode(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	userRole := c.Locals("userRole").(string)
+
+	promoID, err := parseID(c, "promo code ID")
+	if err != nil {
+		return err
+	}
+
+	if err := ctrl.service.DeletePromoCode(userID, userRole, promoID); err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "promo code deleted successfully",
+	})
+}
+
+// parseID membaca path param :id, name dipakai untuk pesan error
+func parseID(c *fiber.Ctx, name string) (uint, error) {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return 0, apperror.InvalidParam(name)
+	}
+	return uint(id), nil
+}
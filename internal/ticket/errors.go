@@ -0,0 +1,28 @@
+package ticket
+
+import "go-event/pkg/apperror"
+
+var (
+	ErrTicketTypeNotFound = apperror.New(apperror.KindNotFound, "TICKET_TYPE_NOT_FOUND", "ticket type not found")
+	ErrOrderNotFound      = apperror.New(apperror.KindNotFound, "ORDER_NOT_FOUND", "order not found")
+	ErrEventNotFound      = apperror.New(apperror.KindNotFound, "EVENT_NOT_FOUND", "event not found")
+	ErrNotOrganizer       = apperror.New(apperror.KindForbidden, "TICKET_FORBIDDEN", "unauthorized to manage tickets for this event")
+	ErrEventFinalized     = apperror.New(apperror.KindConflict, "EVENT_FINALIZED", "cannot manage tickets for a completed or cancelled event")
+	ErrTicketTypeInUse    = apperror.New(apperror.KindConflict, "TICKET_TYPE_IN_USE", "ticket type already has orders")
+	ErrQuotaBelowReserved = apperror.New(apperror.KindConflict, "TICKET_QUOTA_BELOW_RESERVED", "quota cannot be lower than seats already reserved")
+	ErrRegistrationClosed = apperror.New(apperror.KindConflict, "REGISTRATION_CLOSED", "event is not open for registration")
+	ErrNotOnSale          = apperror.New(apperror.KindConflict, "TICKET_NOT_ON_SALE", "ticket is outside its sale window")
+	ErrSoldOut            = apperror.New(apperror.KindConflict, "TICKET_SOLD_OUT", "ticket is sold out")
+	ErrAlreadyRegistered  = apperror.New(apperror.KindConflict, "PARTICIPANT_ALREADY_REGISTERED", "user already registered for this event")
+	ErrOrderPending       = apperror.New(apperror.KindConflict, "ORDER_ALREADY_PENDING", "user already has a pending order for this event")
+	ErrOrderNotPending    = apperror.New(apperror.KindConflict, "ORDER_NOT_PENDING", "order is no longer awaiting payment")
+	ErrOrderExpired       = apperror.New(apperror.KindConflict, "ORDER_EXPIRED", "order reservation has expired")
+	ErrOrderNotPaid       = apperror.New(apperror.KindConflict, "ORDER_NOT_PAID", "only paid orders can be refunded")
+	ErrPromoCodeNotFound  = apperror.New(apperror.KindNotFound, "PROMO_CODE_NOT_FOUND", "promo code not found")
+	ErrPromoCodeExists    = apperror.New(apperror.KindConflict, "PROMO_CODE_EXISTS", "promo code already exists")
+	ErrPromoCodeExhausted = apperror.New(apperror.KindConflict, "PROMO_CODE_EXHAUSTED", "promo code usage limit reached")
+	ErrPromoCodeInUse     = apperror.New(apperror.KindConflict, "PROMO_CODE_IN_USE", "promo code already used by orders, deactivate it instead")
+	ErrPromoForbidden     = apperror.New(apperror.KindForbidden, "PROMO_CODE_FORBIDDEN", "unauthorized to manage this promo code")
+	ErrPaymentDeclined    = apperror.New(apperror.KindPaymentRequired, "PAYMENT_DECLINED", "payment was declined")
+	ErrRefundFailed       = apperror.New(apperror.KindInternal, "REFUND_FAILED", "refund could not be processed")
+)
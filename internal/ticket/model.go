@@ -0,0 +1,260 @@
+package ticket
+
+import (
+	"go-event/internal/participant"
+	"go-event/pkg/regform"
+	"time"
+)
+
+type OrderStatus string
+
+const (
+	OrderPending   OrderStatus = "pending"   // kursi di-hold sampai ExpiresAt
+	OrderPaid      OrderStatus = "paid"      // participant sudah dibuat
+	OrderExpired   OrderStatus = "expired"   // tidak dibayar sampai ExpiresAt, kursi dilepas
+	OrderCancelled OrderStatus = "cancelled" // dibatalkan sebelum dibayar
+	OrderRefunded  OrderStatus = "refunded"  // dibatalkan setelah dibayar, dana dikembalikan
+	// dibatalkan setelah dibayar, refund belum berhasil dan dicoba ulang oleh scheduler
+	OrderRefundPending OrderStatus = "refund_pending"
+)
+
+type DiscountType string
+
+const (
+	DiscountPercentage DiscountType = "percentage" // DiscountValue 1-100 (%)
+	DiscountFixed      DiscountType = "fixed"      // DiscountValue dalam satuan terkecil Currency
+)
+
+// 🧱 Entity (database model)
+type TicketType struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	EventID     uint       `json:"event_id" gorm:"index"`
+	Name        string     `json:"name" gorm:"size:100"`
+	Description string     `json:"description" gorm:"type:text"`
+	Price       int64      `json:"price"` // satuan terkecil mata uang, 0 = gratis
+	Currency    string     `json:"currency" gorm:"size:3"`
+	Quota       int        `json:"quota"`
+	SalesStart  *time.Time `json:"sales_start"` // nil = langsung dibuka
+	SalesEnd    *time.Time `json:"sales_end"`   // nil = sampai event dimulai
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+type Order struct {
+	ID           uint       `json:"id" gorm:"primaryKey"`
+	EventID      uint       `json:"event_id" gorm:"index"`
+	UserID       uint       `json:"user_id" gorm:"index"`
+	TicketTypeID uint       `json:"ticket_type_id" gorm:"index"`
+	TicketType   TicketType `json:"-" gorm:"foreignKey:TicketTypeID"`
+	Quantity     int        `json:"quantity" gorm:"default:1"` // 1 + jumlah guest
+	// Guest disimpan di order sampai lunas, baru dibuat sebagai participant.Guest
+	Guests         []participant.GuestRequest `json:"guests" gorm:"serializer:json;type:text"`
+	Answers        regform.Answers            `json:"answers" gorm:"serializer:json;type:text"` // disalin ke participant saat lunas
+	UnitPrice      int64                      `json:"unit_price"`                               // harga saat order dibuat, tidak ikut berubah
+	PromoCodeID    *uint                      `json:"promo_code_id" gorm:"index"`
+	PromoCode      *PromoCode                 `json:"-" gorm:"foreignKey:PromoCodeID"`
+	DiscountAmount int64                      `json:"discount_amount"`
+	TotalAmount    int64                      `json:"total_amount"`
+	Currency       string                     `json:"currency" gorm:"size:3"`
+	Status         OrderStatus                `json:"status" gorm:"size:32;index"`
+	ExpiresAt      time.Time                  `json:"expires_at" gorm:"index"`
+	PaymentRef     string                     `json:"payment_ref" gorm:"size:100"` // charge ID dari payment provider
+	PaidAt         *time.Time                 `json:"paid_at"`
+	RefundedAt     *time.Time                 `json:"refunded_at"`
+	CreatedAt      time.Time                  `json:"created_at"`
+	UpdatedAt      time.Time                  `json:"updated_at"`
+}
+
+// PromoCode berlaku untuk satu event (EventID) atau semua event (EventID nil, dibuat admin)
+type PromoCode struct {
+	ID            uint         `json:"id" gorm:"primaryKey"`
+	Code          string       `json:"code" gorm:"size:50;uniqueIndex"` // selalu uppercase
+	EventID       *uint        `json:"event_id" gorm:"index"`
+	DiscountType  DiscountType `json:"discount_type" gorm:"size:16"`
+	DiscountValue int64        `json:"discount_value"`
+	Currency      string       `json:"currency" gorm:"size:3"` // wajib untuk fixed
+	MaxUses       *int         `json:"max_uses"`               // nil = tanpa batas
+	ValidFrom     *time.Time   `json:"valid_from"`
+	ValidUntil    *time.Time   `json:"valid_until"`
+	CreatedBy     uint         `json:"created_by"`
+	CreatedAt     time.Time    `json:"created_at"`
+	UpdatedAt     time.Time    `json:"updated_at"`
+}
+
+// 📩 Request structs
+type CreateTicketTypeRequest struct {
+	Name        string     `json:"name" validate:"required,notblank,max=100"`
+	Description string     `json:"description" validate:"max=1000"`
+	Price       int64      `json:"price" validate:"gte=0"`
+	Currency    string     `json:"currency" validate:"required,len=3,alpha"`
+	Quota       int        `json:"quota" validate:"required,gt=0"`
+	SalesStart  *time.Time `json:"sales_start"`
+	SalesEnd    *time.Time `json:"sales_end"`
+}
+
+type UpdateTicketTypeRequest struct {
+	Name        *string    `json:"name" validate:"omitempty,notblank,max=100"`
+	Description *string    `json:"description" validate:"omitempty,max=1000"`
+	Price       *int64     `json:"price" validate:"omitempty,gte=0"`
+	Quota       *int       `json:"quota" validate:"omitempty,gt=0"`
+	SalesStart  *time.Time `json:"sales_start"`
+	SalesEnd    *time.Time `json:"sales_end"`
+}
+
+type CreateOrderRequest struct {
+	TicketTypeID uint                       `json:"ticket_type_id" validate:"required"`
+	Guests       []participant.GuestRequest `json:"guests" validate:"max=9,dive"` // participant.MaxGroupSize - 1
+	PromoCode    string                     `json:"promo_code" validate:"omitempty,max=50"`
+	Answers      regform.Answers            `json:"answers"`
+}
+
+type CreatePromoCodeRequest struct {
+	Code          string       `json:"code" validate:"required,alphanum,min=3,max=50"`
+	EventID       *uint        `json:"event_id" validate:"omitempty,gt=0"` // kosong = global (admin)
+	DiscountType  DiscountType `json:"discount_type" validate:"required,oneof=percentage fixed"`
+	DiscountValue int64        `json:"discount_value" validate:"required,gt=0"`
+	Currency      string       `json:"currency" validate:"required_if=DiscountType fixed,omitempty,len=3,alpha"`
+	MaxUses       *int         `json:"max_uses" validate:"omitempty,gt=0"`
+	ValidFrom     *time.Time   `json:"valid_from"`
+	ValidUntil    *time.Time   `json:"valid_until"`
+}
+
+type PayOrderRequest struct {
+	PaymentToken string `json:"payment_token" validate:"required,notblank,max=255"`
+}
+
+// 📤 Response structs
+type TicketTypeResponse struct {
+	ID          uint       `json:"id"`
+	EventID     uint       `json:"event_id"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Price       int64      `json:"price"`
+	Currency    string     `json:"currency"`
+	Quota       int        `json:"quota"`
+	Available   int        `json:"available"`
+	SalesStart  *time.Time `json:"sales_start"`
+	SalesEnd    *time.Time `json:"sales_end"`
+	OnSale      bool       `json:"on_sale"`
+}
+
+type OrderResponse struct {
+	ID           uint                       `json:"id"`
+	EventID      uint                       `json:"event_id"`
+	TicketTypeID uint                       `json:"ticket_type_id"`
+	TicketName   string                     `json:"ticket_name"`
+	Quantity     int                        `json:"quantity"`
+	UnitPrice    int64                      `json:"unit_price"`
+	Subtotal     int64                      `json:"subtotal"`
+	PromoCode    string                     `json:"promo_code,omitempty"`
+	Discount     int64                      `json:"discount_amount"`
+	TotalAmount  int64                      `json:"total_amount"`
+	Currency     string                     `json:"currency"`
+	Status       OrderStatus                `json:"status"`
+	ExpiresAt    time.Time                  `json:"expires_at"`
+	PaidAt       *time.Time                 `json:"paid_at"`
+	RefundedAt   *time.Time                 `json:"refunded_at"`
+	Guests       []participant.GuestRequest `json:"guests,omitempty"`
+	CreatedAt    time.Time                  `json:"created_at"`
+}
+
+// OrderQuote adalah rincian harga sebelum order dibuat
+type OrderQuote struct {
+	TicketTypeID uint   `json:"ticket_type_id"`
+	Quantity     int    `json:"quantity"`
+	UnitPrice    int64  `json:"unit_price"`
+	Subtotal     int64  `json:"subtotal"`
+	PromoCode    string `json:"promo_code,omitempty"`
+	Discount     int64  `json:"discount_amount"`
+	TotalAmount  int64  `json:"total_amount"`
+	Currency     string `json:"currency"`
+}
+
+type PromoCodeResponse struct {
+	PromoCode
+	Uses int `json:"uses"`
+}
+
+// ToResponse mengubah TicketType ke response, reserved = kursi yang sudah di-hold / terjual
+func (t *TicketType) ToResponse(reserved int, now time.Time) *TicketTypeResponse {
+	available := t.Quota - reserved
+	if available < 0 {
+		available = 0
+	}
+	return &TicketTypeResponse{
+		ID:          t.ID,
+		EventID:     t.EventID,
+		Name:        t.Name,
+		Description: t.Description,
+		Price:       t.Price,
+		Currency:    t.Currency,
+		Quota:       t.Quota,
+		Available:   available,
+		SalesStart:  t.SalesStart,
+		SalesEnd:    t.SalesEnd,
+		OnSale:      t.OnSale(now),
+	}
+}
+
+// OnSale true jika now berada di dalam sale window
+func (t *TicketType) OnSale(now time.Time) bool {
+	if t.SalesStart != nil && now.Before(*t.SalesStart) {
+		return false
+	}
+	if t.SalesEnd != nil && !now.Before(*t.SalesEnd) {
+		return false
+	}
+	return true
+}
+
+func (o *Order) ToResponse() *OrderResponse {
+	promoCode := ""
+	if o.PromoCode != nil {
+		promoCode = o.PromoCode.Code
+	}
+	return &OrderResponse{
+		ID:           o.ID,
+		EventID:      o.EventID,
+		TicketTypeID: o.TicketTypeID,
+		TicketName:   o.TicketType.Name,
+		Quantity:     o.Quantity,
+		UnitPrice:    o.UnitPrice,
+		Subtotal:     o.UnitPrice * int64(o.Quantity),
+		PromoCode:    promoCode,
+		Discount:     o.DiscountAmount,
+		TotalAmount:  o.TotalAmount,
+		Currency:     o.Currency,
+		Status:       o.Status,
+		ExpiresAt:    o.ExpiresAt,
+		PaidAt:       o.PaidAt,
+		RefundedAt:   o.RefundedAt,
+		Guests:       o.Guests,
+		CreatedAt:    o.CreatedAt,
+	}
+}
+
+// Discount menghitung potongan untuk subtotal, tidak pernah melebihi subtotal
+func (p *PromoCode) Discount(subtotal int64) int64 {
+	var discount int64
+	switch p.DiscountType {
+	case DiscountPercentage:
+		discount = subtotal * p.DiscountValue / 100
+	case DiscountFixed:
+		discount = p.DiscountValue
+	}
+	if discount > subtotal {
+		return subtotal
+	}
+	return discount
+}
+
+// ActiveAt true jika now berada di dalam masa berlaku promo
+func (p *PromoCode) ActiveAt(now time.Time) bool {
+	if p.ValidFrom != nil && now.Before(*p.ValidFrom) {
+		return false
+	}
+	if p.ValidUntil != nil && !now.Before(*p.ValidUntil) {
+		return false
+	}
+	return true
+}
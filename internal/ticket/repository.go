@@ -0,0 +1,326 @@
+package ticket
+
+import (
+	"go-event/internal/participant"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Repository interface {
+	CreateTicketType(ticketType *TicketType) error
+	GetTicketTypeByID(id uint) (*TicketType, error)
+	FindTicketTypesByEventID(eventID uint) ([]TicketType, error)
+	CountTicketTypesByEventID(eventID uint) (int64, error)
+	UpdateTicketType(ticketType *TicketType) error
+	DeleteTicketType(ticketType *TicketType) error
+	CountOrdersByTicketType(ticketTypeID uint) (int64, error)
+	ReservedSeats(ticketTypeIDs []uint, now time.Time) (map[uint]int, error)
+	WithTicketTypeLock(ticketTypeID uint, fn func(repo Repository, ticketType *TicketType) error) error
+	WithEventLock(eventID uint, fn func(repo Repository, participants participant.Repository) error) error
+
+	CreateOrder(order *Order) error
+	GetOrderByID(id uint) (*Order, error)
+	FindOrdersByUserID(userID uint) ([]Order, error)
+	FindPendingOrder(eventID, userID uint, now time.Time) (*Order, error)
+	FindExpiredPendingOrders(now time.Time) ([]Order, error)
+	FindOrdersByEventAndStatus(eventID uint, statuses ...OrderStatus) ([]Order, error)
+	FindStaleRefundPendingOrders(before time.Time) ([]Order, error)
+	UpdateOrderStatus(order *Order, from OrderStatus) (bool, error)
+	CompleteOrder(order *Order, p *participant.Participant) (bool, error)
+
+	CreatePromoCode(promo *PromoCode) error
+	GetPromoCodeByID(id uint) (*PromoCode, error)
+	GetPromoCodeByCode(code string) (*PromoCode, error)
+	FindPromoCodes(eventID *uint) ([]PromoCode, error)
+	UpdatePromoCode(promo *PromoCode) error
+	DeletePromoCode(promo *PromoCode) error
+	LockPromoCode(id uint) error
+	PromoCodeUses(promoIDs []uint, now time.Time) (map[uint]int, error)
+	CountOrdersByPromoCode(promoID uint) (int64, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+// CreateTicketType implements Repository.
+func (r *repository) CreateTicketType(ticketType *TicketType) error {
+	return r.db.Create(ticketType).Error
+}
+
+// GetTicketTypeByID implements Repository.
+func (r *repository) GetTicketTypeByID(id uint) (*TicketType, error) {
+	var ticketType TicketType
+	if err := r.db.Where("id = ?", id).First(&ticketType).Error; err != nil {
+		return nil, err
+	}
+	return &ticketType, nil
+}
+
+// FindTicketTypesByEventID implements Repository.
+func (r *repository) FindTicketTypesByEventID(eventID uint) ([]TicketType, error) {
+	var ticketTypes []TicketType
+	err := r.db.Where("event_id = ?", eventID).Order("price asc, id asc").Find(&ticketTypes).Error
+	return ticketTypes, err
+}
+
+// CountTicketTypesByEventID implements Repository.
+func (r *repository) CountTicketTypesByEventID(eventID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&TicketType{}).Where("event_id = ?", eventID).Count(&count).Error
+	return count, err
+}
+
+// UpdateTicketType implements Repository.
+func (r *repository) UpdateTicketType(ticketType *TicketType) error {
+	return r.db.Save(ticketType).Error
+}
+
+// DeleteTicketType implements Repository.
+func (r *repository) DeleteTicketType(ticketType *TicketType) error {
+	return r.db.Delete(ticketType).Error
+}
+
+// CountOrdersByTicketType implements Repository.
+func (r *repository) CountOrdersByTicketType(ticketTypeID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&Order{}).Where("ticket_type_id = ?", ticketTypeID).Count(&count).Error
+	return count, err
+}
+
+// ReservedSeats implements Repository.
+// Kursi terpakai = quantity order paid + order pending yang belum expired, per ticket type
+func (r *repository) ReservedSeats(ticketTypeIDs []uint, now time.Time) (map[uint]int, error) {
+	reserved := make(map[uint]int, len(ticketTypeIDs))
+	if len(ticketTypeIDs) == 0 {
+		return reserved, nil
+	}
+
+	var rows []struct {
+		TicketTypeID uint
+		Seats        int
+	}
+	err := r.db.Model(&Order{}).
+		Select("ticket_type_id, SUM(quantity) AS seats").
+		Where("ticket_type_id IN ?", ticketTypeIDs).
+		Where("status = ? OR (status = ? AND expires_at > ?)", OrderPaid, OrderPending, now).
+		Group("ticket_type_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		reserved[row.TicketTypeID] = row.Seats
+	}
+	return reserved, nil
+}
+
+// WithTicketTypeLock implements Repository.
+// Mengunci baris ticket type (SELECT ... FOR UPDATE) selama fn berjalan agar
+// hitung sisa kuota + buat order tidak bisa oversell saat request paralel
+func (r *repository) WithTicketTypeLock(ticketTypeID uint, fn func(repo Repository, ticketType *TicketType) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var ticketType TicketType
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", ticketTypeID).First(&ticketType).Error; err != nil {
+			return err
+		}
+		return fn(&repository{db: tx}, &ticketType)
+	})
+}
+
+// WithEventLock implements Repository.
+// Mengunci baris event lewat participant.Repository.WithEventLock sehingga order yang
+// lunas dan registrasi gratis paralel tidak bisa bersama-sama melebihi capacity
+func (r *repository) WithEventLock(eventID uint, fn func(repo Repository, participants participant.Repository) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return participant.Newrepository(tx).WithEventLock(eventID, func(participants participant.Repository) error {
+			return fn(&repository{db: tx}, participants)
+		})
+	})
+}
+
+// CreateOrder implements Repository.
+func (r *repository) CreateOrder(order *Order) error {
+	return r.db.Create(order).Error
+}
+
+// GetOrderByID implements Repository.
+func (r *repository) GetOrderByID(id uint) (*Order, error) {
+	var order Order
+	if err := r.db.Preload("TicketType").Preload("PromoCode").Where("id = ?", id).First(&order).Error; err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// FindOrdersByUserID implements Repository.
+func (r *repository) FindOrdersByUserID(userID uint) ([]Order, error) {
+	var orders []Order
+	err := r.db.Preload("TicketType").Preload("PromoCode").Where("user_id = ?", userID).Order("created_at desc").Find(&orders).Error
+	return orders, err
+}
+
+// FindPendingOrder implements Repository.
+// Order pending milik user untuk event tertentu yang belum expired, nil jika tidak ada
+func (r *repository) FindPendingOrder(eventID, userID uint, now time.Time) (*Order, error) {
+	var orders []Order
+	err := r.db.Preload("TicketType").
+		Where("event_id = ? AND user_id = ? AND status = ? AND expires_at > ?", eventID, userID, OrderPending, now).
+		Limit(1).
+		Find(&orders).Error
+	if err != nil || len(orders) == 0 {
+		return nil, err
+	}
+	return &orders[0], nil
+}
+
+// FindExpiredPendingOrders implements Repository.
+func (r *repository) FindExpiredPendingOrders(now time.Time) ([]Order, error) {
+	var orders []Order
+	err := r.db.Where("status = ? AND expires_at <= ?", OrderPending, now).Find(&orders).Error
+	return orders, err
+}
+
+// FindOrdersByEventAndStatus implements Repository.
+func (r *repository) FindOrdersByEventAndStatus(eventID uint, statuses ...OrderStatus) ([]Order, error) {
+	var orders []Order
+	err := r.db.Where("event_id = ? AND status IN ?", eventID, statuses).Find(&orders).Error
+	return orders, err
+}
+
+// FindStaleRefundPendingOrders implements Repository.
+// Order refund_pending yang terakhir diubah sebelum `before`, yang lebih baru bisa jadi
+// masih diproses oleh request lain
+func (r *repository) FindStaleRefundPendingOrders(before time.Time) ([]Order, error) {
+	var orders []Order
+	err := r.db.Where("status = ? AND updated_at <= ?", OrderRefundPending, before).Find(&orders).Error
+	return orders, err
+}
+
+// UpdateOrderStatus implements Repository.
+// Menyimpan order hanya jika status di database masih `from`, return false jika
+// order sudah diubah oleh proses lain (misalnya expired oleh scheduler)
+func (r *repository) UpdateOrderStatus(order *Order, from OrderStatus) (bool, error) {
+	result := r.db.Model(&Order{}).
+		Where("id = ? AND status = ?", order.ID, from).
+		Updates(map[string]interface{}{
+			"status":      order.Status,
+			"payment_ref": order.PaymentRef,
+			"paid_at":     order.PaidAt,
+			"refunded_at": order.RefundedAt,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// CompleteOrder implements Repository.
+// Menandai order paid dan membuat participant dalam satu transaksi
+func (r *repository) CompleteOrder(order *Order, p *participant.Participant) (bool, error) {
+	completed := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		ok, err := (&repository{db: tx}).UpdateOrderStatus(order, OrderPending)
+		if err != nil || !ok {
+			return err
+		}
+		if err := participant.Newrepository(tx).Register(p); err != nil {
+			return err
+		}
+		completed = true
+		return nil
+	})
+	return completed, err
+}
+
+// CreatePromoCode implements Repository.
+func (r *repository) CreatePromoCode(promo *PromoCode) error {
+	return r.db.Create(promo).Error
+}
+
+// GetPromoCodeByID implements Repository.
+func (r *repository) GetPromoCodeByID(id uint) (*PromoCode, error) {
+	var promo PromoCode
+	if err := r.db.Where("id = ?", id).First(&promo).Error; err != nil {
+		return nil, err
+	}
+	return &promo, nil
+}
+
+// GetPromoCodeByCode implements Repository.
+func (r *repository) GetPromoCodeByCode(code string) (*PromoCode, error) {
+	var promo PromoCode
+	if err := r.db.Where("code = ?", code).First(&promo).Error; err != nil {
+		return nil, err
+	}
+	return &promo, nil
+}
+
+// FindPromoCodes implements Repository.
+// eventID nil mengembalikan promo global
+func (r *repository) FindPromoCodes(eventID *uint) ([]PromoCode, error) {
+	var promos []PromoCode
+	query := r.db.Order("created_at desc")
+	if eventID == nil {
+		query = query.Where("event_id IS NULL")
+	} else {
+		query = query.Where("event_id = ?", *eventID)
+	}
+	err := query.Find(&promos).Error
+	return promos, err
+}
+
+// UpdatePromoCode implements Repository.
+func (r *repository) UpdatePromoCode(promo *PromoCode) error {
+	return r.db.Save(promo).Error
+}
+
+// DeletePromoCode implements Repository.
+func (r *repository) DeletePromoCode(promo *PromoCode) error {
+	return r.db.Delete(promo).Error
+}
+
+// LockPromoCode implements Repository.
+// Hanya bermakna di dalam WithTicketTypeLock (transaksi), mengunci baris promo
+// agar batas pemakaian tidak terlewati oleh order paralel
+func (r *repository) LockPromoCode(id uint) error {
+	var promo PromoCode
+	return r.db.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&promo).Error
+}
+
+// PromoCodeUses implements Repository.
+// Pemakaian = order paid + order pending yang belum expired, per promo code
+func (r *repository) PromoCodeUses(promoIDs []uint, now time.Time) (map[uint]int, error) {
+	uses := make(map[uint]int, len(promoIDs))
+	if len(promoIDs) == 0 {
+		return uses, nil
+	}
+
+	var rows []struct {
+		PromoCodeID uint
+		Uses        int
+	}
+	err := r.db.Model(&Order{}).
+		Select("promo_code_id, COUNT(*) AS uses").
+		Where("promo_code_id IN ?", promoIDs).
+		Where("status = ? OR (status = ? AND expires_at > ?)", OrderPaid, OrderPending, now).
+		Group("promo_code_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		uses[row.PromoCodeID] = row.Uses
+	}
+	return uses, nil
+}
+
+// CountOrdersByPromoCode implements Repository.
+func (r *repository) CountOrdersByPromoCode(promoID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&Order{}).Where("promo_code_id = ?", promoID).Count(&count).Error
+	return count, err
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
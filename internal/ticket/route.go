@@ -0,0 +1,31 @@
+package ticket
+
+import (
+	"go-event/pkg/config"
+	"go-event/pkg/idempotency"
+	"go-event/pkg/middlewares"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func SetupTicketRoutes(app *fiber.App, ctrl *Controller, cfg *config.Config, idempotencyStore idempotency.Repository) {
+	tickets := app.Group("/api/ticket")
+	tickets.Get("/event/:id", middlewares.Authenticate(cfg), ctrl.GetTicketTypes)
+	tickets.Post("/event/:id", middlewares.Authenticate(cfg), middlewares.Authorize("organizer"), ctrl.CreateTicketType)
+	tickets.Put("/:id", middlewares.Authenticate(cfg), middlewares.Authorize("organizer"), ctrl.UpdateTicketType)
+	tickets.Delete("/:id", middlewares.Authenticate(cfg), middlewares.Authorize("organizer"), ctrl.DeleteTicketType)
+
+	orders := app.Group("/api/order")
+	orders.Post("/", middlewares.Authenticate(cfg), middlewares.Idempotency(idempotencyStore), ctrl.CreateOrder)
+	orders.Post("/quote", middlewares.Authenticate(cfg), ctrl.QuoteOrder)
+	orders.Get("/", middlewares.Authenticate(cfg), ctrl.GetMyOrders)
+	orders.Get("/:id", middlewares.Authenticate(cfg), ctrl.GetOrder)
+	orders.Post("/:id/pay", middlewares.Authenticate(cfg), middlewares.Idempotency(idempotencyStore), ctrl.PayOrder)
+	orders.Post("/:id/cancel", middlewares.Authenticate(cfg), ctrl.CancelOrder)
+
+	promos := app.Group("/api/promo")
+	promos.Post("/", middlewares.Authenticate(cfg), middlewares.Authorize("organizer", "admin"), ctrl.CreatePromoCode)
+	promos.Get("/", middlewares.Authenticate(cfg), middlewares.Authorize("organizer", "admin"), ctrl.GetPromoCodes)
+	promos.Post("/:id/deactivate", middlewares.Authenticate(cfg), middlewares.Authorize("organizer", "admin"), ctrl.DeactivatePromoCode)
+	promos.Delete("/:id", middlewares.Authenticate(cfg), middlewares.Authorize("organizer", "admin"), ctrl.DeletePromoCode)
+}
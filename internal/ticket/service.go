@@ -0,0 +1,968 @@
+package ticket
+
+import (
+	"errors"
+	"fmt"
+	"go-event/internal/event"
+	"go-event/internal/eventbus"
+	"go-event/internal/notification/email"
+	"go-event/internal/participant"
+	"go-event/internal/payment"
+	"go-event/internal/user"
+	"go-event/pkg/apperror"
+	"go-event/pkg/config"
+	"go-event/pkg/i18n"
+	"go-event/pkg/regform"
+	"go-event/pkg/timezone"
+	"go-event/pkg/validation"
+	"log"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// defaultOrderHold dipakai jika ORDER_HOLD_DURATION tidak valid
+	defaultOrderHold = 15 * time.Minute
+	// refundRetryDelay adalah jeda sebelum order refund_pending dicoba ulang
+	refundRetryDelay = 5 * time.Minute
+)
+
+type Service interface {
+	CreateTicketType(userID, eventID uint, req *CreateTicketTypeRequest) (*TicketTypeResponse, error)
+	GetTicketTypes(eventID uint) ([]TicketTypeResponse, error)
+	UpdateTicketType(userID, ticketTypeID uint, req *UpdateTicketTypeRequest) (*TicketTypeResponse, error)
+	DeleteTicketType(userID, ticketTypeID uint) error
+
+	CreatePromoCode(userID uint, userRole string, req *CreatePromoCodeRequest) (*PromoCodeResponse, error)
+	GetPromoCodes(userID uint, userRole string, eventID *uint) ([]PromoCodeResponse, error)
+	DeactivatePromoCode(userID uint, userRole string, promoID uint) (*PromoCodeResponse, error)
+	DeletePromoCode(userID uint, userRole string, promoID uint) error
+
+	QuoteOrder(req *CreateOrderRequest) (*OrderQuote, error)
+	CreateOrder(userID uint, req *CreateOrderRequest) (*OrderResponse, error)
+	PayOrder(userID, orderID uint, req *PayOrderRequest) (*OrderResponse, error)
+	CancelOrder(userID, orderID uint) (*OrderResponse, error)
+	GetOrder(userID, orderID uint) (*OrderResponse, error)
+	GetOrdersByUserID(userID uint) ([]OrderResponse, error)
+
+	HasTicketTypes(eventID uint) (bool, error)
+	RefundOrder(orderID uint) error
+	RefundEventOrders(eventID uint) error
+	OnEventCancelled(e eventbus.EventCancelled) error
+	ExpireOrders(now time.Time) error
+	RetryRefunds(now time.Time) error
+}
+
+type service struct {
+	repo            Repository
+	eventRepo       event.Repository
+	participantRepo participant.Repository
+	userRepo        user.Repository
+	provider        payment.Provider
+	emailService    email.Service
+	bus             eventbus.Publisher
+	orderHold       time.Duration
+	cfg             *config.Config
+}
+
+// CreateTicketType implements Service.
+func (s *service) CreateTicketType(userID, eventID uint, req *CreateTicketTypeRequest) (*TicketTypeResponse, error) {
+	if _, err := s.getManagedEvent(userID, eventID); err != nil {
+		return nil, err
+	}
+	if err := validateSaleWindow(req.SalesStart, req.SalesEnd); err != nil {
+		return nil, err
+	}
+
+	ticketType := &TicketType{
+		EventID:     eventID,
+		Name:        req.Name,
+		Description: req.Description,
+		Price:       req.Price,
+		Currency:    strings.ToUpper(req.Currency),
+		Quota:       req.Quota,
+		SalesStart:  req.SalesStart,
+		SalesEnd:    req.SalesEnd,
+	}
+	if err := s.repo.CreateTicketType(ticketType); err != nil {
+		return nil, apperror.Internal(fmt.Errorf("failed to create ticket type: %w", err))
+	}
+	return ticketType.ToResponse(0, time.Now()), nil
+}
+
+// GetTicketTypes implements Service.
+func (s *service) GetTicketTypes(eventID uint) ([]TicketTypeResponse, error) {
+	if _, err := s.eventRepo.GetByID(eventID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrEventNotFound
+		}
+		return nil, apperror.Internal(err)
+	}
+
+	ticketTypes, err := s.repo.FindTicketTypesByEventID(eventID)
+	if err != nil {
+		return nil, apperror.Internal(err)
+	}
+
+	now := time.Now()
+	ids := make([]uint, 0, len(ticketTypes))
+	for _, t := range ticketTypes {
+		ids = append(ids, t.ID)
+	}
+	reserved, err := s.repo.ReservedSeats(ids, now)
+	if err != nil {
+		return nil, apperror.Internal(err)
+	}
+
+	responses := make([]TicketTypeResponse, 0, len(ticketTypes))
+	for _, t := range ticketTypes {
+		responses = append(responses, *t.ToResponse(reserved[t.ID], now))
+	}
+	return responses, nil
+}
+
+// UpdateTicketType implements Service.
+// Perubahan harga hanya berlaku untuk order baru, order lama menyimpan UnitPrice sendiri
+func (s *service) UpdateTicketType(userID, ticketTypeID uint, req *UpdateTicketTypeRequest) (*TicketTypeResponse, error) {
+	ticketType, err := s.getManagedTicketType(userID, ticketTypeID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		ticketType.Name = *req.Name
+	}
+	if req.Description != nil {
+		ticketType.Description = *req.Description
+	}
+	if req.Price != nil {
+		ticketType.Price = *req.Price
+	}
+	if req.SalesStart != nil {
+		ticketType.SalesStart = req.SalesStart
+	}
+	if req.SalesEnd != nil {
+		ticketType.SalesEnd = req.SalesEnd
+	}
+	if err := validateSaleWindow(ticketType.SalesStart, ticketType.SalesEnd); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var reserved int
+	err = s.repo.WithTicketTypeLock(ticketType.ID, func(repo Repository, _ *TicketType) error {
+		seats, err := repo.ReservedSeats([]uint{ticketType.ID}, now)
+		if err != nil {
+			return err
+		}
+		reserved = seats[ticketType.ID]
+		if req.Quota != nil {
+			if *req.Quota < reserved {
+				return ErrQuotaBelowReserved.WithDetails(map[string]int{"reserved": reserved})
+			}
+			ticketType.Quota = *req.Quota
+		}
+		return repo.UpdateTicketType(ticketType)
+	})
+	if err != nil {
+		return nil, wrapInternal(err)
+	}
+	return ticketType.ToResponse(reserved, now), nil
+}
+
+// DeleteTicketType implements Service.
+// Ticket type yang sudah pernah dipesan tidak bisa dihapus agar riwayat order tetap utuh
+func (s *service) DeleteTicketType(userID, ticketTypeID uint) error {
+	ticketType, err := s.getManagedTicketType(userID, ticketTypeID)
+	if err != nil {
+		return err
+	}
+	count, err := s.repo.CountOrdersByTicketType(ticketType.ID)
+	if err != nil {
+		return apperror.Internal(err)
+	}
+	if count > 0 {
+		return ErrTicketTypeInUse
+	}
+	if err := s.repo.DeleteTicketType(ticketType); err != nil {
+		return apperror.Internal(err)
+	}
+	return nil
+}
+
+// QuoteOrder implements Service.
+// Menghitung harga (termasuk promo) tanpa membuat order / menahan kursi
+func (s *service) QuoteOrder(req *CreateOrderRequest) (*OrderQuote, error) {
+	now := time.Now()
+	ticketType, _, err := s.getOrderableTicketType(req.TicketTypeID, now)
+	if err != nil {
+		return nil, err
+	}
+	quote, promo, err := s.quote(ticketType, req, now)
+	if err != nil {
+		return nil, err
+	}
+	if promo != nil {
+		if err := s.checkPromoUsage(s.repo, promo, now); err != nil {
+			return nil, err
+		}
+	}
+	return quote, nil
+}
+
+// CreateOrder implements Service.
+// Order menahan 1 + jumlah guest kursi selama orderHold. Order dengan total 0
+// (ticket gratis / diskon penuh) langsung dianggap lunas
+func (s *service) CreateOrder(userID uint, req *CreateOrderRequest) (*OrderResponse, error) {
+	now := time.Now()
+	ticketType, ev, err := s.getOrderableTicketType(req.TicketTypeID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.participantRepo.FindByEventAndUser(ev.ID, userID)
+	if err != nil {
+		return nil, apperror.Internal(err)
+	}
+	if existing != nil {
+		switch {
+		case existing.Status == participant.StatusRejected:
+			return nil, participant.ErrRegistrationRejected
+		case existing.Status.IsActive():
+			return nil, ErrAlreadyRegistered
+		}
+	}
+	pending, err := s.repo.FindPendingOrder(ev.ID, userID, now)
+	if err != nil {
+		return nil, apperror.Internal(err)
+	}
+	if pending != nil {
+		return nil, ErrOrderPending.WithDetails(pending.ToResponse())
+	}
+
+	quote, promo, err := s.quote(ticketType, req, now)
+	if err != nil {
+		return nil, err
+	}
+	answers, err := regform.ValidateAnswers(ev.RegistrationForm, req.Answers)
+	if err != nil {
+		return nil, err
+	}
+
+	order := &Order{
+		EventID:        ev.ID,
+		UserID:         userID,
+		TicketTypeID:   ticketType.ID,
+		Quantity:       quote.Quantity,
+		Guests:         req.Guests,
+		Answers:        answers,
+		UnitPrice:      quote.UnitPrice,
+		DiscountAmount: quote.Discount,
+		TotalAmount:    quote.TotalAmount,
+		Currency:       quote.Currency,
+		Status:         OrderPending,
+		ExpiresAt:      now.Add(s.orderHold),
+	}
+	if promo != nil {
+		order.PromoCodeID = &promo.ID
+	}
+
+	err = s.repo.WithTicketTypeLock(ticketType.ID, func(repo Repository, locked *TicketType) error {
+		seats, err := repo.ReservedSeats([]uint{locked.ID}, now)
+		if err != nil {
+			return err
+		}
+		if available := locked.Quota - seats[locked.ID]; available < order.Quantity {
+			return ErrSoldOut.WithDetails(map[string]int{"available": available})
+		}
+		if promo != nil {
+			if err := repo.LockPromoCode(promo.ID); err != nil {
+				return err
+			}
+			if err := s.checkPromoUsage(repo, promo, now); err != nil {
+				return err
+			}
+		}
+		return repo.CreateOrder(order)
+	})
+	if err != nil {
+		return nil, wrapInternal(err)
+	}
+	order.TicketType = *ticketType
+	order.PromoCode = promo
+
+	if order.TotalAmount == 0 {
+		if err := s.completeOrder(order, ""); err != nil {
+			return nil, err
+		}
+	}
+	return order.ToResponse(), nil
+}
+
+// PayOrder implements Service.
+func (s *service) PayOrder(userID, orderID uint, req *PayOrderRequest) (*OrderResponse, error) {
+	order, err := s.getOwnedOrder(userID, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status != OrderPending {
+		return nil, ErrOrderNotPending
+	}
+	if !order.ExpiresAt.After(time.Now()) {
+		s.expireOrder(order)
+		return nil, ErrOrderExpired
+	}
+
+	charge, err := s.provider.Charge(payment.ChargeRequest{
+		OrderID:      order.ID,
+		Amount:       order.TotalAmount,
+		Currency:     order.Currency,
+		Description:  fmt.Sprintf("Order #%d - %s", order.ID, order.TicketType.Name),
+		PaymentToken: req.PaymentToken,
+	})
+	if err != nil {
+		return nil, apperror.Internal(fmt.Errorf("payment provider charge failed: %w", err))
+	}
+	if charge.Status != payment.ChargeSucceeded {
+		// Order tetap pending, user bisa mencoba metode lain sampai ExpiresAt
+		return nil, ErrPaymentDeclined.WithDetails(map[string]string{"reason": charge.FailureReason})
+	}
+
+	if err := s.completeOrder(order, charge.ID); err != nil {
+		return nil, err
+	}
+	return order.ToResponse(), nil
+}
+
+// CancelOrder implements Service.
+// Hanya order yang belum dibayar; order lunas dibatalkan lewat pembatalan participant
+func (s *service) CancelOrder(userID, orderID uint) (*OrderResponse, error) {
+	order, err := s.getOwnedOrder(userID, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status != OrderPending {
+		return nil, ErrOrderNotPending
+	}
+
+	order.Status = OrderCancelled
+	ok, err := s.repo.UpdateOrderStatus(order, OrderPending)
+	if err != nil {
+		return nil, apperror.Internal(err)
+	}
+	if !ok {
+		return nil, ErrOrderNotPending
+	}
+	return order.ToResponse(), nil
+}
+
+// GetOrder implements Service.
+func (s *service) GetOrder(userID, orderID uint) (*OrderResponse, error) {
+	order, err := s.getOwnedOrder(userID, orderID)
+	if err != nil {
+		return nil, err
+	}
+	return order.ToResponse(), nil
+}
+
+// GetOrdersByUserID implements Service.
+func (s *service) GetOrdersByUserID(userID uint) ([]OrderResponse, error) {
+	orders, err := s.repo.FindOrdersByUserID(userID)
+	if err != nil {
+		return nil, apperror.Internal(err)
+	}
+	responses := make([]OrderResponse, 0, len(orders))
+	for _, o := range orders {
+		responses = append(responses, *o.ToResponse())
+	}
+	return responses, nil
+}
+
+// HasTicketTypes implements Service.
+func (s *service) HasTicketTypes(eventID uint) (bool, error) {
+	count, err := s.repo.CountTicketTypesByEventID(eventID)
+	return count > 0, err
+}
+
+// RefundOrder implements Service.
+// Dipanggil saat participant membatalkan pendaftaran, dana dikembalikan lewat provider
+func (s *service) RefundOrder(orderID uint) error {
+	order, err := s.repo.GetOrderByID(orderID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrOrderNotFound
+		}
+		return apperror.Internal(err)
+	}
+	return s.refund(order)
+}
+
+// RefundEventOrders implements Service.
+// Dipanggil saat event dibatalkan: order lunas di-refund, order pending dibatalkan.
+// Refund yang gagal tersimpan sebagai refund_pending dan dicoba ulang RetryRefunds
+func (s *service) RefundEventOrders(eventID uint) error {
+	orders, err := s.repo.FindOrdersByEventAndStatus(eventID, OrderPaid, OrderPending)
+	if err != nil {
+		return err
+	}
+
+	var failed int
+	for i := range orders {
+		order := &orders[i]
+		if order.Status == OrderPending {
+			order.Status = OrderCancelled
+			if _, err := s.repo.UpdateOrderStatus(order, OrderPending); err != nil {
+				log.Printf("ticket: failed to cancel pending order %d: %v", order.ID, err)
+				failed++
+			}
+			continue
+		}
+		if err := s.refund(order); err != nil {
+			log.Printf("ticket: failed to refund order %d, will retry: %v", order.ID, err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d orders for event %d could not be refunded", failed, len(orders), eventID)
+	}
+	return nil
+}
+
+// OnEventCancelled implements Service (subscriber EventCancelled).
+func (s *service) OnEventCancelled(e eventbus.EventCancelled) error {
+	return s.RefundEventOrders(e.Event.ID)
+}
+
+// ExpireOrders implements Service.
+// Dipanggil scheduler: order pending yang melewati ExpiresAt dilepas kursinya
+func (s *service) ExpireOrders(now time.Time) error {
+	orders, err := s.repo.FindExpiredPendingOrders(now)
+	if err != nil {
+		return fmt.Errorf("failed to get expired orders: %w", err)
+	}
+	for i := range orders {
+		s.expireOrder(&orders[i])
+	}
+	return nil
+}
+
+// RetryRefunds implements Service.
+// Dipanggil scheduler: mencoba ulang refund order yang sebelumnya gagal
+func (s *service) RetryRefunds(now time.Time) error {
+	orders, err := s.repo.FindStaleRefundPendingOrders(now.Add(-refundRetryDelay))
+	if err != nil {
+		return fmt.Errorf("failed to get refund pending orders: %w", err)
+	}
+	for i := range orders {
+		if err := s.refund(&orders[i]); err != nil {
+			log.Printf("ticket: refund retry for order %d failed: %v", orders[i].ID, err)
+		}
+	}
+	return nil
+}
+
+// CreatePromoCode implements Service.
+// Organizer membuat promo untuk event miliknya, promo global (tanpa event_id) hanya admin
+func (s *service) CreatePromoCode(userID uint, userRole string, req *CreatePromoCodeRequest) (*PromoCodeResponse, error) {
+	if err := s.checkPromoScope(userID, userRole, req.EventID); err != nil {
+		return nil, err
+	}
+	if req.DiscountType == DiscountPercentage && req.DiscountValue > 100 {
+		return nil, validation.NewError("discount_value", "lte", "100", "must be less than or equal to 100")
+	}
+	if req.ValidFrom != nil && req.ValidUntil != nil && !req.ValidUntil.After(*req.ValidFrom) {
+		return nil, validation.NewError("valid_until", "gtfield", "valid_from", "must be after valid_from")
+	}
+
+	code := strings.ToUpper(req.Code)
+	if _, err := s.repo.GetPromoCodeByCode(code); err == nil {
+		return nil, ErrPromoCodeExists
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, apperror.Internal(err)
+	}
+
+	promo := &PromoCode{
+		Code:          code,
+		EventID:       req.EventID,
+		DiscountType:  req.DiscountType,
+		DiscountValue: req.DiscountValue,
+		MaxUses:       req.MaxUses,
+		ValidFrom:     req.ValidFrom,
+		ValidUntil:    req.ValidUntil,
+		CreatedBy:     userID,
+	}
+	if req.DiscountType == DiscountFixed {
+		promo.Currency = strings.ToUpper(req.Currency)
+	}
+	if err := s.repo.CreatePromoCode(promo); err != nil {
+		return nil, apperror.Internal(fmt.Errorf("failed to create promo code: %w", err))
+	}
+	return &PromoCodeResponse{PromoCode: *promo}, nil
+}
+
+// GetPromoCodes implements Service.
+func (s *service) GetPromoCodes(userID uint, userRole string, eventID *uint) ([]PromoCodeResponse, error) {
+	if err := s.checkPromoScope(userID, userRole, eventID); err != nil {
+		return nil, err
+	}
+	promos, err := s.repo.FindPromoCodes(eventID)
+	if err != nil {
+		return nil, apperror.Internal(err)
+	}
+
+	ids := make([]uint, 0, len(promos))
+	for _, p := range promos {
+		ids = append(ids, p.ID)
+	}
+	uses, err := s.repo.PromoCodeUses(ids, time.Now())
+	if err != nil {
+		return nil, apperror.Internal(err)
+	}
+
+	responses := make([]PromoCodeResponse, 0, len(promos))
+	for _, p := range promos {
+		responses = append(responses, PromoCodeResponse{PromoCode: p, Uses: uses[p.ID]})
+	}
+	return responses, nil
+}
+
+// DeactivatePromoCode implements Service.
+// Promo yang sudah dipakai tidak bisa dihapus, cukup diakhiri masa berlakunya
+func (s *service) DeactivatePromoCode(userID uint, userRole string, promoID uint) (*PromoCodeResponse, error) {
+	promo, err := s.getManagedPromoCode(userID, userRole, promoID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	promo.ValidUntil = &now
+	if err := s.repo.UpdatePromoCode(promo); err != nil {
+		return nil, apperror.Internal(err)
+	}
+	return &PromoCodeResponse{PromoCode: *promo}, nil
+}
+
+// DeletePromoCode implements Service.
+func (s *service) DeletePromoCode(userID uint, userRole string, promoID uint) error {
+	promo, err := s.getManagedPromoCode(userID, userRole, promoID)
+	if err != nil {
+		return err
+	}
+	count, err := s.repo.CountOrdersByPromoCode(promo.ID)
+	if err != nil {
+		return apperror.Internal(err)
+	}
+	if count > 0 {
+		return ErrPromoCodeInUse
+	}
+	if err := s.repo.DeletePromoCode(promo); err != nil {
+		return apperror.Internal(err)
+	}
+	return nil
+}
+
+// quote menghitung harga order dan memvalidasi promo code (tanpa cek batas pemakaian)
+func (s *service) quote(ticketType *TicketType, req *CreateOrderRequest, now time.Time) (*OrderQuote, *PromoCode, error) {
+	quantity := 1 + len(req.Guests)
+	subtotal := ticketType.Price * int64(quantity)
+	quote := &OrderQuote{
+		TicketTypeID: ticketType.ID,
+		Quantity:     quantity,
+		UnitPrice:    ticketType.Price,
+		Subtotal:     subtotal,
+		TotalAmount:  subtotal,
+		Currency:     ticketType.Currency,
+	}
+	if strings.TrimSpace(req.PromoCode) == "" {
+		return quote, nil, nil
+	}
+
+	promo, err := s.repo.GetPromoCodeByCode(strings.ToUpper(strings.TrimSpace(req.PromoCode)))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, validation.NewError("promo_code", "valid", "", "is not a valid promo code")
+		}
+		return nil, nil, apperror.Internal(err)
+	}
+	if promo.EventID != nil && *promo.EventID != ticketType.EventID {
+		return nil, nil, validation.NewError("promo_code", "valid", "", "is not valid for this event")
+	}
+	if !promo.ActiveAt(now) {
+		return nil, nil, validation.NewError("promo_code", "active", "", "is expired or not active yet")
+	}
+	if promo.DiscountType == DiscountFixed && promo.Currency != ticketType.Currency {
+		return nil, nil, validation.NewError("promo_code", "currency", promo.Currency, "is only valid for "+promo.Currency+" tickets")
+	}
+
+	quote.PromoCode = promo.Code
+	quote.Discount = promo.Discount(subtotal)
+	quote.TotalAmount = subtotal - quote.Discount
+	return quote, promo, nil
+}
+
+// checkPromoUsage memastikan promo belum mencapai MaxUses. repo bisa berupa repo
+// transaksi (di dalam lock) agar hasilnya konsisten dengan order yang akan dibuat
+func (s *service) checkPromoUsage(repo Repository, promo *PromoCode, now time.Time) error {
+	if promo.MaxUses == nil {
+		return nil
+	}
+	uses, err := repo.PromoCodeUses([]uint{promo.ID}, now)
+	if err != nil {
+		return err
+	}
+	if uses[promo.ID] >= *promo.MaxUses {
+		return ErrPromoCodeExhausted
+	}
+	return nil
+}
+
+// checkPromoScope: promo event hanya untuk organizer event tsb (atau admin), promo global hanya admin
+func (s *service) checkPromoScope(userID uint, userRole string, eventID *uint) error {
+	if eventID == nil {
+		if userRole != "admin" {
+			return ErrPromoForbidden
+		}
+		return nil
+	}
+	ev, err := s.eventRepo.GetByID(*eventID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrEventNotFound
+		}
+		return apperror.Internal(err)
+	}
+	if userRole != "admin" && ev.OrganizerID != userID {
+		return ErrPromoForbidden
+	}
+	return nil
+}
+
+func (s *service) getManagedPromoCode(userID uint, userRole string, promoID uint) (*PromoCode, error) {
+	promo, err := s.repo.GetPromoCodeByID(promoID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPromoCodeNotFound
+		}
+		return nil, apperror.Internal(err)
+	}
+	if err := s.checkPromoScope(userID, userRole, promo.EventID); err != nil {
+		return nil, err
+	}
+	return promo, nil
+}
+
+// getOrderableTicketType memastikan ticket ada, event membuka pendaftaran, dan ticket dalam sale window
+func (s *service) getOrderableTicketType(ticketTypeID uint, now time.Time) (*TicketType, *event.Event, error) {
+	ticketType, err := s.getTicketType(ticketTypeID)
+	if err != nil {
+		return nil, nil, err
+	}
+	ev, err := s.eventRepo.GetByID(ticketType.EventID)
+	if err != nil {
+		return nil, nil, apperror.Internal(err)
+	}
+	if ev.Status != event.StatusPublished || !ev.StartTime.After(now) {
+		return nil, nil, ErrRegistrationClosed
+	}
+	if !ticketType.OnSale(now) {
+		return nil, nil, ErrNotOnSale
+	}
+	return ticketType, ev, nil
+}
+
+// completeOrder menandai order lunas dan membuat participant. Jika order ternyata
+// sudah tidak pending (expired di tengah pembayaran) atau event sudah penuh, charge
+// langsung di-refund
+func (s *service) completeOrder(order *Order, chargeID string) error {
+	now := time.Now()
+	order.Status = OrderPaid
+	order.PaymentRef = chargeID
+	order.PaidAt = &now
+
+	guests, err := participant.NewGuests(order.EventID, order.Guests)
+	if err != nil {
+		return apperror.Internal(err)
+	}
+	orderID := order.ID
+	p := &participant.Participant{
+		EventID:   order.EventID,
+		UserID:    order.UserID,
+		OrderID:   &orderID,
+		Status:    participant.StatusRegistered,
+		CreatedAt: now,
+		Guests:    guests,
+		Answers:   order.Answers,
+	}
+
+	// Re-registrasi setelah cancel memakai baris participant yang lama
+	existing, err := s.participantRepo.FindByEventAndUser(order.EventID, order.UserID)
+	if err != nil {
+		return apperror.Internal(err)
+	}
+	if existing != nil {
+		p.ID = existing.ID
+	}
+
+	// Event dengan approval mode: participant menunggu review organizer,
+	// jika ditolak order di-refund (lihat participant.RejectParticipants)
+	ev, err := s.eventRepo.GetByID(order.EventID)
+	ok := false
+	if err == nil {
+		if ev.RequiresApproval {
+			p.Status = participant.StatusPendingApproval
+		}
+		err = s.repo.WithEventLock(order.EventID, func(repo Repository, participants participant.Repository) error {
+			// Quota ticket type tidak menghitung participant gratis / hasil import,
+			// jadi capacity event dicek ulang sama seperti registrasi gratis
+			if p.Status.IsConfirmed() {
+				if err := participant.CheckCapacity(participants, ev, p); err != nil {
+					return err
+				}
+			}
+			ok, err = repo.CompleteOrder(order, p)
+			return err
+		})
+	}
+	if err == nil && ok {
+		// Tiket guest dikirim setelah participant di-approve
+		if p.Status.IsConfirmed() {
+			s.sendConfirmation(order, p.Guests)
+		}
+		s.publishRegistered(order.UserID, ev, p)
+		return nil
+	}
+
+	s.closeFailedOrder(order, chargeID)
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		// User sudah terdaftar lewat jalur lain (order paralel / registrasi gratis)
+		return participant.ErrAlreadyRegistered
+	}
+	if errors.Is(err, participant.ErrEventFull) {
+		return err
+	}
+	if err != nil {
+		return apperror.Internal(fmt.Errorf("failed to complete order %d: %w", order.ID, err))
+	}
+	return ErrOrderNotPending
+}
+
+// refund mengembalikan dana order lunas lewat provider lalu menandai order refunded.
+// Order ditandai refund_pending sebelum provider dipanggil, sehingga refund yang gagal
+// tidak hilang dan dicoba ulang RetryRefunds. Order yang sudah refunded dianggap sukses
+// agar pemanggil tidak perlu cek ulang
+func (s *service) refund(order *Order) error {
+	switch order.Status {
+	case OrderRefunded:
+		return nil
+	case OrderPaid:
+		order.Status = OrderRefundPending
+		ok, err := s.repo.UpdateOrderStatus(order, OrderPaid)
+		if err != nil {
+			return apperror.Internal(err)
+		}
+		if !ok {
+			// Sudah diambil alih proses lain (refund paralel)
+			return nil
+		}
+	case OrderRefundPending:
+	default:
+		return ErrOrderNotPaid
+	}
+
+	if order.TotalAmount > 0 && order.PaymentRef != "" {
+		if _, err := s.provider.Refund(order.PaymentRef, order.TotalAmount); err != nil {
+			return ErrRefundFailed.Wrap(fmt.Errorf("refund order %d: %w", order.ID, err))
+		}
+	}
+
+	now := time.Now()
+	order.Status = OrderRefunded
+	order.RefundedAt = &now
+	if _, err := s.repo.UpdateOrderStatus(order, OrderRefundPending); err != nil {
+		return apperror.Internal(err)
+	}
+	return nil
+}
+
+// closeFailedOrder menutup order yang gagal diselesaikan completeOrder. Order yang sudah
+// di-charge dicatat refund_pending beserta charge ID-nya lalu di-refund, sehingga refund
+// yang gagal dicoba ulang RetryRefunds. Order gratis yang masih pending dibatalkan
+func (s *service) closeFailedOrder(order *Order, chargeID string) {
+	current, err := s.repo.GetOrderByID(order.ID)
+	if err != nil {
+		log.Printf("ticket: failed to get order %d to close it: %v", order.ID, err)
+		return
+	}
+	if chargeID == "" || order.TotalAmount == 0 {
+		if current.Status == OrderPending {
+			current.Status = OrderCancelled
+			if _, err := s.repo.UpdateOrderStatus(current, OrderPending); err != nil {
+				log.Printf("ticket: failed to cancel order %d: %v", order.ID, err)
+			}
+		}
+		return
+	}
+
+	switch from := current.Status; from {
+	case OrderPending, OrderExpired, OrderCancelled:
+		current.Status = OrderRefundPending
+		current.PaymentRef = chargeID
+		current.PaidAt = order.PaidAt
+		if ok, err := s.repo.UpdateOrderStatus(current, from); err != nil || !ok {
+			log.Printf("ticket: failed to record refund for charge %s of order %d: %v", chargeID, order.ID, err)
+			return
+		}
+		if err := s.refund(current); err != nil {
+			log.Printf("ticket: failed to refund charge %s for order %d, will retry: %v", chargeID, order.ID, err)
+		}
+	default:
+		// Order sudah dilunasi charge lain (pembayaran paralel), charge ini tidak punya order
+		if _, err := s.provider.Refund(chargeID, order.TotalAmount); err != nil {
+			log.Printf("ticket: failed to refund charge %s for order %d: %v", chargeID, order.ID, err)
+		}
+	}
+}
+
+// publishRegistered mengirim ParticipantRegistered untuk participant dari order yang
+// sudah tersimpan. Gagal publish tidak membatalkan order yang sudah lunas
+func (s *service) publishRegistered(actorID uint, ev *event.Event, p *participant.Participant) {
+	if u, err := s.userRepo.GetByID(p.UserID); err == nil {
+		p.User = *u
+	}
+	e := eventbus.ParticipantRegistered{Meta: eventbus.NewMeta(actorID, ev.ID, ev.OrganizerID), Participant: p.Snapshot()}
+	if err := s.bus.Publish(e); err != nil {
+		log.Printf("Failed to handle %s for event %d: %v", e.Topic(), ev.ID, err)
+	}
+}
+
+func (s *service) expireOrder(order *Order) {
+	order.Status = OrderExpired
+	if _, err := s.repo.UpdateOrderStatus(order, OrderPending); err != nil {
+		log.Printf("ticket: failed to expire order %d: %v", order.ID, err)
+	}
+}
+
+// sendConfirmation mengirim email konfirmasi ke pemesan dan tiket ke setiap guest
+// (async, tidak block jika gagal)
+func (s *service) sendConfirmation(order *Order, guests []participant.Guest) {
+	go func() {
+		ev, err := s.eventRepo.GetByID(order.EventID)
+		if err != nil {
+			log.Printf("Failed to get event %d for order confirmation: %v", order.EventID, err)
+			return
+		}
+		u, err := s.userRepo.GetByID(order.UserID)
+		if err != nil {
+			log.Printf("Failed to get user %d for order confirmation: %v", order.UserID, err)
+			return
+		}
+		locale := i18n.Resolve(u.Locale)
+		mailer := s.emailService.WithLocale(locale)
+		eventDate := i18n.FormatDateTime(locale, ev.StartTime.In(timezone.Resolve(u.Timezone, ev.Timezone)))
+		if err := mailer.SendRegistrationConfirmationEmail(u.Email, u.Name, ev.Title, eventDate, ev.Location); err != nil {
+			log.Printf("Failed to send registration confirmation email to %s: %v", u.Email, err)
+		}
+		// Guest tidak punya preferensi timezone, waktu ditulis dalam timezone event
+		guestDate := i18n.FormatDateTime(locale, ev.StartTime.In(ev.Zone()))
+		participant.SendGuestTickets(mailer, guests, ev.Title, guestDate, ev.Location, u.Name)
+	}()
+}
+
+// getManagedEvent memastikan event ada, milik organizer, dan belum final
+func (s *service) getManagedEvent(userID, eventID uint) (*event.Event, error) {
+	ev, err := s.eventRepo.GetByID(eventID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrEventNotFound
+		}
+		return nil, apperror.Internal(err)
+	}
+	if ev.OrganizerID != userID {
+		return nil, ErrNotOrganizer
+	}
+	if ev.Status.IsFinal() {
+		return nil, ErrEventFinalized
+	}
+	return ev, nil
+}
+
+func (s *service) getTicketType(id uint) (*TicketType, error) {
+	ticketType, err := s.repo.GetTicketTypeByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTicketTypeNotFound
+		}
+		return nil, apperror.Internal(err)
+	}
+	return ticketType, nil
+}
+
+func (s *service) getManagedTicketType(userID, id uint) (*TicketType, error) {
+	ticketType, err := s.getTicketType(id)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.getManagedEvent(userID, ticketType.EventID); err != nil {
+		return nil, err
+	}
+	return ticketType, nil
+}
+
+// getOwnedOrder mengambil order milik user. Order milik user lain dianggap tidak ada
+func (s *service) getOwnedOrder(userID, orderID uint) (*Order, error) {
+	order, err := s.repo.GetOrderByID(orderID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOrderNotFound
+		}
+		return nil, apperror.Internal(err)
+	}
+	if order.UserID != userID {
+		return nil, ErrOrderNotFound
+	}
+	return order, nil
+}
+
+func validateSaleWindow(start, end *time.Time) error {
+	if start != nil && end != nil && !end.After(*start) {
+		return validation.NewError("sales_end", "gtfield", "sales_start", "must be after sales_start")
+	}
+	return nil
+}
+
+// wrapInternal meneruskan error domain apa adanya, error lain dibungkus sebagai internal
+func wrapInternal(err error) error {
+	if _, ok := apperror.As(err); ok {
+		return err
+	}
+	return apperror.Internal(err)
+}
+
+func NewService(
+	repo Repository,
+	eventRepo event.Repository,
+	participantRepo participant.Repository,
+	userRepo user.Repository,
+	provider payment.Provider,
+	emailService email.Service,
+	bus eventbus.Publisher,
+	cfg *config.Config,
+) Service {
+	orderHold, err := time.ParseDuration(cfg.OrderHoldDuration)
+	if err != nil || orderHold <= 0 {
+		log.Printf("ticket: invalid ORDER_HOLD_DURATION %q, using %s", cfg.OrderHoldDuration, defaultOrderHold)
+		orderHold = defaultOrderHold
+	}
+	return &service{
+		repo:            repo,
+		eventRepo:       eventRepo,
+		participantRepo: participantRepo,
+		userRepo:        userRepo,
+		provider:        provider,
+		emailService:    emailService,
+		bus:             bus,
+		orderHold:       orderHold,
+		cfg:             cfg,
+	}
+}
@@ -0,0 +1,432 @@
+package ticket
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"go-event/internal/event"
+	"go-event/internal/eventbus"
+	"go-event/internal/notification/email"
+	"go-event/internal/participant"
+	"go-event/internal/payment"
+	"go-event/internal/user"
+	"go-event/pkg/config"
+	"go-event/pkg/i18n"
+
+	"gorm.io/gorm"
+)
+
+const (
+	testEventID      = 1
+	testTicketTypeID = 1
+	testPrice        = 50000
+)
+
+// fakeRepo adalah Repository in-memory. Order disimpan sebagai value agar service
+// selalu bekerja dengan salinan, sama seperti membaca dari database.
+// Method yang tidak dipakai test ini panic lewat interface yang di-embed
+type fakeRepo struct {
+	Repository
+	mu           sync.Mutex
+	ticketTypes  map[uint]TicketType
+	orders       map[uint]Order
+	nextOrderID  uint
+	participants *fakeParticipantRepo
+}
+
+func (r *fakeRepo) GetTicketTypeByID(id uint) (*TicketType, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, ok := r.ticketTypes[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &t, nil
+}
+
+func (r *fakeRepo) ReservedSeats(ids []uint, now time.Time) (map[uint]int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	reserved := make(map[uint]int, len(ids))
+	for _, o := range r.orders {
+		if o.Status == OrderPaid || (o.Status == OrderPending && o.ExpiresAt.After(now)) {
+			reserved[o.TicketTypeID] += o.Quantity
+		}
+	}
+	return reserved, nil
+}
+
+func (r *fakeRepo) WithTicketTypeLock(id uint, fn func(repo Repository, ticketType *TicketType) error) error {
+	t, err := r.GetTicketTypeByID(id)
+	if err != nil {
+		return err
+	}
+	return fn(r, t)
+}
+
+func (r *fakeRepo) WithEventLock(_ uint, fn func(repo Repository, participants participant.Repository) error) error {
+	return fn(r, r.participants)
+}
+
+func (r *fakeRepo) CreateOrder(order *Order) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nextOrderID++
+	order.ID = r.nextOrderID
+	order.CreatedAt = time.Now()
+	order.UpdatedAt = order.CreatedAt
+	r.orders[order.ID] = *order
+	return nil
+}
+
+func (r *fakeRepo) GetOrderByID(id uint) (*Order, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	o, ok := r.orders[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	o.TicketType = r.ticketTypes[o.TicketTypeID]
+	return &o, nil
+}
+
+func (r *fakeRepo) FindPendingOrder(eventID, userID uint, now time.Time) (*Order, error) {
+	for _, o := range r.ordersWhere(func(o Order) bool {
+		return o.EventID == eventID && o.UserID == userID && o.Status == OrderPending && o.ExpiresAt.After(now)
+	}) {
+		return &o, nil
+	}
+	return nil, nil
+}
+
+func (r *fakeRepo) FindExpiredPendingOrders(now time.Time) ([]Order, error) {
+	return r.ordersWhere(func(o Order) bool {
+		return o.Status == OrderPending && !o.ExpiresAt.After(now)
+	}), nil
+}
+
+func (r *fakeRepo) FindStaleRefundPendingOrders(before time.Time) ([]Order, error) {
+	return r.ordersWhere(func(o Order) bool {
+		return o.Status == OrderRefundPending && !o.UpdatedAt.After(before)
+	}), nil
+}
+
+func (r *fakeRepo) UpdateOrderStatus(order *Order, from OrderStatus) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.orders[order.ID]
+	if !ok || stored.Status != from {
+		return false, nil
+	}
+	stored.Status = order.Status
+	stored.PaymentRef = order.PaymentRef
+	stored.PaidAt = order.PaidAt
+	stored.RefundedAt = order.RefundedAt
+	stored.UpdatedAt = time.Now()
+	r.orders[order.ID] = stored
+	return true, nil
+}
+
+func (r *fakeRepo) CompleteOrder(order *Order, p *participant.Participant) (bool, error) {
+	ok, err := r.UpdateOrderStatus(order, OrderPending)
+	if err != nil || !ok {
+		return false, err
+	}
+	return true, r.participants.Register(p)
+}
+
+func (r *fakeRepo) ordersWhere(match func(o Order) bool) []Order {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var orders []Order
+	for _, o := range r.orders {
+		if match(o) {
+			orders = append(orders, o)
+		}
+	}
+	return orders
+}
+
+type fakeParticipantRepo struct {
+	participant.Repository
+	mu           sync.Mutex
+	participants []participant.Participant
+}
+
+func (r *fakeParticipantRepo) Register(p *participant.Participant) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, existing := range r.participants {
+		if existing.EventID == p.EventID && existing.UserID == p.UserID && existing.ID != p.ID {
+			return gorm.ErrDuplicatedKey
+		}
+	}
+	p.ID = uint(len(r.participants) + 1)
+	r.participants = append(r.participants, *p)
+	return nil
+}
+
+func (r *fakeParticipantRepo) FindByEventAndUser(eventID, userID uint) (*participant.Participant, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, p := range r.participants {
+		if p.EventID == eventID && p.UserID == userID {
+			return &p, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeParticipantRepo) CountConfirmedSeats(eventID uint) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var seats int64
+	for _, p := range r.participants {
+		if p.EventID == eventID && p.Status.IsConfirmed() {
+			seats += int64(1 + len(p.Guests))
+		}
+	}
+	return seats, nil
+}
+
+type fakeEventRepo struct {
+	event.Repository
+	event event.Event
+}
+
+func (r *fakeEventRepo) GetByID(id uint) (*event.Event, error) {
+	if id != r.event.ID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	ev := r.event
+	return &ev, nil
+}
+
+type fakeUserRepo struct {
+	user.Repository
+}
+
+func (r *fakeUserRepo) GetByID(id uint) (*user.User, error) {
+	return &user.User{ID: id, Name: "Budi", Email: "budi@example.com"}, nil
+}
+
+// fakeEmail tidak mengirim apa pun, konfirmasi order dikirim dari goroutine
+type fakeEmail struct {
+	email.Service
+}
+
+func (e *fakeEmail) WithLocale(i18n.Locale) email.Service { return e }
+
+func (e *fakeEmail) SendRegistrationConfirmationEmail(_, _, _, _, _ string) error { return nil }
+
+func (e *fakeEmail) SendGuestTicketEmail(_, _, _, _, _, _, _ string) error { return nil }
+
+type testEnv struct {
+	svc          Service
+	repo         *fakeRepo
+	participants *fakeParticipantRepo
+	provider     *payment.FakeProvider
+	registered   []eventbus.ParticipantRegistered
+}
+
+// newTestEnv menyiapkan event published dengan capacity dan satu ticket type berbayar
+func newTestEnv(t *testing.T, capacity int) *testEnv {
+	t.Helper()
+	participants := &fakeParticipantRepo{}
+	repo := &fakeRepo{
+		ticketTypes: map[uint]TicketType{
+			testTicketTypeID: {ID: testTicketTypeID, EventID: testEventID, Name: "Regular", Price: testPrice, Currency: "IDR", Quota: 10},
+		},
+		orders:       make(map[uint]Order),
+		participants: participants,
+	}
+	eventRepo := &fakeEventRepo{event: event.Event{
+		ID:          testEventID,
+		Title:       "Go Meetup",
+		StartTime:   time.Now().Add(48 * time.Hour),
+		EndTime:     time.Now().Add(50 * time.Hour),
+		Timezone:    "UTC",
+		OrganizerID: 99,
+		Capacity:    capacity,
+		Status:      event.StatusPublished,
+	}}
+
+	env := &testEnv{repo: repo, participants: participants, provider: payment.NewFakeProvider()}
+	bus := eventbus.New()
+	eventbus.Subscribe(bus, eventbus.Sync, func(e eventbus.ParticipantRegistered) error {
+		env.registered = append(env.registered, e)
+		return nil
+	})
+	env.svc = NewService(repo, eventRepo, participants, &fakeUserRepo{}, env.provider, &fakeEmail{}, bus, &config.Config{OrderHoldDuration: "15m"})
+	return env
+}
+
+func (env *testEnv) createOrder(t *testing.T, userID uint) *OrderResponse {
+	t.Helper()
+	order, err := env.svc.CreateOrder(userID, &CreateOrderRequest{TicketTypeID: testTicketTypeID})
+	if err != nil {
+		t.Fatalf("CreateOrder: %v", err)
+	}
+	return order
+}
+
+func (env *testEnv) order(t *testing.T, id uint) *Order {
+	t.Helper()
+	order, err := env.repo.GetOrderByID(id)
+	if err != nil {
+		t.Fatalf("GetOrderByID(%d): %v", id, err)
+	}
+	return order
+}
+
+func TestPayOrderRegistersParticipant(t *testing.T) {
+	env := newTestEnv(t, 10)
+	created := env.createOrder(t, 1)
+
+	paid, err := env.svc.PayOrder(1, created.ID, &PayOrderRequest{PaymentToken: "tok_ok"})
+	if err != nil {
+		t.Fatalf("PayOrder: %v", err)
+	}
+	if paid.Status != OrderPaid {
+		t.Errorf("status = %s, want %s", paid.Status, OrderPaid)
+	}
+	p, _ := env.participants.FindByEventAndUser(testEventID, 1)
+	if p == nil || p.Status != participant.StatusRegistered || p.OrderID == nil || *p.OrderID != created.ID {
+		t.Fatalf("participant = %+v, want registered with order %d", p, created.ID)
+	}
+	if len(env.registered) != 1 || env.registered[0].Participant.UserID != 1 {
+		t.Errorf("ParticipantRegistered published %d times, want once for user 1", len(env.registered))
+	}
+}
+
+func TestOrderHoldExpires(t *testing.T) {
+	env := newTestEnv(t, 10)
+	created := env.createOrder(t, 1)
+	if !created.ExpiresAt.After(time.Now()) {
+		t.Fatalf("expires_at %s should be in the future", created.ExpiresAt)
+	}
+
+	// Hold lewat sebelum user membayar
+	if err := env.svc.ExpireOrders(created.ExpiresAt.Add(time.Second)); err != nil {
+		t.Fatalf("ExpireOrders: %v", err)
+	}
+	if got := env.order(t, created.ID).Status; got != OrderExpired {
+		t.Fatalf("status = %s, want %s", got, OrderExpired)
+	}
+	reserved, _ := env.repo.ReservedSeats([]uint{testTicketTypeID}, time.Now())
+	if reserved[testTicketTypeID] != 0 {
+		t.Errorf("reserved seats = %d, want 0 after expiry", reserved[testTicketTypeID])
+	}
+
+	_, err := env.svc.PayOrder(1, created.ID, &PayOrderRequest{PaymentToken: "tok_ok"})
+	if !errors.Is(err, ErrOrderNotPending) {
+		t.Errorf("PayOrder error = %v, want %v", err, ErrOrderNotPending)
+	}
+
+	// User bisa memesan ulang setelah hold dilepas
+	env.createOrder(t, 1)
+}
+
+func TestPayOrderAfterHoldDeadline(t *testing.T) {
+	env := newTestEnv(t, 10)
+	created := env.createOrder(t, 1)
+
+	// Scheduler belum berjalan, tapi ExpiresAt sudah lewat saat user membayar
+	order := env.order(t, created.ID)
+	order.ExpiresAt = time.Now().Add(-time.Second)
+	env.repo.orders[order.ID] = *order
+
+	_, err := env.svc.PayOrder(1, created.ID, &PayOrderRequest{PaymentToken: "tok_ok"})
+	if !errors.Is(err, ErrOrderExpired) {
+		t.Fatalf("PayOrder error = %v, want %v", err, ErrOrderExpired)
+	}
+	if got := env.order(t, created.ID).Status; got != OrderExpired {
+		t.Errorf("status = %s, want %s", got, OrderExpired)
+	}
+	if p, _ := env.participants.FindByEventAndUser(testEventID, 1); p != nil {
+		t.Errorf("participant created for expired order: %+v", p)
+	}
+}
+
+func TestPayOrderRefundsWhenEventFilledUp(t *testing.T) {
+	env := newTestEnv(t, 1)
+	created := env.createOrder(t, 1)
+
+	// Kursi terakhir diambil registrasi lain sebelum order dibayar
+	if err := env.participants.Register(&participant.Participant{EventID: testEventID, UserID: 2, Status: participant.StatusRegistered}); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := env.svc.PayOrder(1, created.ID, &PayOrderRequest{PaymentToken: "tok_ok"})
+	if !errors.Is(err, participant.ErrEventFull) {
+		t.Fatalf("PayOrder error = %v, want %v", err, participant.ErrEventFull)
+	}
+
+	order := env.order(t, created.ID)
+	if order.Status != OrderRefunded || order.RefundedAt == nil {
+		t.Fatalf("status = %s, want %s", order.Status, OrderRefunded)
+	}
+	if order.PaymentRef == "" {
+		t.Fatal("payment_ref should keep the refunded charge")
+	}
+	if got := env.provider.Refunded(order.PaymentRef); got != testPrice {
+		t.Errorf("refunded = %d, want %d", got, testPrice)
+	}
+	if p, _ := env.participants.FindByEventAndUser(testEventID, 1); p != nil {
+		t.Errorf("participant created for full event: %+v", p)
+	}
+	if len(env.registered) != 0 {
+		t.Errorf("ParticipantRegistered published for a refunded order")
+	}
+}
+
+func TestFailedRefundIsRetried(t *testing.T) {
+	env := newTestEnv(t, 1)
+	created := env.createOrder(t, 1)
+	if err := env.participants.Register(&participant.Participant{EventID: testEventID, UserID: 2, Status: participant.StatusRegistered}); err != nil {
+		t.Fatal(err)
+	}
+
+	env.provider.FailRefunds(1)
+	_, err := env.svc.PayOrder(1, created.ID, &PayOrderRequest{PaymentToken: "tok_ok"})
+	if !errors.Is(err, participant.ErrEventFull) {
+		t.Fatalf("PayOrder error = %v, want %v", err, participant.ErrEventFull)
+	}
+	order := env.order(t, created.ID)
+	if order.Status != OrderRefundPending {
+		t.Fatalf("status = %s, want %s", order.Status, OrderRefundPending)
+	}
+	if got := env.provider.Refunded(order.PaymentRef); got != 0 {
+		t.Fatalf("refunded = %d, want 0 while the provider is down", got)
+	}
+
+	// Order yang baru gagal belum dicoba ulang, bisa jadi masih diproses request lain
+	if err := env.svc.RetryRefunds(time.Now()); err != nil {
+		t.Fatalf("RetryRefunds: %v", err)
+	}
+	if got := env.order(t, created.ID).Status; got != OrderRefundPending {
+		t.Fatalf("status = %s, want %s before the retry delay", got, OrderRefundPending)
+	}
+
+	// Putaran scheduler berikutnya setelah refundRetryDelay
+	if err := env.svc.RetryRefunds(time.Now().Add(refundRetryDelay + time.Minute)); err != nil {
+		t.Fatalf("RetryRefunds: %v", err)
+	}
+	order = env.order(t, created.ID)
+	if order.Status != OrderRefunded || order.RefundedAt == nil {
+		t.Fatalf("status = %s, want %s after retry", order.Status, OrderRefunded)
+	}
+	if got := env.provider.Refunded(order.PaymentRef); got != testPrice {
+		t.Errorf("refunded = %d, want %d", got, testPrice)
+	}
+
+	// Retry berikutnya tidak me-refund dua kali
+	if err := env.svc.RetryRefunds(time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("RetryRefunds: %v", err)
+	}
+	if got := env.provider.Refunded(order.PaymentRef); got != testPrice {
+		t.Errorf("refunded = %d after a second retry, want %d", got, testPrice)
+	}
+}
@@ -1,7 +1,10 @@
 package user
 
 import (
+	"go-event/pkg/apperror"
 	"go-event/pkg/config"
+	"go-event/pkg/i18n"
+	"go-event/pkg/validation"
 	"strconv"
 	"time"
 
@@ -25,17 +28,15 @@ func (ctrl *Controller) Login(c *fiber.Ctx) error {
 	var req LoginRequest
 
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
-
+		return apperror.ErrInvalidBody
+	}
+	if err := validation.Struct(&req); err != nil {
+		return err
 	}
 
 	token, userResponse, err := ctrl.service.Login(req)
 	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"message": err.Error(),
-		})
+		return err
 	}
 
 	c.Cookie(&fiber.Cookie{
@@ -58,16 +59,20 @@ func (ctrl *Controller) Register(c *fiber.Ctx) error {
 	var req RegisterRequest
 
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"message": "Invalid request body",
-		})
+		return apperror.ErrInvalidBody
+	}
+	if err := validation.Struct(&req); err != nil {
+		return err
+	}
+	if req.Locale == "" {
+		if locale, ok := i18n.FromAcceptLanguage(c.Get(fiber.HeaderAcceptLanguage)); ok {
+			req.Locale = string(locale)
+		}
 	}
 
 	userResponse, err := ctrl.service.Register(req)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"message": err.Error(),
-		})
+		return err
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
@@ -85,9 +90,7 @@ func (ctrl *Controller) GetProfile(c *fiber.Ctx) error {
 
 	userResponse, err := ctrl.service.GetProfile(userID)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"message": err.Error(),
-		})
+		return err
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
@@ -100,9 +103,7 @@ func (ctrl *Controller) GetProfile(c *fiber.Ctx) error {
 func (ctrl *Controller) GetAllUsers(c *fiber.Ctx) error {
 	users, err := ctrl.service.GetAllUsers()
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"message": err.Error(),
-		})
+		return err
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
@@ -117,20 +118,12 @@ func (ctrl *Controller) GetUserByID(c *fiber.Ctx) error {
 
 	userID, err := strconv.ParseUint(id, 10, 32)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "invalid user id",
-		})
+		return apperror.InvalidParam("user id")
 	}
 
 	userResponse, err := ctrl.service.GetUserByID(uint(userID))
 	if err != nil {
-		statusCode := fiber.StatusInternalServerError
-		if err.Error() == "user not found" {
-			statusCode = fiber.StatusNotFound
-		}
-		return c.Status(statusCode).JSON(fiber.Map{
-			"message": err.Error(),
-		})
+		return err
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
@@ -144,16 +137,15 @@ func (ctrl *Controller) UpdateProfile(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uint)
 	var req UpdateUserRequest
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "invalid request body",
-		})
+		return apperror.ErrInvalidBody
+	}
+	if err := validation.Struct(&req); err != nil {
+		return err
 	}
 
 	updatedUser, err := ctrl.service.UpdateProfile(userID, &req)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"message": err.Error(),
-		})
+		return err
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
@@ -168,19 +160,11 @@ func (ctrl *Controller) DeleteUser(c *fiber.Ctx) error {
 
 	userID, err := strconv.ParseUint(id, 10, 32)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "invalid user id",
-		})
+		return apperror.InvalidParam("user id")
 	}
 
 	if err := ctrl.service.DeleteUser(uint(userID)); err != nil {
-		statusCode := fiber.StatusInternalServerError
-		if err.Error() == "user not found" {
-			statusCode = fiber.StatusNotFound
-		}
-		return c.Status(statusCode).JSON(fiber.Map{
-			"message": err.Error(),
-		})
+		return err
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
@@ -194,9 +178,7 @@ func (ctrl *Controller) GetUsersByRole(c *fiber.Ctx) error {
 
 	users, err := ctrl.service.GetUsersByRole(role)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"message": err.Error(),
-		})
+		return err
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
@@ -210,19 +192,14 @@ func (ctrl *Controller) ChangePassword(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(uint)
 	var req ChangePasswordRequest
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "invalid request body",
-		})
+		return apperror.ErrInvalidBody
+	}
+	if err := validation.Struct(&req); err != nil {
+		return err
 	}
 
 	if err := ctrl.service.ChangePassword(userID, &req); err != nil {
-		statusCode := fiber.StatusBadRequest
-		if err.Error() == "invalid old password" {
-			statusCode = fiber.StatusUnauthorized
-		}
-		return c.Status(statusCode).JSON(fiber.Map{
-			"message": err.Error(),
-		})
+		return err
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
@@ -235,21 +212,18 @@ func (ctrl *Controller) UpdateRole(c *fiber.Ctx) error {
 
 	userID, err := strconv.ParseUint(id, 10, 32)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "invalid user id",
-		})
+		return apperror.InvalidParam("user id")
 	}
 	var req UpdateRoleRequest
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "invalid request body",
-		})
+		return apperror.ErrInvalidBody
+	}
+	if err := validation.Struct(&req); err != nil {
+		return err
 	}
 	updatedUser, err := ctrl.service.UpdateRole(uint(userID), &req)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"message": err.Error(),
-		})
+		return err
 	}
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"message": "user role updated successfully",
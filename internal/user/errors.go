@@ -0,0 +1,11 @@
+package user
+
+import "go-event/pkg/apperror"
+
+var (
+	ErrUserNotFound       = apperror.New(apperror.KindNotFound, "USER_NOT_FOUND", "user not found")
+	ErrInvalidCredentials = apperror.New(apperror.KindUnauthorized, "INVALID_CREDENTIALS", "invalid email or password")
+	ErrInvalidOldPassword = apperror.New(apperror.KindUnauthorized, "INVALID_OLD_PASSWORD", "invalid old password")
+	ErrEmailInUse         = apperror.New(apperror.KindConflict, "EMAIL_IN_USE", "email already in use")
+	ErrMissingFields      = apperror.New(apperror.KindBadRequest, "USER_MISSING_FIELDS", "all fields are required")
+)
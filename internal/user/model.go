@@ -1,6 +1,9 @@
 package user
 
-import "time"
+import (
+	"go-event/pkg/i18n"
+	"time"
+)
 
 type RoleType string
 
@@ -16,24 +19,37 @@ type User struct {
 	Email     string    `json:"email" gorm:"uniqueIndex;size:191"`
 	Password  string    `json:"-"` // jangan dikirim ke response
 	Role      RoleType  `json:"role" gorm:"type:enum('admin','organizer','participant')"`
+	// Akun placeholder dari import participant, belum punya password dan tidak bisa login.
+	// Diklaim saat user register dengan email yang sama
+	Invited   bool      `json:"invited" gorm:"default:false"`
+	// Timezone IANA (contoh Asia/Jakarta) untuk menampilkan waktu event dan quiet hours notifikasi.
+	// Kosong = waktu event ditampilkan dalam timezone event, quiet hours memakai UTC
+	Timezone  string    `json:"timezone" gorm:"size:64"`
+	// Bahasa notifikasi dan email (id/en)
+	Locale    string    `json:"locale" gorm:"size:10;default:id"`
 	CreatedAt time.Time `json:"created_at"`
 }
 
 func (u *User) ToResponse() *UserResponse {
 	return &UserResponse{
-		ID:    u.ID,
-		Name:  u.Name,
-		Email: u.Email,
-		Role:  string(u.Role),
+		ID:       u.ID,
+		Name:     u.Name,
+		Email:    u.Email,
+		Role:     string(u.Role),
+		Timezone: u.Timezone,
+		Locale:   string(i18n.Resolve(u.Locale)),
 	}
 }
 
 // 📩 Request structs
 type RegisterRequest struct {
-	Name     string `json:"name" validate:"required"`
-	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required,min=6"`
-	Role     string `json:"role" validate:"required,oneof=admin organizer participant"`
+	Name     string `json:"name" validate:"required,notblank,max=100"`
+	Email    string `json:"email" validate:"required,email,max=191"`
+	Password string `json:"password" validate:"required,min=6,max=72"`
+	Role     string `json:"role" validate:"omitempty,oneof=admin organizer participant"` // diabaikan, user baru selalu participant
+	// Kosong = dari header Accept-Language, lalu default (id)
+	Locale   string `json:"locale" validate:"omitempty,oneof=id en"`
+	Timezone string `json:"timezone" validate:"omitempty,timezone"` // kosong = ikut timezone event
 }
 
 type LoginRequest struct {
@@ -42,8 +58,10 @@ type LoginRequest struct {
 }
 
 type UpdateUserRequest struct {
-	Name  *string `json:"name"`
-	Email *string `json:"email"`
+	Name     *string `json:"name" validate:"omitempty,notblank,max=100"`
+	Email    *string `json:"email" validate:"omitempty,email,max=191"`
+	Timezone *string `json:"timezone" validate:"omitempty,timezone"` // "" = ikut timezone event
+	Locale   *string `json:"locale" validate:"omitempty,oneof=id en"`
 }
 
 type UpdateRoleRequest struct {
@@ -52,16 +70,18 @@ type UpdateRoleRequest struct {
 
 type ChangePasswordRequest struct {
     OldPassword string `json:"old_password" form:"old_password" validate:"required"`
-    NewPassword string `json:"new_password" form:"new_password" validate:"required,min=6"`
+    NewPassword string `json:"new_password" form:"new_password" validate:"required,min=6,max=72"`
 }
 
 
 // 📤 Response structs
 type UserResponse struct {
-	ID    uint   `json:"id"`
-	Name  string `json:"name"`
-	Email string `json:"email"`
-	Role  string `json:"role"`
+	ID       uint   `json:"id"`
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Role     string `json:"role"`
+	Timezone string `json:"timezone"`
+	Locale   string `json:"locale"`
 }
 
 type Participant struct {
@@ -1,25 +1,26 @@
 package user
 
 import (
+	"go-event/internal/audit"
 	"go-event/pkg/config"
 	"go-event/pkg/middlewares"
 
 	"github.com/gofiber/fiber/v2"
 )
 
-func SetupUserRoutes(app *fiber.App, ctrl *Controller, cfg *config.Config) {
+func SetupUserRoutes(app *fiber.App, ctrl *Controller, cfg *config.Config, auditLog *audit.Recorder) {
 	auth := app.Group("/api/auth")
 	auth.Post("/register", ctrl.Register)
 	auth.Post("/login", ctrl.Login)
 
 	user := app.Group("/api/user")
 	user.Get("/profile", middlewares.Authenticate(cfg), ctrl.GetProfile)
-	user.Put("/profile", middlewares.Authenticate(cfg), ctrl.UpdateProfile)
-	user.Post("/change-password", middlewares.Authenticate(cfg), ctrl.ChangePassword)
+	user.Put("/profile", middlewares.Authenticate(cfg), auditLog.Record("user.update_profile", audit.Self("user")), ctrl.UpdateProfile)
+	user.Post("/change-password", middlewares.Authenticate(cfg), auditLog.Record("user.change_password", audit.Self("user")), ctrl.ChangePassword)
 	// Admin only routes
 	user.Get("/", middlewares.Authenticate(cfg), middlewares.Authorize("admin"), ctrl.GetAllUsers)
 	user.Get("/:id", middlewares.Authenticate(cfg), middlewares.Authorize("admin"), ctrl.GetUserByID)
-	user.Delete("/:id", middlewares.Authenticate(cfg), middlewares.Authorize("admin"), ctrl.DeleteUser)
+	user.Delete("/:id", middlewares.Authenticate(cfg), middlewares.Authorize("admin"), auditLog.Record("user.delete", audit.Param("user", "id")), ctrl.DeleteUser)
 	user.Get("/role/:role", middlewares.Authenticate(cfg), middlewares.Authorize("admin"), ctrl.GetUsersByRole)
-	user.Put("/role/:id", middlewares.Authenticate(cfg), middlewares.Authorize("admin"), ctrl.UpdateRole)
+	user.Put("/role/:id", middlewares.Authenticate(cfg), middlewares.Authorize("admin"), auditLog.Record("user.update_role", audit.Param("user", "id")), ctrl.UpdateRole)
 }
\ No newline at end of file
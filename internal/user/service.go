@@ -2,8 +2,11 @@ package user
 
 import (
 	"errors"
+	"fmt"
 	"go-event/internal/notification/email"
+	"go-event/pkg/apperror"
 	"go-event/pkg/config"
+	"go-event/pkg/i18n"
 	"log"
 	"time"
 
@@ -66,74 +69,82 @@ func (a *service) GenerateToken(user *User) (string, error) {
 // Login implements service.
 func (s *service) Login(req LoginRequest) (string,*UserResponse, error) {
 	if req.Email == "" || req.Password == "" {
-		return "",nil, errors.New("email and password are required")
+		return "",nil, ErrInvalidCredentials.WithMessage("email and password are required")
 	}
 
 	users, err := s.repo.FindByEmail(req.Email)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return "",nil, errors.New("invalid email or password")
+			return "",nil, ErrInvalidCredentials
 		}
-		return "",nil, err
+		return "",nil, apperror.Internal(err)
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(users.Password), []byte(req.Password)); err != nil {
-		return "",nil, errors.New("invalid email or password")
+		return "",nil, ErrInvalidCredentials
 	}
 
 	token, err := s.GenerateToken(users)
 	if err != nil {
-		return "",nil, err
+		return "",nil, apperror.Internal(err)
 	}
 
-	userResponse := &UserResponse{
-		ID:    users.ID,
-		Name:  users.Name,
-		Email: users.Email,
-		Role:  string(users.Role),
-	}
+	userResponse := users.ToResponse()
 	return token,userResponse, nil
 }
 
 // Register implements service.
 func (s *service) Register(req RegisterRequest) (*UserResponse, error) {
 	if req.Email == "" || req.Name == "" || req.Password == "" {
-		return nil, errors.New("all fields are required")
+		return nil, ErrMissingFields
 	}
 
 	existingUser, _ := s.repo.FindByEmail(req.Email)
-	if existingUser != nil {
-		return nil, errors.New("email already in use")
+	if existingUser != nil && !existingUser.Invited {
+		return nil, ErrEmailInUse
 	}
 
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 	if err != nil {
-		return nil, err	}
+		return nil, apperror.Internal(err)
+	}
 	
 	newUser := &User{
 		Name:     req.Name,
 		Email:    req.Email,
 		Password: string(hashedPassword),
 		Role:     RoleParticipant,
+		Timezone: req.Timezone,
+		Locale:   string(i18n.Resolve(req.Locale)),
 	}
 	
-	if err := s.repo.Create(newUser); err != nil {
-		return nil, err
+	// Akun invited (hasil import) diklaim: pendaftaran event yang sudah ada tetap terhubung
+	if existingUser != nil {
+		existingUser.Name = newUser.Name
+		existingUser.Password = newUser.Password
+		existingUser.Invited = false
+		existingUser.Locale = newUser.Locale
+		existingUser.Timezone = newUser.Timezone
+		newUser = existingUser
+		err = s.repo.Update(newUser)
+	} else {
+		err = s.repo.Create(newUser)
+	}
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return nil, ErrEmailInUse
+	}
+	if err != nil {
+		return nil, apperror.Internal(err)
 	}
 	
 	// Kirim welcome email (async, tidak block jika gagal)
 	go func() {
-		if err := s.emailService.SendWelcomeEmail(newUser.Email, newUser.Name); err != nil {
+		if err := s.emailService.WithLocale(i18n.Resolve(newUser.Locale)).SendWelcomeEmail(newUser.Email, newUser.Name); err != nil {
 			log.Printf("Failed to send welcome email to %s: %v", newUser.Email, err)
 		}
 	}()
 	
-	userResponse := &UserResponse{
-		ID:    newUser.ID,
-		Name:  newUser.Name,
-		Email: newUser.Email,
-		Role:  string(newUser.Role),
-	}
+	userResponse := newUser.ToResponse()
 	return userResponse, nil
 }
 
@@ -145,17 +156,12 @@ func (s *service) GetProfile(userID uint) (*UserResponse, error) {
 	users, err := s.repo.GetByID(userID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("user not found")
+			return nil, ErrUserNotFound
 		}
-		return nil, errors.New("failed to get user")
+		return nil, apperror.Internal(err)
 	}
 
-	response := &UserResponse{
-		ID:    users.ID,
-		Name:  users.Name,
-		Email: users.Email,
-		Role:  string(users.Role),
-	}
+	response := users.ToResponse()
 	return response, nil
 }
 
@@ -163,17 +169,12 @@ func (s *service) GetProfile(userID uint) (*UserResponse, error) {
 func (s *service) GetAllUsers() ([]UserResponse, error) {
 	users, err := s.repo.GetAll()
 	if err != nil {
-		return nil, errors.New("failed to get users")
+		return nil, apperror.Internal(err)
 	}
 
 	var responses []UserResponse
 	for _, u := range users {
-		response := UserResponse{
-			ID:    u.ID,
-			Name:  u.Name,
-			Email: u.Email,
-			Role:  string(u.Role),
-		}
+		response := *u.ToResponse()
 		responses = append(responses, response)
 	}
 	return responses, nil
@@ -184,17 +185,12 @@ func (s *service) GetUserByID(userID uint) (*UserResponse, error) {
 	users, err := s.repo.GetByID(userID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("user not found")
+			return nil, ErrUserNotFound
 		}
-		return nil, errors.New("failed to get user")
+		return nil, apperror.Internal(err)
 	}
 
-	response := &UserResponse{
-		ID:    users.ID,
-		Name:  users.Name,
-		Email: users.Email,
-		Role:  string(users.Role),
-	}
+	response := users.ToResponse()
 	return response, nil
 }
 
@@ -203,9 +199,9 @@ func (s *service) UpdateProfile(userID uint, req *UpdateUserRequest) (*UserRespo
 	users, err := s.repo.GetByID(userID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("user not found")
+			return nil, ErrUserNotFound
 		}
-		return nil, errors.New("failed to get user")
+		return nil, apperror.Internal(err)
 	}
 
 	if req.Name != nil {
@@ -214,36 +210,40 @@ func (s *service) UpdateProfile(userID uint, req *UpdateUserRequest) (*UserRespo
 	if req.Email != nil {
 		users.Email = *req.Email
 	}
+	if req.Timezone != nil {
+		users.Timezone = *req.Timezone
+	}
+	if req.Locale != nil {
+		users.Locale = *req.Locale
+	}
 
 	if err := s.repo.Update(users); err != nil {
-		return nil, errors.New("failed to update user")
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, ErrEmailInUse
+		}
+		return nil, apperror.Internal(err)
 	}
 
-	response := &UserResponse{
-		ID:    users.ID,
-		Name:  users.Name,
-		Email: users.Email,
-		Role:  string(users.Role),
-	}
+	response := users.ToResponse()
 	return response, nil
 }
 
 // DeleteUser implements UserService.
 func (s *service) DeleteUser(userID uint) error {
 	if err := s.repo.DeleteParticipantsByUserID(userID); err != nil {
-        return errors.New("failed to delete related participants: " + err.Error())
+        return apperror.Internal(fmt.Errorf("failed to delete related participants: %w", err))
     }
 
 	user, err := s.repo.GetByID(userID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("user not found")
+			return ErrUserNotFound
 		}
-		return errors.New("failed to get user")
+		return apperror.Internal(err)
 	}
 
 	if err := s.repo.Delete(user); err != nil {
-		return errors.New("failed to delete user. " + err.Error())
+		return apperror.Internal(fmt.Errorf("failed to delete user: %w", err))
 	}
 	return nil
 }
@@ -252,17 +252,12 @@ func (s *service) DeleteUser(userID uint) error {
 func (s *service) GetUsersByRole(role string) ([]UserResponse, error) {
 	users, err := s.repo.FindByRole(RoleType(role))
 	if err != nil {
-		return nil, errors.New("failed to get users by role")
+		return nil, apperror.Internal(err)
 	}
 
 	var responses []UserResponse
 	for _, u := range users {
-		response := UserResponse{
-			ID:    u.ID,
-			Name:  u.Name,
-			Email: u.Email,
-			Role:  string(u.Role),
-		}
+		response := *u.ToResponse()
 		responses = append(responses, response)
 	}
 	return responses, nil
@@ -273,26 +268,26 @@ func (s *service) ChangePassword(userID uint, req *ChangePasswordRequest) error
 	user, err := s.repo.GetByID(userID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("user not found")
+			return ErrUserNotFound
 		}
-		return errors.New("failed to get user")
+		return apperror.Internal(err)
 	}
 
 	// Verify old password
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.OldPassword)); err != nil {
-		return errors.New("invalid old password" + err.Error())
+		return ErrInvalidOldPassword
 	}
 
 	// Hash new password
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
 	if err != nil {
-		return errors.New("failed to hash password")
+		return apperror.Internal(err)
 	}
 
 	user.Password = string(hashedPassword)
 
 	if err := s.repo.Update(user); err != nil {
-		return errors.New("failed to update password")
+		return apperror.Internal(err)
 	}
 
 	return nil
@@ -303,9 +298,9 @@ func (s *service) UpdateRole(userID uint, req *UpdateRoleRequest) (*UserResponse
 	user, err := s.repo.GetByID(userID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("user not found")
+			return nil, ErrUserNotFound
 		}
-		return nil, errors.New("failed to get user")
+		return nil, apperror.Internal(err)
 	}
 
 	if req.Role != "" {
@@ -313,15 +308,10 @@ func (s *service) UpdateRole(userID uint, req *UpdateRoleRequest) (*UserResponse
 	}
 
 	if err := s.repo.Update(user); err != nil {
-		return nil, errors.New("failed to update user role: " + err.Error())
+		return nil, apperror.Internal(fmt.Errorf("failed to update user role: %w", err))
 	}
 
-	response := &UserResponse{
-		ID:    user.ID,
-		Name:  user.Name,
-		Email: user.Email,
-		Role:  string(user.Role),
-	}
+	response := user.ToResponse()
 	return response, nil
 }
 
@@ -0,0 +1,10 @@
+package venue
+
+import "time"
+
+// BookingRepository interface untuk membaca jadwal event di sebuah room
+// tanpa import package event (event sudah import venue)
+type BookingRepository interface {
+	FindBookings(roomID uint, from, to time.Time) ([]Booking, error)
+	HasBookings(roomIDs ...uint) (bool, error)
+}
@@ -0,0 +1,251 @@
+package venue
+
+import (
+	"go-event/pkg/apperror"
+	"go-event/pkg/config"
+	"go-event/pkg/validation"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type Controller struct {
+	service Service
+	cfg     *config.Config
+}
+
+func NewController(service Service, cfg *config.Config) *Controller {
+	return &Controller{service: service, cfg: cfg}
+}
+
+func (ctrl *Controller) CreateVenue(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	var req CreateVenueRequest
+	if err := c.BodyParser(&req); err != nil {
+		return apperror.ErrInvalidBody
+	}
+	if err := validation.Struct(&req); err != nil {
+		return err
+	}
+
+	venue, err := ctrl.service.CreateVenue(userID, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "venue created successfully",
+		"venue":   venue,
+	})
+}
+
+func (ctrl *Controller) GetAllVenues(c *fiber.Ctx) error {
+	venues, err := ctrl.service.GetAllVenues()
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "venues retrieved successfully",
+		"venues":  venues,
+	})
+}
+
+func (ctrl *Controller) GetVenueByID(c *fiber.Ctx) error {
+	venueID, err := parseID(c, "venue ID")
+	if err != nil {
+		return err
+	}
+
+	venue, err := ctrl.service.GetVenueByID(venueID)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "venue retrieved successfully",
+		"venue":   venue,
+	})
+}
+
+func (ctrl *Controller) UpdateVenue(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	userRole := c.Locals("userRole").(string)
+
+	venueID, err := parseID(c, "venue ID")
+	if err != nil {
+		return err
+	}
+
+	var req UpdateVenueRequest
+	if err := c.BodyParser(&req); err != nil {
+		return apperror.ErrInvalidBody
+	}
+	if err := validation.Struct(&req); err != nil {
+		return err
+	}
+
+	venue, err := ctrl.service.UpdateVenue(userID, userRole, venueID, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "venue updated successfully",
+		"venue":   venue,
+	})
+}
+
+func (ctrl *Controller) DeleteVenue(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	userRole := c.Locals("userRole").(string)
+
+	venueID, err := parseID(c, "venue ID")
+	if err != nil {
+		return err
+	}
+
+	if err := ctrl.service.DeleteVenue(userID, userRole, venueID); err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "venue deleted successfully",
+	})
+}
+
+func (ctrl *Controller) CreateRoom(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	userRole := c.Locals("userRole").(string)
+
+	venueID, err := parseID(c, "venue ID")
+	if err != nil {
+		return err
+	}
+
+	var req CreateRoomRequest
+	if err := c.BodyParser(&req); err != nil {
+		return apperror.ErrInvalidBody
+	}
+	if err := validation.Struct(&req); err != nil {
+		return err
+	}
+
+	room, err := ctrl.service.CreateRoom(userID, userRole, venueID, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "room created successfully",
+		"room":    room,
+	})
+}
+
+func (ctrl *Controller) UpdateRoom(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	userRole := c.Locals("userRole").(string)
+
+	roomID, err := parseID(c, "room ID")
+	if err != nil {
+		return err
+	}
+
+	var req UpdateRoomRequest
+	if err := c.BodyParser(&req); err != nil {
+		return apperror.ErrInvalidBody
+	}
+	if err := validation.Struct(&req); err != nil {
+		return err
+	}
+
+	room, err := ctrl.service.UpdateRoom(userID, userRole, roomID, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "room updated successfully",
+		"room":    room,
+	})
+}
+
+func (ctrl *Controller) DeleteRoom(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	userRole := c.Locals("userRole").(string)
+
+	roomID, err := parseID(c, "room ID")
+	if err != nil {
+		return err
+	}
+
+	if err := ctrl.service.DeleteRoom(userID, userRole, roomID); err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "room deleted successfully",
+	})
+}
+
+// GetRoomAvailability - ?from=...&to=...&min_duration=60 (from/to format RFC3339)
+func (ctrl *Controller) GetRoomAvailability(c *fiber.Ctx) error {
+	roomID, err := parseID(c, "room ID")
+	if err != nil {
+		return err
+	}
+
+	query, err := parseAvailabilityQuery(c)
+	if err != nil {
+		return err
+	}
+	if err := validation.Struct(query); err != nil {
+		return err
+	}
+
+	availability, err := ctrl.service.GetRoomAvailability(roomID, query)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message":      "room availability retrieved successfully",
+		"availability": availability,
+	})
+}
+
+// parseAvailabilityQuery membaca query string. from/to kosong dibiarkan zero
+// supaya dilaporkan sebagai error "required" oleh validator
+func parseAvailabilityQuery(c *fiber.Ctx) (*AvailabilityQuery, error) {
+	var query AvailabilityQuery
+	for name, dst := range map[string]*time.Time{"from": &query.From, "to": &query.To} {
+		raw := c.Query(name)
+		if raw == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return nil, validation.NewError(name, "datetime", time.RFC3339, "must be a valid RFC3339 datetime")
+		}
+		*dst = t
+	}
+	if raw := c.Query("min_duration"); raw != "" {
+		minutes, err := strconv.Atoi(raw)
+		if err != nil {
+			return nil, validation.NewError("min_duration", "number", "", "must be a number of minutes")
+		}
+		query.MinDuration = minutes
+	}
+	return &query, nil
+}
+
+// parseID membaca path param :id, name dipakai untuk pesan error
+func parseID(c *fiber.Ctx, name string) (uint, error) {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return 0, apperror.InvalidParam(name)
+	}
+	return uint(id), nil
+}
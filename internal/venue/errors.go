@@ -0,0 +1,11 @@
+package venue
+
+import "go-event/pkg/apperror"
+
+var (
+	ErrVenueNotFound = apperror.New(apperror.KindNotFound, "VENUE_NOT_FOUND", "venue not found")
+	ErrRoomNotFound  = apperror.New(apperror.KindNotFound, "ROOM_NOT_FOUND", "room not found")
+	ErrNotOwner      = apperror.New(apperror.KindForbidden, "VENUE_FORBIDDEN", "unauthorized to modify this venue")
+	ErrVenueInUse    = apperror.New(apperror.KindConflict, "VENUE_IN_USE", "venue or room is still used by events")
+	ErrRangeTooLong  = apperror.New(apperror.KindBadRequest, "AVAILABILITY_RANGE_TOO_LONG", "availability range cannot exceed 90 days")
+)
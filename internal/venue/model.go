@@ -0,0 +1,130 @@
+package venue
+
+import "time"
+
+// 🧱 Entity (database model)
+type Venue struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Name      string    `json:"name" gorm:"size:200"`
+	Address   string    `json:"address" gorm:"size:500"`
+	Latitude  *float64  `json:"latitude"`
+	Longitude *float64  `json:"longitude"`
+	Amenities []string  `json:"amenities" gorm:"serializer:json;type:text"`
+	CreatedBy uint      `json:"created_by"`
+	Rooms     []Room    `json:"rooms,omitempty" gorm:"foreignKey:VenueID"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type Room struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	VenueID   uint      `json:"venue_id" gorm:"index"`
+	Name      string    `json:"name" gorm:"size:200"`
+	Capacity  int       `json:"capacity"`
+	Amenities []string  `json:"amenities" gorm:"serializer:json;type:text"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Booking adalah rentang waktu room yang sudah dipakai event
+type Booking struct {
+	EventID   uint      `json:"event_id"`
+	Title     string    `json:"title"`
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+}
+
+// 📩 Request structs
+type CreateVenueRequest struct {
+	Name      string   `json:"name" validate:"required,notblank,max=200"`
+	Address   string   `json:"address" validate:"required,notblank,max=500"`
+	Latitude  *float64 `json:"latitude" validate:"omitempty,gte=-90,lte=90"`
+	Longitude *float64 `json:"longitude" validate:"omitempty,gte=-180,lte=180"`
+	Amenities []string `json:"amenities" validate:"omitempty,max=50,dive,notblank,max=100"`
+}
+
+type UpdateVenueRequest struct {
+	Name      *string   `json:"name" validate:"omitempty,notblank,max=200"`
+	Address   *string   `json:"address" validate:"omitempty,notblank,max=500"`
+	Latitude  *float64  `json:"latitude" validate:"omitempty,gte=-90,lte=90"`
+	Longitude *float64  `json:"longitude" validate:"omitempty,gte=-180,lte=180"`
+	Amenities *[]string `json:"amenities" validate:"omitempty,max=50,dive,notblank,max=100"`
+}
+
+type CreateRoomRequest struct {
+	Name      string   `json:"name" validate:"required,notblank,max=200"`
+	Capacity  int      `json:"capacity" validate:"required,gt=0"`
+	Amenities []string `json:"amenities" validate:"omitempty,max=50,dive,notblank,max=100"`
+}
+
+type UpdateRoomRequest struct {
+	Name      *string   `json:"name" validate:"omitempty,notblank,max=200"`
+	Capacity  *int      `json:"capacity" validate:"omitempty,gt=0"`
+	Amenities *[]string `json:"amenities" validate:"omitempty,max=50,dive,notblank,max=100"`
+}
+
+type AvailabilityQuery struct {
+	From        time.Time `json:"from" validate:"required"`
+	To          time.Time `json:"to" validate:"required,gtfield=From"`
+	MinDuration int       `json:"min_duration" validate:"gte=0"` // menit, slot lebih pendek diabaikan
+}
+
+// 📤 Response structs
+type VenueResponse struct {
+	ID        uint           `json:"id"`
+	Name      string         `json:"name"`
+	Address   string         `json:"address"`
+	Latitude  *float64       `json:"latitude"`
+	Longitude *float64       `json:"longitude"`
+	Amenities []string       `json:"amenities"`
+	CreatedBy uint           `json:"created_by"`
+	Rooms     []RoomResponse `json:"rooms"`
+}
+
+type RoomResponse struct {
+	ID        uint     `json:"id"`
+	VenueID   uint     `json:"venue_id"`
+	Name      string   `json:"name"`
+	Capacity  int      `json:"capacity"`
+	Amenities []string `json:"amenities"`
+}
+
+func (v *Venue) ToResponse() *VenueResponse {
+	rooms := make([]RoomResponse, 0, len(v.Rooms))
+	for _, r := range v.Rooms {
+		rooms = append(rooms, *r.ToResponse())
+	}
+	return &VenueResponse{
+		ID:        v.ID,
+		Name:      v.Name,
+		Address:   v.Address,
+		Latitude:  v.Latitude,
+		Longitude: v.Longitude,
+		Amenities: v.Amenities,
+		CreatedBy: v.CreatedBy,
+		Rooms:     rooms,
+	}
+}
+
+func (r *Room) ToResponse() *RoomResponse {
+	return &RoomResponse{
+		ID:        r.ID,
+		VenueID:   r.VenueID,
+		Name:      r.Name,
+		Capacity:  r.Capacity,
+		Amenities: r.Amenities,
+	}
+}
+
+type Slot struct {
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+}
+
+type AvailabilityResponse struct {
+	RoomID    uint      `json:"room_id"`
+	From      time.Time `json:"from"`
+	To        time.Time `json:"to"`
+	Bookings  []Booking `json:"bookings"`
+	FreeSlots []Slot    `json:"free_slots"`
+}
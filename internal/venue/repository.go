@@ -0,0 +1,84 @@
+package venue
+
+import "gorm.io/gorm"
+
+type Repository interface {
+	CreateVenue(venue *Venue) error
+	GetVenueByID(id uint) (*Venue, error)
+	GetAllVenues() ([]Venue, error)
+	UpdateVenue(venue *Venue) error
+	DeleteVenue(venue *Venue) error
+	CreateRoom(room *Room) error
+	GetRoomByID(id uint) (*Room, error)
+	UpdateRoom(room *Room) error
+	DeleteRoom(room *Room) error
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+// CreateVenue implements Repository.
+func (r *repository) CreateVenue(venue *Venue) error {
+	return r.db.Create(venue).Error
+}
+
+// GetVenueByID implements Repository.
+func (r *repository) GetVenueByID(id uint) (*Venue, error) {
+	var venue Venue
+	if err := r.db.Preload("Rooms").Where("id = ?", id).First(&venue).Error; err != nil {
+		return nil, err
+	}
+	return &venue, nil
+}
+
+// GetAllVenues implements Repository.
+func (r *repository) GetAllVenues() ([]Venue, error) {
+	var venues []Venue
+	err := r.db.Preload("Rooms").Order("name asc").Find(&venues).Error
+	return venues, err
+}
+
+// UpdateVenue implements Repository.
+func (r *repository) UpdateVenue(venue *Venue) error {
+	return r.db.Omit("Rooms").Save(venue).Error
+}
+
+// DeleteVenue implements Repository.
+// Room milik venue ikut dihapus dalam satu transaksi
+func (r *repository) DeleteVenue(venue *Venue) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("venue_id = ?", venue.ID).Delete(&Room{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(venue).Error
+	})
+}
+
+// CreateRoom implements Repository.
+func (r *repository) CreateRoom(room *Room) error {
+	return r.db.Create(room).Error
+}
+
+// GetRoomByID implements Repository.
+func (r *repository) GetRoomByID(id uint) (*Room, error) {
+	var room Room
+	if err := r.db.Where("id = ?", id).First(&room).Error; err != nil {
+		return nil, err
+	}
+	return &room, nil
+}
+
+// UpdateRoom implements Repository.
+func (r *repository) UpdateRoom(room *Room) error {
+	return r.db.Save(room).Error
+}
+
+// DeleteRoom implements Repository.
+func (r *repository) DeleteRoom(room *Room) error {
+	return r.db.Delete(room).Error
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
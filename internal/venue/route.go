@@ -0,0 +1,25 @@
+package venue
+
+import (
+	"go-event/pkg/config"
+	"go-event/pkg/middlewares"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func SetupVenueRoutes(app *fiber.App, ctrl *Controller, cfg *config.Config) {
+	venue := app.Group("/api/venue")
+
+	// Katalog venue bisa dilihat semua user yang login
+	venue.Get("/", middlewares.Authenticate(cfg), ctrl.GetAllVenues)
+	venue.Get("/:id", middlewares.Authenticate(cfg), ctrl.GetVenueByID)
+	venue.Get("/room/:id/availability", middlewares.Authenticate(cfg), ctrl.GetRoomAvailability)
+
+	// Pengelolaan venue & room hanya untuk organizer dan admin
+	venue.Post("/", middlewares.Authenticate(cfg), middlewares.Authorize("organizer", "admin"), ctrl.CreateVenue)
+	venue.Put("/:id", middlewares.Authenticate(cfg), middlewares.Authorize("organizer", "admin"), ctrl.UpdateVenue)
+	venue.Delete("/:id", middlewares.Authenticate(cfg), middlewares.Authorize("organizer", "admin"), ctrl.DeleteVenue)
+	venue.Post("/:id/room", middlewares.Authenticate(cfg), middlewares.Authorize("organizer", "admin"), ctrl.CreateRoom)
+	venue.Put("/room/:id", middlewares.Authenticate(cfg), middlewares.Authorize("organizer", "admin"), ctrl.UpdateRoom)
+	venue.Delete("/room/:id", middlewares.Authenticate(cfg), middlewares.Authorize("organizer", "admin"), ctrl.DeleteRoom)
+}
@@ -0,0 +1,312 @@
+package venue
+
+import (
+	"errors"
+	"fmt"
+	"go-event/pkg/apperror"
+	"go-event/pkg/config"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// maxAvailabilityRange membatasi rentang query availability agar tidak terlalu berat
+const maxAvailabilityRange = 90 * 24 * time.Hour
+
+type Service interface {
+	CreateVenue(userID uint, req *CreateVenueRequest) (*VenueResponse, error)
+	GetAllVenues() ([]VenueResponse, error)
+	GetVenueByID(id uint) (*VenueResponse, error)
+	UpdateVenue(userID uint, userRole string, id uint, req *UpdateVenueRequest) (*VenueResponse, error)
+	DeleteVenue(userID uint, userRole string, id uint) error
+	CreateRoom(userID uint, userRole string, venueID uint, req *CreateRoomRequest) (*RoomResponse, error)
+	UpdateRoom(userID uint, userRole string, roomID uint, req *UpdateRoomRequest) (*RoomResponse, error)
+	DeleteRoom(userID uint, userRole string, roomID uint) error
+	GetRoomAvailability(roomID uint, query *AvailabilityQuery) (*AvailabilityResponse, error)
+}
+
+type service struct {
+	repo        Repository
+	bookingRepo BookingRepository
+	cfg         *config.Config
+}
+
+// CreateVenue implements Service.
+func (s *service) CreateVenue(userID uint, req *CreateVenueRequest) (*VenueResponse, error) {
+	venue := &Venue{
+		Name:      req.Name,
+		Address:   req.Address,
+		Latitude:  req.Latitude,
+		Longitude: req.Longitude,
+		Amenities: req.Amenities,
+		CreatedBy: userID,
+	}
+	if err := s.repo.CreateVenue(venue); err != nil {
+		return nil, apperror.Internal(fmt.Errorf("failed to create venue: %w", err))
+	}
+	return venue.ToResponse(), nil
+}
+
+// GetAllVenues implements Service.
+func (s *service) GetAllVenues() ([]VenueResponse, error) {
+	venues, err := s.repo.GetAllVenues()
+	if err != nil {
+		return nil, apperror.Internal(err)
+	}
+	responses := make([]VenueResponse, 0, len(venues))
+	for _, venue := range venues {
+		responses = append(responses, *venue.ToResponse())
+	}
+	return responses, nil
+}
+
+// GetVenueByID implements Service.
+func (s *service) GetVenueByID(id uint) (*VenueResponse, error) {
+	venue, err := s.getVenue(id)
+	if err != nil {
+		return nil, err
+	}
+	return venue.ToResponse(), nil
+}
+
+// UpdateVenue implements Service.
+func (s *service) UpdateVenue(userID uint, userRole string, id uint, req *UpdateVenueRequest) (*VenueResponse, error) {
+	venue, err := s.getOwnedVenue(userID, userRole, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		venue.Name = *req.Name
+	}
+	if req.Address != nil {
+		venue.Address = *req.Address
+	}
+	if req.Latitude != nil {
+		venue.Latitude = req.Latitude
+	}
+	if req.Longitude != nil {
+		venue.Longitude = req.Longitude
+	}
+	if req.Amenities != nil {
+		venue.Amenities = *req.Amenities
+	}
+
+	if err := s.repo.UpdateVenue(venue); err != nil {
+		return nil, apperror.Internal(err)
+	}
+	return venue.ToResponse(), nil
+}
+
+// DeleteVenue implements Service.
+// Venue yang room-nya masih dipakai event tidak bisa dihapus
+func (s *service) DeleteVenue(userID uint, userRole string, id uint) error {
+	venue, err := s.getOwnedVenue(userID, userRole, id)
+	if err != nil {
+		return err
+	}
+
+	roomIDs := make([]uint, 0, len(venue.Rooms))
+	for _, room := range venue.Rooms {
+		roomIDs = append(roomIDs, room.ID)
+	}
+	if err := s.ensureNotBooked(roomIDs...); err != nil {
+		return err
+	}
+
+	if err := s.repo.DeleteVenue(venue); err != nil {
+		return apperror.Internal(err)
+	}
+	return nil
+}
+
+// CreateRoom implements Service.
+func (s *service) CreateRoom(userID uint, userRole string, venueID uint, req *CreateRoomRequest) (*RoomResponse, error) {
+	venue, err := s.getOwnedVenue(userID, userRole, venueID)
+	if err != nil {
+		return nil, err
+	}
+
+	room := &Room{
+		VenueID:   venue.ID,
+		Name:      req.Name,
+		Capacity:  req.Capacity,
+		Amenities: req.Amenities,
+	}
+	if err := s.repo.CreateRoom(room); err != nil {
+		return nil, apperror.Internal(fmt.Errorf("failed to create room: %w", err))
+	}
+	return room.ToResponse(), nil
+}
+
+// UpdateRoom implements Service.
+func (s *service) UpdateRoom(userID uint, userRole string, roomID uint, req *UpdateRoomRequest) (*RoomResponse, error) {
+	room, err := s.getOwnedRoom(userID, userRole, roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		room.Name = *req.Name
+	}
+	if req.Capacity != nil {
+		room.Capacity = *req.Capacity
+	}
+	if req.Amenities != nil {
+		room.Amenities = *req.Amenities
+	}
+
+	if err := s.repo.UpdateRoom(room); err != nil {
+		return nil, apperror.Internal(err)
+	}
+	return room.ToResponse(), nil
+}
+
+// DeleteRoom implements Service.
+func (s *service) DeleteRoom(userID uint, userRole string, roomID uint) error {
+	room, err := s.getOwnedRoom(userID, userRole, roomID)
+	if err != nil {
+		return err
+	}
+	if err := s.ensureNotBooked(room.ID); err != nil {
+		return err
+	}
+	if err := s.repo.DeleteRoom(room); err != nil {
+		return apperror.Internal(err)
+	}
+	return nil
+}
+
+// GetRoomAvailability implements Service.
+// Mengembalikan booking yang ada di rentang [from, to) dan slot kosong di antaranya
+func (s *service) GetRoomAvailability(roomID uint, query *AvailabilityQuery) (*AvailabilityResponse, error) {
+	if _, err := s.getRoom(roomID); err != nil {
+		return nil, err
+	}
+	if query.To.Sub(query.From) > maxAvailabilityRange {
+		return nil, ErrRangeTooLong
+	}
+
+	bookings, err := s.bookingRepo.FindBookings(roomID, query.From, query.To)
+	if err != nil {
+		return nil, apperror.Internal(err)
+	}
+
+	minDuration := time.Duration(query.MinDuration) * time.Minute
+	return &AvailabilityResponse{
+		RoomID:    roomID,
+		From:      query.From,
+		To:        query.To,
+		Bookings:  bookings,
+		FreeSlots: freeSlots(query.From, query.To, bookings, minDuration),
+	}, nil
+}
+
+// freeSlots menghitung celah kosong di [from, to) setelah dikurangi semua booking.
+// Booking boleh saling tumpang tindih (data lama sebelum ada conflict detection)
+func freeSlots(from, to time.Time, bookings []Booking, minDuration time.Duration) []Slot {
+	sorted := make([]Booking, len(bookings))
+	copy(sorted, bookings)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].StartTime.Before(sorted[j].StartTime)
+	})
+
+	slots := []Slot{}
+	cursor := from
+	addSlot := func(start, end time.Time) {
+		if end.Sub(start) > 0 && end.Sub(start) >= minDuration {
+			slots = append(slots, Slot{StartTime: start, EndTime: end})
+		}
+	}
+
+	for _, b := range sorted {
+		if b.StartTime.After(cursor) {
+			addSlot(cursor, minTime(b.StartTime, to))
+		}
+		if b.EndTime.After(cursor) {
+			cursor = b.EndTime
+		}
+		if !cursor.Before(to) {
+			return slots
+		}
+	}
+	addSlot(cursor, to)
+	return slots
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+// ensureNotBooked menolak penghapusan room yang masih direferensikan event
+func (s *service) ensureNotBooked(roomIDs ...uint) error {
+	if len(roomIDs) == 0 {
+		return nil
+	}
+	booked, err := s.bookingRepo.HasBookings(roomIDs...)
+	if err != nil {
+		return apperror.Internal(err)
+	}
+	if booked {
+		return ErrVenueInUse
+	}
+	return nil
+}
+
+func (s *service) getVenue(id uint) (*Venue, error) {
+	venue, err := s.repo.GetVenueByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrVenueNotFound
+		}
+		return nil, apperror.Internal(err)
+	}
+	return venue, nil
+}
+
+func (s *service) getRoom(id uint) (*Room, error) {
+	room, err := s.repo.GetRoomByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRoomNotFound
+		}
+		return nil, apperror.Internal(err)
+	}
+	return room, nil
+}
+
+// getOwnedVenue mengambil venue dan memastikan user adalah pembuatnya (atau admin)
+func (s *service) getOwnedVenue(userID uint, userRole string, id uint) (*Venue, error) {
+	venue, err := s.getVenue(id)
+	if err != nil {
+		return nil, err
+	}
+	if userRole != "admin" && venue.CreatedBy != userID {
+		return nil, ErrNotOwner
+	}
+	return venue, nil
+}
+
+// getOwnedRoom mengambil room dan memastikan user boleh mengubah venue-nya
+func (s *service) getOwnedRoom(userID uint, userRole string, id uint) (*Room, error) {
+	room, err := s.getRoom(id)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.getOwnedVenue(userID, userRole, room.VenueID); err != nil {
+		return nil, err
+	}
+	return room, nil
+}
+
+func NewService(repo Repository, bookingRepo BookingRepository, cfg *config.Config) Service {
+	return &service{
+		repo:        repo,
+		bookingRepo: bookingRepo,
+		cfg:         cfg,
+	}
+}
@@ -0,0 +1,211 @@
+package webhook
+
+import (
+	"go-event/pkg/apperror"
+	"go-event/pkg/config"
+	"go-event/pkg/pagination"
+	"go-event/pkg/validation"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type Controller struct {
+	service Service
+	cfg     *config.Config
+}
+
+func NewController(service Service, cfg *config.Config) *Controller {
+	return &Controller{service: service, cfg: cfg}
+}
+
+// CreateWebhook - daftarkan endpoint webhook baru, secret hanya dikirim di response ini
+func (ctrl *Controller) CreateWebhook(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	userRole := c.Locals("userRole").(string)
+
+	var req CreateWebhookRequest
+	if err := c.BodyParser(&req); err != nil {
+		return apperror.ErrInvalidBody
+	}
+	if err := validation.Struct(&req); err != nil {
+		return err
+	}
+
+	webhook, err := ctrl.service.CreateWebhook(userID, userRole, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "webhook created successfully",
+		"webhook": webhook,
+	})
+}
+
+func (ctrl *Controller) GetWebhooks(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	webhooks, err := ctrl.service.GetWebhooks(userID)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message":  "webhooks retrieved successfully",
+		"webhooks": webhooks,
+	})
+}
+
+func (ctrl *Controller) GetWebhook(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	userRole := c.Locals("userRole").(string)
+
+	webhookID, err := parseID(c, "id", "webhook ID")
+	if err != nil {
+		return err
+	}
+
+	webhook, err := ctrl.service.GetWebhook(userID, userRole, webhookID)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "webhook retrieved successfully",
+		"webhook": webhook,
+	})
+}
+
+func (ctrl *Controller) UpdateWebhook(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	userRole := c.Locals("userRole").(string)
+
+	webhookID, err := parseID(c, "id", "webhook ID")
+	if err != nil {
+		return err
+	}
+	var req UpdateWebhookRequest
+	if err := c.BodyParser(&req); err != nil {
+		return apperror.ErrInvalidBody
+	}
+	if err := validation.Struct(&req); err != nil {
+		return err
+	}
+
+	webhook, err := ctrl.service.UpdateWebhook(userID, userRole, webhookID, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "webhook updated successfully",
+		"webhook": webhook,
+	})
+}
+
+func (ctrl *Controller) DeleteWebhook(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	userRole := c.Locals("userRole").(string)
+
+	webhookID, err := parseID(c, "id", "webhook ID")
+	if err != nil {
+		return err
+	}
+
+	if err := ctrl.service.DeleteWebhook(userID, userRole, webhookID); err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "webhook deleted successfully",
+	})
+}
+
+// RotateSecret - ganti secret HMAC, secret baru hanya dikirim di response ini
+func (ctrl *Controller) RotateSecret(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	userRole := c.Locals("userRole").(string)
+
+	webhookID, err := parseID(c, "id", "webhook ID")
+	if err != nil {
+		return err
+	}
+
+	webhook, err := ctrl.service.RotateSecret(userID, userRole, webhookID)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "webhook secret rotated successfully",
+		"webhook": webhook,
+	})
+}
+
+// GetDeliveries - log pengiriman webhook, terbaru dulu, bisa difilter ?status=
+func (ctrl *Controller) GetDeliveries(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	userRole := c.Locals("userRole").(string)
+
+	webhookID, err := parseID(c, "id", "webhook ID")
+	if err != nil {
+		return err
+	}
+	var page pagination.Params
+	if err := c.QueryParser(&page); err != nil {
+		return apperror.ErrInvalidParam
+	}
+	if err := validation.Struct(&page); err != nil {
+		return err
+	}
+	var query DeliveryQuery
+	if err := c.QueryParser(&query); err != nil {
+		return apperror.ErrInvalidParam
+	}
+	if err := validation.Struct(&query); err != nil {
+		return err
+	}
+
+	result, err := ctrl.service.GetDeliveries(userID, userRole, webhookID, &query, page)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message":    "webhook deliveries retrieved successfully",
+		"deliveries": result.Deliveries,
+		"pagination": result.Pagination,
+	})
+}
+
+// SendTest - kirim payload webhook.test sekarang dan kembalikan hasilnya
+func (ctrl *Controller) SendTest(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	userRole := c.Locals("userRole").(string)
+
+	webhookID, err := parseID(c, "id", "webhook ID")
+	if err != nil {
+		return err
+	}
+
+	delivery, err := ctrl.service.SendTest(userID, userRole, webhookID)
+	if err != nil {
+		return err
+	}
+
+	// Endpoint yang gagal tetap 200, hasilnya ada di delivery.status
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message":  "test delivery sent",
+		"delivery": delivery,
+	})
+}
+
+// parseID membaca path param sebagai ID
+func parseID(c *fiber.Ctx, param, name string) (uint, error) {
+	id, err := strconv.ParseUint(c.Params(param), 10, 32)
+	if err != nil {
+		return 0, apperror.InvalidParam(name)
+	}
+	return uint(id), nil
+}
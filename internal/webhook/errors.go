@@ -0,0 +1,11 @@
+package webhook
+
+import "go-event/pkg/apperror"
+
+var (
+	ErrWebhookNotFound  = apperror.New(apperror.KindNotFound, "WEBHOOK_NOT_FOUND", "webhook not found")
+	ErrForbidden        = apperror.New(apperror.KindForbidden, "WEBHOOK_FORBIDDEN", "only the webhook owner can manage this webhook")
+	ErrInvalidURL       = apperror.New(apperror.KindValidation, "WEBHOOK_INVALID_URL", "webhook url must be an absolute http or https url")
+	ErrBlockedURL       = apperror.New(apperror.KindValidation, "WEBHOOK_BLOCKED_URL", "webhook url must resolve to a public address")
+	ErrUnknownEventType = apperror.New(apperror.KindValidation, "WEBHOOK_UNKNOWN_EVENT_TYPE", "unknown webhook event type")
+)
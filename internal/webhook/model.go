@@ -0,0 +1,181 @@
+package webhook
+
+import (
+	"encoding/json"
+	"go-event/pkg/pagination"
+	"time"
+)
+
+type DeliveryStatus string
+
+const (
+	DeliveryPending DeliveryStatus = "pending"
+	DeliverySuccess DeliveryStatus = "success"
+	DeliveryFailed  DeliveryStatus = "failed"
+)
+
+// TypeTest adalah nama event untuk endpoint "send test", tidak bisa di-subscribe
+const TypeTest = "webhook.test"
+
+// 🧱 Entity (database model)
+
+// Webhook adalah endpoint milik organizer yang menerima domain event dari event-event
+// yang dia kelola. Webhook milik admin (AllEvents) menerima event dari semua organizer
+type Webhook struct {
+	ID          uint   `gorm:"primaryKey"`
+	OwnerID     uint   `gorm:"index"`
+	URL         string `gorm:"size:500"`
+	Description string `gorm:"size:255"`
+	// Secret untuk tanda tangan HMAC, hanya ditampilkan saat dibuat / di-rotate
+	Secret string `gorm:"size:100"`
+	// Kosong = semua event
+	EventTypes []string `gorm:"serializer:json;type:text"`
+	AllEvents  bool
+	Active     bool `gorm:"index"`
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// Subscribes mengecek apakah webhook berlangganan event eventType
+func (w *Webhook) Subscribes(eventType string) bool {
+	if len(w.EventTypes) == 0 {
+		return true
+	}
+	for _, t := range w.EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery adalah satu payload untuk satu webhook beserta hasil percobaan terakhir.
+// Payload disimpan apa adanya agar retry mengirim body yang sama persis
+type WebhookDelivery struct {
+	ID        uint   `gorm:"primaryKey"`
+	WebhookID uint   `gorm:"index"`
+	EventType string `gorm:"size:64"`
+	// EventUID sama untuk semua webhook yang menerima domain event yang sama,
+	// dipakai penerima untuk deduplikasi
+	EventUID     string         `gorm:"size:64"`
+	Payload      string         `gorm:"type:text"`
+	Status       DeliveryStatus `gorm:"size:16;index"`
+	Attempts     int
+	ResponseCode int
+	ResponseBody string `gorm:"size:1024"`
+	Error        string `gorm:"size:255"`
+	DurationMs   int64
+	// Waktu percobaan berikutnya untuk delivery pending, juga dipakai sebagai lease
+	// agar satu delivery tidak dikirim dua proses bersamaan
+	NextAttemptAt *time.Time `gorm:"index"`
+	DeliveredAt   *time.Time
+	CreatedAt     time.Time `gorm:"index"`
+	UpdatedAt     time.Time
+}
+
+// Payload adalah body JSON yang dikirim ke webhook
+type Payload struct {
+	ID        string      `json:"id"`
+	Type      string      `json:"type"`
+	CreatedAt time.Time   `json:"created_at"`
+	EventID   uint        `json:"event_id,omitempty"`
+	Data      interface{} `json:"data"`
+}
+
+// 📩 Request structs
+type CreateWebhookRequest struct {
+	URL         string   `json:"url" validate:"required,url,max=500"`
+	Description string   `json:"description" validate:"max=255"`
+	EventTypes  []string `json:"event_types" validate:"omitempty,max=20,dive,required"`
+	Active      *bool    `json:"active"` // default true
+}
+
+type UpdateWebhookRequest struct {
+	URL         *string  `json:"url" validate:"omitempty,url,max=500"`
+	Description *string  `json:"description" validate:"omitempty,max=255"`
+	EventTypes  []string `json:"event_types" validate:"omitempty,max=20,dive,required"` // nil = tidak diubah, [] = semua event
+	Active      *bool    `json:"active"`
+}
+
+// DeliveryQuery filter log delivery
+type DeliveryQuery struct {
+	Status string `query:"status" validate:"omitempty,oneof=pending success failed"`
+}
+
+// 📤 Response structs
+type WebhookResponse struct {
+	ID          uint      `json:"id"`
+	OwnerID     uint      `json:"owner_id"`
+	URL         string    `json:"url"`
+	Description string    `json:"description"`
+	EventTypes  []string  `json:"event_types"`
+	AllEvents   bool      `json:"all_events"`
+	Active      bool      `json:"active"`
+	Secret      string    `json:"secret,omitempty"` // hanya saat dibuat / di-rotate
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func (w *Webhook) ToResponse() WebhookResponse {
+	eventTypes := w.EventTypes
+	if eventTypes == nil {
+		eventTypes = []string{}
+	}
+	return WebhookResponse{
+		ID:          w.ID,
+		OwnerID:     w.OwnerID,
+		URL:         w.URL,
+		Description: w.Description,
+		EventTypes:  eventTypes,
+		AllEvents:   w.AllEvents,
+		Active:      w.Active,
+		CreatedAt:   w.CreatedAt,
+		UpdatedAt:   w.UpdatedAt,
+	}
+}
+
+type DeliveryResponse struct {
+	ID            uint            `json:"id"`
+	WebhookID     uint            `json:"webhook_id"`
+	EventType     string          `json:"event_type"`
+	EventUID      string          `json:"event_uid"`
+	Payload       json.RawMessage `json:"payload"`
+	Status        DeliveryStatus  `json:"status"`
+	Attempts      int             `json:"attempts"`
+	ResponseCode  int             `json:"response_code,omitempty"`
+	ResponseBody  string          `json:"response_body,omitempty"`
+	Error         string          `json:"error,omitempty"`
+	DurationMs    int64           `json:"duration_ms"`
+	NextAttemptAt *time.Time      `json:"next_attempt_at,omitempty"`
+	DeliveredAt   *time.Time      `json:"delivered_at,omitempty"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+func (d *WebhookDelivery) ToResponse() DeliveryResponse {
+	response := DeliveryResponse{
+		ID:           d.ID,
+		WebhookID:    d.WebhookID,
+		EventType:    d.EventType,
+		EventUID:     d.EventUID,
+		Payload:      json.RawMessage(d.Payload),
+		Status:       d.Status,
+		Attempts:     d.Attempts,
+		ResponseCode: d.ResponseCode,
+		ResponseBody: d.ResponseBody,
+		Error:        d.Error,
+		DurationMs:   d.DurationMs,
+		DeliveredAt:  d.DeliveredAt,
+		CreatedAt:    d.CreatedAt,
+	}
+	// next_attempt_at hanya berarti untuk delivery yang masih akan dicoba lagi
+	if d.Status == DeliveryPending {
+		response.NextAttemptAt = d.NextAttemptAt
+	}
+	return response
+}
+
+// DeliveryPage adalah satu halaman log delivery, terbaru dulu
+type DeliveryPage struct {
+	Deliveries []DeliveryResponse `json:"deliveries"`
+	Pagination pagination.Meta    `json:"pagination"`
+}
@@ -0,0 +1,129 @@
+package webhook
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Repository interface {
+	Create(webhook *Webhook) error
+	GetByID(id uint) (*Webhook, error)
+	FindByOwner(ownerID uint) ([]Webhook, error)
+	FindActiveFor(organizerID uint) ([]Webhook, error)
+	Update(webhook *Webhook) error
+	Delete(webhook *Webhook) error
+	CreateDelivery(delivery *WebhookDelivery) error
+	FindDeliveries(webhookID uint, status string, offset, limit int) ([]WebhookDelivery, int64, error)
+	FindDueDeliveries(now time.Time, limit int) ([]WebhookDelivery, error)
+	ClaimDelivery(id uint, now, leaseUntil time.Time) (bool, error)
+	SaveAttempt(delivery *WebhookDelivery) error
+	PurgeDeliveries(before time.Time) (int64, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+// Create implements Repository.
+func (r *repository) Create(webhook *Webhook) error {
+	return r.db.Create(webhook).Error
+}
+
+// GetByID implements Repository.
+func (r *repository) GetByID(id uint) (*Webhook, error) {
+	var webhook Webhook
+	if err := r.db.Where("id = ?", id).Take(&webhook).Error; err != nil {
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+// FindByOwner implements Repository.
+func (r *repository) FindByOwner(ownerID uint) ([]Webhook, error) {
+	var webhooks []Webhook
+	err := r.db.Where("owner_id = ?", ownerID).Order("created_at desc").Find(&webhooks).Error
+	return webhooks, err
+}
+
+// FindActiveFor implements Repository.
+// Webhook aktif milik organizer ditambah webhook admin yang menerima semua event.
+// Filter event type dilakukan di service karena disimpan sebagai JSON
+func (r *repository) FindActiveFor(organizerID uint) ([]Webhook, error) {
+	var webhooks []Webhook
+	err := r.db.Where("active = ? AND (owner_id = ? OR all_events = ?)", true, organizerID, true).Find(&webhooks).Error
+	return webhooks, err
+}
+
+// Update implements Repository.
+func (r *repository) Update(webhook *Webhook) error {
+	return r.db.Save(webhook).Error
+}
+
+// Delete implements Repository.
+// Log delivery ikut dihapus
+func (r *repository) Delete(webhook *Webhook) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("webhook_id = ?", webhook.ID).Delete(&WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(webhook).Error
+	})
+}
+
+// CreateDelivery implements Repository.
+func (r *repository) CreateDelivery(delivery *WebhookDelivery) error {
+	return r.db.Create(delivery).Error
+}
+
+// FindDeliveries implements Repository.
+func (r *repository) FindDeliveries(webhookID uint, status string, offset, limit int) ([]WebhookDelivery, int64, error) {
+	query := r.db.Model(&WebhookDelivery{}).Where("webhook_id = ?", webhookID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var deliveries []WebhookDelivery
+	err := query.Order("id desc").Offset(offset).Limit(limit).Find(&deliveries).Error
+	return deliveries, total, err
+}
+
+// FindDueDeliveries implements Repository.
+func (r *repository) FindDueDeliveries(now time.Time, limit int) ([]WebhookDelivery, error) {
+	var deliveries []WebhookDelivery
+	err := r.db.Where("status = ? AND next_attempt_at <= ?", DeliveryPending, now).
+		Order("next_attempt_at").Limit(limit).Find(&deliveries).Error
+	return deliveries, err
+}
+
+// ClaimDelivery implements Repository.
+// Memundurkan next_attempt_at ke leaseUntil hanya jika delivery masih pending dan sudah
+// jatuh tempo, false jika sudah diambil proses lain
+func (r *repository) ClaimDelivery(id uint, now, leaseUntil time.Time) (bool, error) {
+	result := r.db.Model(&WebhookDelivery{}).
+		Where("id = ? AND status = ? AND next_attempt_at <= ?", id, DeliveryPending, now).
+		Update("next_attempt_at", leaseUntil)
+	return result.RowsAffected == 1, result.Error
+}
+
+// SaveAttempt implements Repository.
+// Menyimpan hasil satu percobaan pengiriman
+func (r *repository) SaveAttempt(delivery *WebhookDelivery) error {
+	return r.db.Model(delivery).
+		Select("status", "attempts", "response_code", "response_body", "error", "duration_ms", "next_attempt_at", "delivered_at").
+		Updates(delivery).Error
+}
+
+// PurgeDeliveries implements Repository.
+// Menghapus log delivery yang sudah selesai (success/failed) sebelum waktu before
+func (r *repository) PurgeDeliveries(before time.Time) (int64, error) {
+	result := r.db.Where("status <> ? AND created_at < ?", DeliveryPending, before).Delete(&WebhookDelivery{})
+	return result.RowsAffected, result.Error
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
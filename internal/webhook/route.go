@@ -0,0 +1,22 @@
+package webhook
+
+import (
+	"go-event/pkg/config"
+	"go-event/pkg/middlewares"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func SetupWebhookRoutes(app *fiber.App, ctrl *Controller, cfg *config.Config) {
+	webhooks := app.Group("/api/webhooks")
+
+	// Organizer mengelola webhook miliknya, admin boleh mengelola semua webhook
+	webhooks.Post("/", middlewares.Authenticate(cfg), middlewares.Authorize("organizer", "admin"), ctrl.CreateWebhook)
+	webhooks.Get("/", middlewares.Authenticate(cfg), middlewares.Authorize("organizer", "admin"), ctrl.GetWebhooks)
+	webhooks.Get("/:id", middlewares.Authenticate(cfg), middlewares.Authorize("organizer", "admin"), ctrl.GetWebhook)
+	webhooks.Put("/:id", middlewares.Authenticate(cfg), middlewares.Authorize("organizer", "admin"), ctrl.UpdateWebhook)
+	webhooks.Delete("/:id", middlewares.Authenticate(cfg), middlewares.Authorize("organizer", "admin"), ctrl.DeleteWebhook)
+	webhooks.Post("/:id/rotate-secret", middlewares.Authenticate(cfg), middlewares.Authorize("organizer", "admin"), ctrl.RotateSecret)
+	webhooks.Get("/:id/deliveries", middlewares.Authenticate(cfg), middlewares.Authorize("organizer", "admin"), ctrl.GetDeliveries)
+	webhooks.Post("/:id/test", middlewares.Authenticate(cfg), middlewares.Authorize("organizer", "admin"), ctrl.SendTest)
+}
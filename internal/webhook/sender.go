@@ -0,0 +1,177 @@
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Header yang dikirim bersama setiap payload
+const (
+	HeaderSignature = "X-GoEvent-Signature"
+	HeaderEvent     = "X-GoEvent-Event"
+	HeaderDelivery  = "X-GoEvent-Delivery"
+)
+
+const (
+	// requestTimeout adalah batas satu request ke endpoint webhook
+	requestTimeout = 10 * time.Second
+	// maxResponseBody adalah panjang body response yang disimpan di log delivery
+	maxResponseBody = 1024
+	// resolveTimeout adalah batas lookup DNS saat memvalidasi URL webhook
+	resolveTimeout = 5 * time.Second
+)
+
+// errBlockedAddress dikembalikan dialer jika endpoint resolve ke alamat internal
+var errBlockedAddress = errors.New("webhook endpoint resolves to a non-public address")
+
+// blockedNetworks adalah range internal yang tidak tercakup method net.IP:
+// CGNAT (dipakai beberapa cloud untuk alamat internal), "this network", dan NAT64
+// yang meneruskan ke alamat IPv4 mana pun
+var blockedNetworks = parseCIDRs(
+	"100.64.0.0/10",
+	"0.0.0.0/8",
+	"64:ff9b::/96",
+)
+
+// isBlockedIP: alamat yang tidak boleh dituju webhook (loopback, jaringan privat,
+// link-local termasuk metadata cloud 169.254.169.254, unspecified, multicast,
+// dan blockedNetworks)
+func isBlockedIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() ||
+		ip.IsMulticast() || ip.IsUnspecified() {
+		return true
+	}
+	for _, network := range blockedNetworks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func parseCIDRs(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
+
+// dialControl memeriksa alamat yang benar-benar di-dial, sehingga DNS rebinding
+// setelah validasi URL tetap tertolak
+func dialControl(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || isBlockedIP(ip) {
+		return errBlockedAddress
+	}
+	return nil
+}
+
+// sender mengirim payload ke endpoint webhook
+type sender struct {
+	client *http.Client
+}
+
+// attemptResult adalah hasil satu request. code 0 berarti tidak ada response
+// (timeout, DNS, koneksi ditolak, dll)
+type attemptResult struct {
+	code     int
+	body     string
+	err      error
+	duration time.Duration
+}
+
+func (r attemptResult) ok() bool {
+	return r.err == nil && r.code >= 200 && r.code < 300
+}
+
+func (r attemptResult) errorMessage() string {
+	if r.err != nil {
+		return r.err.Error()
+	}
+	return fmt.Sprintf("unexpected response status %d", r.code)
+}
+
+// post mengirim payload delivery yang ditandatangani dengan secret webhook saat ini
+func (s *sender) post(webhook *Webhook, delivery *WebhookDelivery) attemptResult {
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequest(http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return attemptResult{err: err}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "GoEvent-Webhook/1.0")
+	req.Header.Set(HeaderEvent, delivery.EventType)
+	req.Header.Set(HeaderDelivery, strconv.FormatUint(uint64(delivery.ID), 10))
+	req.Header.Set(HeaderSignature, Sign(webhook.Secret, time.Now().Unix(), body))
+
+	start := time.Now()
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return attemptResult{err: err, duration: time.Since(start)}
+	}
+	defer resp.Body.Close()
+
+	snippet, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	// Sisa body dibuang agar koneksi bisa dipakai ulang
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	return attemptResult{
+		code:     resp.StatusCode,
+		body:     strings.ToValidUTF8(string(snippet), ""),
+		duration: time.Since(start),
+	}
+}
+
+// Sign membuat nilai header X-GoEvent-Signature: "t=<unix>,v1=<hex>", dengan
+// v1 = HMAC-SHA256(secret, "<unix>.<body>"). Timestamp ikut ditandatangani agar
+// penerima bisa menolak payload lama yang dikirim ulang
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+	return fmt.Sprintf("t=%d,v1=%s", timestamp, hex.EncodeToString(mac.Sum(nil)))
+}
+
+func newSender() *sender {
+	dialer := &net.Dialer{
+		Timeout: requestTimeout,
+		Control: dialControl,
+	}
+	return &sender{
+		client: &http.Client{
+			Timeout: requestTimeout,
+			// Tanpa proxy: dialControl harus melihat alamat endpoint, bukan alamat proxy
+			Transport: &http.Transport{
+				DialContext:         dialer.DialContext,
+				TLSHandshakeTimeout: requestTimeout,
+				MaxIdleConns:        100,
+				IdleConnTimeout:     90 * time.Second,
+			},
+			// Redirect tidak diikuti: POST yang di-redirect berubah menjadi GET dan
+			// payload hilang, 3xx dicatat sebagai gagal
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
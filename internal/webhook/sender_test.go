@@ -0,0 +1,70 @@
+package webhook
+
+import (
+	"errors"
+	"net"
+	"testing"
+)
+
+func TestIsBlockedIP(t *testing.T) {
+	tests := []struct {
+		ip      string
+		blocked bool
+	}{
+		{"127.0.0.1", true},
+		{"::1", true},
+		{"10.1.2.3", true},
+		{"172.16.0.1", true},
+		{"192.168.1.1", true},
+		{"fd00::1", true},
+		{"169.254.169.254", true},
+		{"fe80::1", true},
+		{"0.0.0.0", true},
+		{"::", true},
+		{"0.1.2.3", true},
+		{"100.64.0.1", true},
+		{"100.127.255.254", true},
+		{"64:ff9b::a9fe:a9fe", true},
+		{"64:ff9b::808:808", true},
+		{"::ffff:127.0.0.1", true},
+		{"::ffff:100.64.0.1", true},
+		{"224.0.0.1", true},
+		{"ff02::1", true},
+		{"8.8.8.8", false},
+		{"100.63.255.255", false},
+		{"100.128.0.0", false},
+		{"1.0.0.0", false},
+		{"2001:4860:4860::8888", false},
+		{"::ffff:8.8.8.8", false},
+	}
+	for _, tt := range tests {
+		if got := isBlockedIP(net.ParseIP(tt.ip)); got != tt.blocked {
+			t.Errorf("isBlockedIP(%s) = %v, want %v", tt.ip, got, tt.blocked)
+		}
+	}
+}
+
+func TestDialControl(t *testing.T) {
+	tests := []struct {
+		address string
+		err     error
+	}{
+		{"127.0.0.1:80", errBlockedAddress},
+		{"169.254.169.254:80", errBlockedAddress},
+		{"100.100.100.200:80", errBlockedAddress},
+		{"0.0.0.0:443", errBlockedAddress},
+		{"[64:ff9b::a9fe:a9fe]:80", errBlockedAddress},
+		{"[::1]:443", errBlockedAddress},
+		{"localhost:80", errBlockedAddress},
+		{"93.184.216.34:443", nil},
+		{"[2606:2800:220:1::248]:443", nil},
+	}
+	for _, tt := range tests {
+		if err := dialControl("tcp", tt.address, nil); !errors.Is(err, tt.err) {
+			t.Errorf("dialControl(%s) = %v, want %v", tt.address, err, tt.err)
+		}
+	}
+	if err := dialControl("tcp", "93.184.216.34", nil); err == nil {
+		t.Error("dialControl without port should fail")
+	}
+}
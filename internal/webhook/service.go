@@ -0,0 +1,515 @@
+package webhook
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-event/internal/eventbus"
+	"go-event/internal/user"
+	"go-event/pkg/apperror"
+	"go-event/pkg/config"
+	"go-event/pkg/pagination"
+	"log"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Service interface {
+	CreateWebhook(requesterID uint, requesterRole string, req *CreateWebhookRequest) (*WebhookResponse, error)
+	GetWebhooks(requesterID uint) ([]WebhookResponse, error)
+	GetWebhook(requesterID uint, requesterRole string, webhookID uint) (*WebhookResponse, error)
+	UpdateWebhook(requesterID uint, requesterRole string, webhookID uint, req *UpdateWebhookRequest) (*WebhookResponse, error)
+	DeleteWebhook(requesterID uint, requesterRole string, webhookID uint) error
+	RotateSecret(requesterID uint, requesterRole string, webhookID uint) (*WebhookResponse, error)
+	GetDeliveries(requesterID uint, requesterRole string, webhookID uint, query *DeliveryQuery, page pagination.Params) (*DeliveryPage, error)
+	SendTest(requesterID uint, requesterRole string, webhookID uint) (*DeliveryResponse, error)
+	Dispatch(e eventbus.Event) error
+	RetryDeliveries(now time.Time) error
+	PurgeDeliveries(now time.Time) error
+}
+
+// retryBackoff adalah jeda sebelum percobaan ke-2, ke-3, dst. Setelah semua habis
+// delivery ditandai failed
+var retryBackoff = []time.Duration{
+	time.Minute,
+	5 * time.Minute,
+	30 * time.Minute,
+	2 * time.Hour,
+	6 * time.Hour,
+}
+
+const (
+	// claimLease adalah lama delivery dikunci selama dikirim, jauh di atas timeout HTTP
+	claimLease = time.Minute
+	// retryBatch adalah jumlah delivery yang dicoba ulang per putaran scheduler
+	retryBatch = 100
+	// retryWorkers membatasi jumlah request webhook paralel saat retry
+	retryWorkers = 10
+	// deliveryRetention adalah lama log delivery yang sudah selesai disimpan
+	deliveryRetention = 30 * 24 * time.Hour
+)
+
+type service struct {
+	repo   Repository
+	sender *sender
+	cfg    *config.Config
+}
+
+// CreateWebhook implements Service.
+// Webhook yang dibuat admin menerima event dari semua organizer
+func (s *service) CreateWebhook(requesterID uint, requesterRole string, req *CreateWebhookRequest) (*WebhookResponse, error) {
+	target, err := normalizeURL(req.URL)
+	if err != nil {
+		return nil, err
+	}
+	eventTypes, err := normalizeEventTypes(req.EventTypes)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := newSecret()
+	if err != nil {
+		return nil, apperror.Internal(err)
+	}
+
+	webhook := &Webhook{
+		OwnerID:     requesterID,
+		URL:         target,
+		Description: strings.TrimSpace(req.Description),
+		Secret:      secret,
+		EventTypes:  eventTypes,
+		AllEvents:   requesterRole == string(user.RoleAdmin),
+		Active:      req.Active == nil || *req.Active,
+	}
+	if err := s.repo.Create(webhook); err != nil {
+		return nil, apperror.Internal(fmt.Errorf("failed to create webhook: %w", err))
+	}
+
+	response := webhook.ToResponse()
+	response.Secret = webhook.Secret
+	return &response, nil
+}
+
+// GetWebhooks implements Service.
+func (s *service) GetWebhooks(requesterID uint) ([]WebhookResponse, error) {
+	webhooks, err := s.repo.FindByOwner(requesterID)
+	if err != nil {
+		return nil, apperror.Internal(err)
+	}
+	responses := make([]WebhookResponse, 0, len(webhooks))
+	for i := range webhooks {
+		responses = append(responses, webhooks[i].ToResponse())
+	}
+	return responses, nil
+}
+
+// GetWebhook implements Service.
+func (s *service) GetWebhook(requesterID uint, requesterRole string, webhookID uint) (*WebhookResponse, error) {
+	webhook, err := s.getOwnedWebhook(requesterID, requesterRole, webhookID)
+	if err != nil {
+		return nil, err
+	}
+	response := webhook.ToResponse()
+	return &response, nil
+}
+
+// UpdateWebhook implements Service.
+func (s *service) UpdateWebhook(requesterID uint, requesterRole string, webhookID uint, req *UpdateWebhookRequest) (*WebhookResponse, error) {
+	webhook, err := s.getOwnedWebhook(requesterID, requesterRole, webhookID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.URL != nil {
+		target, err := normalizeURL(*req.URL)
+		if err != nil {
+			return nil, err
+		}
+		webhook.URL = target
+	}
+	if req.Description != nil {
+		webhook.Description = strings.TrimSpace(*req.Description)
+	}
+	if req.EventTypes != nil {
+		eventTypes, err := normalizeEventTypes(req.EventTypes)
+		if err != nil {
+			return nil, err
+		}
+		webhook.EventTypes = eventTypes
+	}
+	if req.Active != nil {
+		webhook.Active = *req.Active
+	}
+
+	if err := s.repo.Update(webhook); err != nil {
+		return nil, apperror.Internal(err)
+	}
+	response := webhook.ToResponse()
+	return &response, nil
+}
+
+// DeleteWebhook implements Service.
+func (s *service) DeleteWebhook(requesterID uint, requesterRole string, webhookID uint) error {
+	webhook, err := s.getOwnedWebhook(requesterID, requesterRole, webhookID)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Delete(webhook); err != nil {
+		return apperror.Internal(err)
+	}
+	return nil
+}
+
+// RotateSecret implements Service.
+// Secret lama langsung tidak berlaku, termasuk untuk retry yang belum terkirim
+func (s *service) RotateSecret(requesterID uint, requesterRole string, webhookID uint) (*WebhookResponse, error) {
+	webhook, err := s.getOwnedWebhook(requesterID, requesterRole, webhookID)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := newSecret()
+	if err != nil {
+		return nil, apperror.Internal(err)
+	}
+	webhook.Secret = secret
+	if err := s.repo.Update(webhook); err != nil {
+		return nil, apperror.Internal(err)
+	}
+
+	response := webhook.ToResponse()
+	response.Secret = webhook.Secret
+	return &response, nil
+}
+
+// GetDeliveries implements Service.
+func (s *service) GetDeliveries(requesterID uint, requesterRole string, webhookID uint, query *DeliveryQuery, page pagination.Params) (*DeliveryPage, error) {
+	if _, err := s.getOwnedWebhook(requesterID, requesterRole, webhookID); err != nil {
+		return nil, err
+	}
+	page.Normalize()
+
+	deliveries, total, err := s.repo.FindDeliveries(webhookID, query.Status, page.Offset(), page.Limit())
+	if err != nil {
+		return nil, apperror.Internal(err)
+	}
+	responses := make([]DeliveryResponse, 0, len(deliveries))
+	for i := range deliveries {
+		responses = append(responses, deliveryResponse(&deliveries[i], requesterRole))
+	}
+	return &DeliveryPage{
+		Deliveries: responses,
+		Pagination: pagination.NewMeta(page, total),
+	}, nil
+}
+
+// SendTest implements Service.
+// Mengirim payload webhook.test sekali secara langsung (tanpa retry), juga untuk
+// webhook yang tidak aktif atau tidak berlangganan event apa pun
+func (s *service) SendTest(requesterID uint, requesterRole string, webhookID uint) (*DeliveryResponse, error) {
+	webhook, err := s.getOwnedWebhook(requesterID, requesterRole, webhookID)
+	if err != nil {
+		return nil, err
+	}
+
+	uid, err := newEventUID()
+	if err != nil {
+		return nil, apperror.Internal(err)
+	}
+	body, err := json.Marshal(Payload{
+		ID:        uid,
+		Type:      TypeTest,
+		CreatedAt: time.Now().UTC(),
+		Data: map[string]interface{}{
+			"webhook_id": webhook.ID,
+			"message":    "This is a test delivery from GoEvent",
+		},
+	})
+	if err != nil {
+		return nil, apperror.Internal(err)
+	}
+
+	delivery := &WebhookDelivery{
+		WebhookID: webhook.ID,
+		EventType: TypeTest,
+		EventUID:  uid,
+		Payload:   string(body),
+		Status:    DeliveryPending,
+	}
+	if err := s.repo.CreateDelivery(delivery); err != nil {
+		return nil, apperror.Internal(err)
+	}
+	s.attempt(webhook, delivery, false)
+
+	response := deliveryResponse(delivery, requesterRole)
+	return &response, nil
+}
+
+// Dispatch implements Service.
+// Subscriber semua domain event: membuat satu delivery untuk setiap webhook yang
+// berlangganan lalu langsung mencoba mengirimnya. Yang gagal dicoba ulang lewat RetryDeliveries
+func (s *service) Dispatch(e eventbus.Event) error {
+	meta := e.Metadata()
+	webhooks, err := s.repo.FindActiveFor(meta.OrganizerID)
+	if err != nil {
+		return fmt.Errorf("failed to find webhooks: %w", err)
+	}
+
+	var subscribed []Webhook
+	for _, webhook := range webhooks {
+		if webhook.Subscribes(string(e.Topic())) {
+			subscribed = append(subscribed, webhook)
+		}
+	}
+	if len(subscribed) == 0 {
+		return nil
+	}
+
+	uid, err := newEventUID()
+	if err != nil {
+		return fmt.Errorf("failed to generate event id: %w", err)
+	}
+	body, err := json.Marshal(Payload{
+		ID:        uid,
+		Type:      string(e.Topic()),
+		CreatedAt: meta.OccurredAt.UTC(),
+		EventID:   meta.EventID,
+		Data:      e,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode payload: %w", err)
+	}
+
+	for i := range subscribed {
+		webhook := &subscribed[i]
+		// Delivery langsung dikunci agar tidak ikut diambil RetryDeliveries sebelum
+		// percobaan pertama selesai
+		leaseUntil := time.Now().Add(claimLease)
+		delivery := &WebhookDelivery{
+			WebhookID:     webhook.ID,
+			EventType:     string(e.Topic()),
+			EventUID:      uid,
+			Payload:       string(body),
+			Status:        DeliveryPending,
+			NextAttemptAt: &leaseUntil,
+		}
+		if err := s.repo.CreateDelivery(delivery); err != nil {
+			log.Printf("webhook: failed to create delivery for webhook %d: %v", webhook.ID, err)
+			continue
+		}
+		go s.attempt(webhook, delivery, true)
+	}
+	return nil
+}
+
+// RetryDeliveries implements Service.
+// Dipanggil scheduler untuk delivery pending yang sudah jatuh tempo
+func (s *service) RetryDeliveries(now time.Time) error {
+	deliveries, err := s.repo.FindDueDeliveries(now, retryBatch)
+	if err != nil {
+		return fmt.Errorf("failed to get due webhook deliveries: %w", err)
+	}
+
+	var wg sync.WaitGroup
+	workers := make(chan struct{}, retryWorkers)
+	for i := range deliveries {
+		delivery := &deliveries[i]
+		claimed, err := s.repo.ClaimDelivery(delivery.ID, now, now.Add(claimLease))
+		if err != nil {
+			log.Printf("webhook: failed to claim delivery %d: %v", delivery.ID, err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		webhook, err := s.repo.GetByID(delivery.WebhookID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Printf("webhook: failed to get webhook %d: %v", delivery.WebhookID, err)
+			continue
+		}
+		// Webhook yang dinonaktifkan tidak dikirimi retry lagi
+		if webhook == nil || !webhook.Active {
+			s.abandon(delivery, "webhook is inactive")
+			continue
+		}
+
+		wg.Add(1)
+		workers <- struct{}{}
+		go func() {
+			defer func() {
+				<-workers
+				wg.Done()
+			}()
+			s.attempt(webhook, delivery, true)
+		}()
+	}
+	wg.Wait()
+	return nil
+}
+
+// PurgeDeliveries implements Service.
+func (s *service) PurgeDeliveries(now time.Time) error {
+	purged, err := s.repo.PurgeDeliveries(now.Add(-deliveryRetention))
+	if err != nil {
+		return err
+	}
+	if purged > 0 {
+		log.Printf("webhook: purged %d old deliveries", purged)
+	}
+	return nil
+}
+
+// attempt mengirim delivery sekali dan menyimpan hasilnya. Jika retry true, delivery yang
+// gagal dijadwalkan ulang sesuai retryBackoff
+func (s *service) attempt(webhook *Webhook, delivery *WebhookDelivery, retry bool) {
+	result := s.sender.post(webhook, delivery)
+
+	now := time.Now()
+	delivery.Attempts++
+	delivery.ResponseCode = result.code
+	delivery.ResponseBody = result.body
+	delivery.DurationMs = result.duration.Milliseconds()
+	delivery.Error = ""
+	delivery.NextAttemptAt = nil
+
+	switch {
+	case result.ok():
+		delivery.Status = DeliverySuccess
+		delivery.DeliveredAt = &now
+	case retry && delivery.Attempts <= len(retryBackoff):
+		next := now.Add(retryBackoff[delivery.Attempts-1])
+		delivery.Status = DeliveryPending
+		delivery.NextAttemptAt = &next
+	default:
+		delivery.Status = DeliveryFailed
+	}
+	if !result.ok() {
+		delivery.Error = truncate(result.errorMessage(), 255)
+	}
+
+	if err := s.repo.SaveAttempt(delivery); err != nil {
+		log.Printf("webhook: failed to save delivery %d: %v", delivery.ID, err)
+	}
+}
+
+// abandon menandai delivery gagal tanpa mengirimnya
+func (s *service) abandon(delivery *WebhookDelivery, reason string) {
+	delivery.Status = DeliveryFailed
+	delivery.Error = reason
+	delivery.NextAttemptAt = nil
+	if err := s.repo.SaveAttempt(delivery); err != nil {
+		log.Printf("webhook: failed to save delivery %d: %v", delivery.ID, err)
+	}
+}
+
+// getOwnedWebhook memastikan webhook ada dan requester adalah pemiliknya (atau admin)
+func (s *service) getOwnedWebhook(requesterID uint, requesterRole string, webhookID uint) (*Webhook, error) {
+	webhook, err := s.repo.GetByID(webhookID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWebhookNotFound
+		}
+		return nil, apperror.Internal(err)
+	}
+	if requesterRole != string(user.RoleAdmin) && webhook.OwnerID != requesterID {
+		return nil, ErrForbidden
+	}
+	return webhook, nil
+}
+
+// deliveryResponse menyembunyikan body response endpoint dari selain admin agar
+// webhook tidak bisa dipakai membaca isi layanan lain
+func deliveryResponse(delivery *WebhookDelivery, requesterRole string) DeliveryResponse {
+	response := delivery.ToResponse()
+	if requesterRole != string(user.RoleAdmin) {
+		response.ResponseBody = ""
+	}
+	return response
+}
+
+// normalizeURL memastikan URL absolut http/https yang host-nya resolve ke alamat publik.
+// Sender memeriksa ulang alamat saat dial karena hasil DNS bisa berubah
+func normalizeURL(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return "", ErrInvalidURL
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), resolveTimeout)
+	defer cancel()
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, u.Hostname())
+	if err != nil || len(addrs) == 0 {
+		return "", ErrInvalidURL.WithMessage("webhook url host cannot be resolved")
+	}
+	for _, addr := range addrs {
+		if isBlockedIP(addr.IP) {
+			return "", ErrBlockedURL
+		}
+	}
+	return raw, nil
+}
+
+// normalizeEventTypes memvalidasi event type dan membuang duplikat. Kosong = semua event
+func normalizeEventTypes(eventTypes []string) ([]string, error) {
+	normalized := make([]string, 0, len(eventTypes))
+	seen := make(map[string]bool, len(eventTypes))
+	for _, t := range eventTypes {
+		t = strings.TrimSpace(t)
+		if !eventbus.Topic(t).IsValid() {
+			return nil, ErrUnknownEventType.WithDetails(map[string]interface{}{
+				"event_type": t,
+				"allowed":    eventbus.Topics,
+			})
+		}
+		if !seen[t] {
+			seen[t] = true
+			normalized = append(normalized, t)
+		}
+	}
+	return normalized, nil
+}
+
+// newSecret membuat secret HMAC baru
+func newSecret() (string, error) {
+	return randomHex("whsec_", 32)
+}
+
+// newEventUID membuat ID payload, sama untuk semua webhook penerima event yang sama
+func newEventUID() (string, error) {
+	return randomHex("evt_", 16)
+}
+
+func randomHex(prefix string, size int) (string, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return prefix + hex.EncodeToString(b), nil
+}
+
+// truncate memotong s menjadi paling banyak max byte tanpa memotong karakter UTF-8
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	cut := max
+	for cut > 0 && s[cut]&0xC0 == 0x80 {
+		cut--
+	}
+	return s[:cut]
+}
+
+func NewService(repo Repository, cfg *config.Config) Service {
+	return &service{
+		repo:   repo,
+		sender: newSender(),
+		cfg:    cfg,
+	}
+}
@@ -0,0 +1,98 @@
+// Package apperror mendefinisikan error domain yang dipakai semua service.
+// Setiap error punya Kind (dipetakan ke HTTP status oleh middlewares.ErrorHandler)
+// dan Code yang stabil untuk dibaca oleh client.
+package apperror
+
+import "errors"
+
+type Kind int
+
+const (
+	KindInternal Kind = iota
+	KindBadRequest
+	KindUnauthorized
+	KindForbidden
+	KindNotFound
+	KindConflict
+	KindValidation
+	KindPaymentRequired
+)
+
+// Error adalah error domain dengan kode yang bisa dibaca mesin
+type Error struct {
+	Kind    Kind
+	Code    string      // contoh: EVENT_NOT_FOUND
+	Message string      // pesan yang aman ditampilkan ke client
+	Details interface{} // data tambahan opsional, ikut dikirim di response
+	Err     error       // penyebab asli, hanya untuk logging
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is membuat errors.Is(err, ErrXxx) tetap true walaupun error sudah
+// di-copy lewat Wrap / WithMessage / WithDetails
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// New membuat sentinel error baru
+func New(kind Kind, code, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+// Wrap mengembalikan copy error dengan penyebab asli
+func (e *Error) Wrap(err error) *Error {
+	cp := *e
+	cp.Err = err
+	return &cp
+}
+
+// WithMessage mengembalikan copy error dengan pesan yang lebih spesifik
+func (e *Error) WithMessage(message string) *Error {
+	cp := *e
+	cp.Message = message
+	return &cp
+}
+
+// WithDetails mengembalikan copy error dengan detail tambahan
+func (e *Error) WithDetails(details interface{}) *Error {
+	cp := *e
+	cp.Details = details
+	return &cp
+}
+
+// Error umum yang tidak terikat ke package tertentu
+var (
+	ErrInternal     = New(KindInternal, "INTERNAL_ERROR", "internal server error")
+	ErrInvalidBody  = New(KindBadRequest, "INVALID_BODY", "invalid request body")
+	ErrInvalidParam = New(KindBadRequest, "INVALID_PARAM", "invalid parameter")
+	ErrUnauthorized = New(KindUnauthorized, "UNAUTHORIZED", "unauthorized")
+	ErrForbidden    = New(KindForbidden, "FORBIDDEN", "forbidden")
+)
+
+// Internal membungkus error tak terduga (database, dll). Pesan ke client selalu
+// generik, penyebab aslinya di-log oleh ErrorHandler
+func Internal(err error) *Error {
+	return ErrInternal.Wrap(err)
+}
+
+// InvalidParam dipakai saat path/query param tidak bisa di-parse, contoh InvalidParam("event id")
+func InvalidParam(name string) *Error {
+	return ErrInvalidParam.WithMessage("invalid " + name)
+}
+
+// As adalah shortcut errors.As untuk *Error
+func As(err error) (*Error, bool) {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr, true
+	}
+	return nil, false
+}
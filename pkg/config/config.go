@@ -23,6 +23,7 @@ type Config struct {
 		Port       string // Port untuk aplikasi web server
 		NodeEnv    string // Environment mode (development/production)
 		CorsOrigin string // Allowed CORS origin (URL frontend)
+		AppBaseURL string // URL publik API, dipakai untuk link di email (contoh: link unsubscribe)
 		
 		// Mailjet email configuration
 		MailjetAPIKey     string // Mailjet API key
@@ -31,6 +32,16 @@ type Config struct {
 		MailjetHost       string // Mailjet SMTP host
 		MailSenderEmail   string // Email address untuk sender
 		MailSenderName    string // Nama sender yang tampil di email
+		UnsubscribeSecret string // Secret HMAC untuk link unsubscribe (default: JWTSecret)
+		EmailTemplateDir  string // Folder berisi template email pengganti bawaan (kosong = hanya template bawaan)
+
+		// Notifikasi
+		UpdateNotificationDebounce string // Jeda tanpa perubahan sebelum notifikasi update event dikirim (contoh: 5m, 0 = langsung)
+		UpdateNotificationMaxDelay string // Batas tunda notifikasi update sejak perubahan pertama (contoh: 30m)
+		NotificationRetentionDays  string // Umur (hari) notifikasi yang sudah dibaca sebelum dihapus, 0 = tidak dihapus
+
+		// Ticketing
+		OrderHoldDuration string // Lama kursi di-hold untuk order yang belum dibayar (contoh: 15m)
 }
 
 // LoadConfig membaca konfigurasi dari file .env dan environment variables
@@ -57,6 +68,7 @@ func LoadConfig() *Config {
 		Port:       getEnv("PORT", "5000"),
 		NodeEnv:    getEnv("NODE_ENV", "development"),
 		CorsOrigin: getEnv("CORS_ORIGIN", "http://localhost:3000"),
+		AppBaseURL: getEnv("APP_BASE_URL", "http://localhost:5000"),
 		
 		// Mailjet configuration
 		MailjetAPIKey:    getEnv("MAILJET_API_KEY", ""),
@@ -65,6 +77,16 @@ func LoadConfig() *Config {
 		MailjetHost:      getEnv("MAILJET_HOST", "in-v3.mailjet.com"),
 		MailSenderEmail:  getEnv("MAIL_SENDER_EMAIL", "noreply@goevent.com"),
 		MailSenderName:   getEnv("MAIL_SENDER_NAME", "GoEvent App"),
+		UnsubscribeSecret: getEnv("UNSUBSCRIBE_SECRET", ""),
+		EmailTemplateDir:  getEnv("EMAIL_TEMPLATE_DIR", ""),
+
+		// Notification configuration
+		UpdateNotificationDebounce: getEnv("UPDATE_NOTIFICATION_DEBOUNCE", "5m"),
+		UpdateNotificationMaxDelay: getEnv("UPDATE_NOTIFICATION_MAX_DELAY", "30m"),
+		NotificationRetentionDays:  getEnv("NOTIFICATION_RETENTION_DAYS", "90"),
+
+		// Ticketing configuration
+		OrderHoldDuration: getEnv("ORDER_HOLD_DURATION", "15m"),
 	}
 }
 
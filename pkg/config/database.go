@@ -24,17 +24,22 @@ var DB *gorm.DB
 func Connect(cfg *Config) error {
 	// Build MySQL DSN (Data Source Name) connection string
 	// Format MySQL DSN:
-	//   username:password@tcp(host:port)/dbname?charset=utf8mb4&parseTime=True&loc=Local
+	//   username:password@tcp(host:port)/dbname?charset=utf8mb4&parseTime=True&loc=UTC&time_zone='+00:00'
+	// Semua waktu disimpan dan dibaca sebagai UTC: loc=UTC untuk driver Go, time_zone
+	// untuk session MySQL (NOW(), CURRENT_TIMESTAMP). Timezone event/user hanya untuk tampilan
 	dsn := fmt.Sprintf(
-		"%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
+		"%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=UTC&time_zone=%%27%%2B00%%3A00%%27",
 		cfg.DBUser, cfg.DBPassword, cfg.DBHost, cfg.DBPort, cfg.DBName,
 	)
 
 	var err error
 	DB, err = gorm.Open(mysql.Open(dsn), &gorm.Config{
 		Logger: logger.Default.LogMode(logger.Silent),
+		// Error MySQL diterjemahkan ke error GORM (contoh duplicate key -> gorm.ErrDuplicatedKey)
+		// agar service bisa memetakan pelanggaran unique index ke 409
+		TranslateError: true,
 		NowFunc: func() time.Time {
-			return time.Now().UTC() // Gunakan UTC agar konsisten dengan server
+			return time.Now().UTC() // Sama dengan loc=UTC di DSN
 		},
 	})
 
@@ -0,0 +1,84 @@
+// Package export menulis data tabular (header + baris) ke CSV atau XLSX secara streaming,
+// baris langsung ditulis ke io.Writer tanpa menampung seluruh data di memory
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"strings"
+)
+
+type Format string
+
+const (
+	FormatCSV  Format = "csv"
+	FormatXLSX Format = "xlsx"
+)
+
+// ParseFormat mengubah query param menjadi Format, kosong = csv
+func ParseFormat(s string) (Format, bool) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", string(FormatCSV):
+		return FormatCSV, true
+	case string(FormatXLSX):
+		return FormatXLSX, true
+	default:
+		return "", false
+	}
+}
+
+func (f Format) ContentType() string {
+	if f == FormatXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+func (f Format) Extension() string {
+	return string(f)
+}
+
+// Writer menulis baris satu per satu. Close wajib dipanggil untuk menutup file
+// (XLSX tidak valid sebelum Close)
+type Writer interface {
+	WriteRow(cells []string) error
+	Close() error
+}
+
+// NewWriter membuat Writer sesuai format
+func NewWriter(f Format, w io.Writer) (Writer, error) {
+	if f == FormatXLSX {
+		return NewXLSXWriter(w, "Sheet1")
+	}
+	return NewCSVWriter(w), nil
+}
+
+type csvWriter struct {
+	w *csv.Writer
+}
+
+// NewCSVWriter membuat Writer CSV. Sel yang diawali karakter formula (= + - @)
+// diberi prefix ' agar tidak dieksekusi spreadsheet (CSV injection)
+func NewCSVWriter(w io.Writer) Writer {
+	return &csvWriter{w: csv.NewWriter(w)}
+}
+
+func (c *csvWriter) WriteRow(cells []string) error {
+	row := make([]string, len(cells))
+	for i, cell := range cells {
+		row[i] = escapeFormula(cell)
+	}
+	return c.w.Write(row)
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func escapeFormula(cell string) string {
+	if cell != "" && strings.ContainsRune("=+-@\t\r", rune(cell[0])) {
+		return "'" + cell
+	}
+	return cell
+}
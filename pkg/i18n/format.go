@@ -0,0 +1,18 @@
+package i18n
+
+import (
+	"fmt"
+	"time"
+)
+
+var indonesianMonths = [...]string{"Jan", "Feb", "Mar", "Apr", "Mei", "Jun", "Jul", "Agu", "Sep", "Okt", "Nov", "Des"}
+
+// FormatDateTime memformat tanggal dan jam sesuai kebiasaan locale, dalam timezone
+// milik t beserta singkatannya: id "15 Nov 2025 10:00 WIB", en "Nov 15, 2025 10:00 AM WIB".
+// Ubah dulu t ke timezone penerima dengan t.In(loc)
+func FormatDateTime(locale Locale, t time.Time) string {
+	if locale == English {
+		return t.Format("Jan 2, 2006 3:04 PM MST")
+	}
+	return fmt.Sprintf("%02d %s %d %02d:%02d %s", t.Day(), indonesianMonths[t.Month()-1], t.Year(), t.Hour(), t.Minute(), t.Format("MST"))
+}
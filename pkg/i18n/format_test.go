@@ -0,0 +1,47 @@
+package i18n
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormatDateTimeAcrossDST(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		utc    string
+		loc    *time.Location
+		locale Locale
+		want   string
+	}{
+		{"2025-03-09T06:59:00Z", newYork, English, "Mar 9, 2025 1:59 AM EST"},
+		{"2025-03-09T07:00:00Z", newYork, English, "Mar 9, 2025 3:00 AM EDT"},
+		{"2025-03-09T07:00:00Z", newYork, Indonesian, "09 Mar 2025 03:00 EDT"},
+		{"2025-11-02T05:30:00Z", newYork, English, "Nov 2, 2025 1:30 AM EDT"},
+		{"2025-11-02T06:30:00Z", newYork, English, "Nov 2, 2025 1:30 AM EST"},
+		{"2025-03-30T00:59:00Z", berlin, Indonesian, "30 Mar 2025 01:59 CET"},
+		{"2025-03-30T01:00:00Z", berlin, Indonesian, "30 Mar 2025 03:00 CEST"},
+		{"2025-10-26T00:30:00Z", berlin, Indonesian, "26 Okt 2025 02:30 CEST"},
+		{"2025-10-26T01:30:00Z", berlin, English, "Oct 26, 2025 2:30 AM CET"},
+		// Jam UTC yang sama seminggu sebelum dan sesudah DST tampil bergeser satu jam
+		{"2025-03-02T15:00:00Z", newYork, English, "Mar 2, 2025 10:00 AM EST"},
+		{"2025-03-16T15:00:00Z", newYork, English, "Mar 16, 2025 11:00 AM EDT"},
+		{"2025-03-16T15:00:00Z", time.UTC, Indonesian, "16 Mar 2025 15:00 UTC"},
+	}
+	for _, tt := range tests {
+		stored, err := time.Parse(time.RFC3339, tt.utc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := FormatDateTime(tt.locale, stored.In(tt.loc)); got != tt.want {
+			t.Errorf("FormatDateTime(%s, %s in %s) = %q, want %q", tt.locale, tt.utc, tt.loc, got, tt.want)
+		}
+	}
+}
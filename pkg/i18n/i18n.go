@@ -0,0 +1,100 @@
+// Package i18n berisi katalog pesan (notifikasi dan email) per locale serta format
+// tanggal sesuai locale penerima. Katalog di-embed dari locales/<locale>.json
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Locale adalah kode bahasa ISO 639-1 yang didukung
+type Locale string
+
+const (
+	Indonesian Locale = "id"
+	English    Locale = "en"
+
+	// Default dipakai untuk user lama, guest, dan key yang belum diterjemahkan
+	Default = Indonesian
+)
+
+// Supported adalah semua locale yang punya katalog
+var Supported = []Locale{Indonesian, English}
+
+//go:embed locales/*.json
+var localeFiles embed.FS
+
+// catalogs dimuat sekali saat start, katalog rusak adalah bug sehingga panic
+var catalogs = loadCatalogs()
+
+func loadCatalogs() map[Locale]map[string]string {
+	catalogs := make(map[Locale]map[string]string, len(Supported))
+	for _, locale := range Supported {
+		data, err := localeFiles.ReadFile("locales/" + string(locale) + ".json")
+		if err != nil {
+			panic(fmt.Sprintf("i18n: missing catalog for %s: %v", locale, err))
+		}
+		var messages map[string]string
+		if err := json.Unmarshal(data, &messages); err != nil {
+			panic(fmt.Sprintf("i18n: invalid catalog for %s: %v", locale, err))
+		}
+		catalogs[locale] = messages
+	}
+	return catalogs
+}
+
+// Parse menerima kode bahasa atau language tag ("en", "en-US", "id_ID")
+func Parse(s string) (Locale, bool) {
+	base := strings.ToLower(strings.TrimSpace(s))
+	if i := strings.IndexAny(base, "-_"); i >= 0 {
+		base = base[:i]
+	}
+	for _, locale := range Supported {
+		if base == string(locale) {
+			return locale, true
+		}
+	}
+	return "", false
+}
+
+// Resolve mengembalikan locale yang didukung, fallback ke Default jika kosong atau tidak dikenal
+func Resolve(s string) Locale {
+	if locale, ok := Parse(s); ok {
+		return locale
+	}
+	return Default
+}
+
+// FromAcceptLanguage memilih locale pertama yang didukung dari header Accept-Language.
+// Bobot q diabaikan, urutan dari client dianggap sudah sesuai prioritas
+func FromAcceptLanguage(header string) (Locale, bool) {
+	for _, part := range strings.Split(header, ",") {
+		tag, _, _ := strings.Cut(part, ";")
+		if locale, ok := Parse(tag); ok {
+			return locale, true
+		}
+	}
+	return "", false
+}
+
+// Message mengembalikan teks mentah untuk key, fallback ke Default lalu ke key itu sendiri
+func Message(locale Locale, key string) string {
+	if msg, ok := catalogs[locale][key]; ok {
+		return msg
+	}
+	if msg, ok := catalogs[Default][key]; ok {
+		return msg
+	}
+	return key
+}
+
+// T menerjemahkan key dan mengisi argumen dengan format fmt
+func T(locale Locale, key string, args ...interface{}) string {
+	msg := Message(locale, key)
+	if len(args) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, args...)
+}
@@ -0,0 +1,111 @@
+// Package ics menulis file kalender iCalendar (RFC 5545) untuk diimpor ke Google Calendar,
+// Outlook, Apple Calendar, dll
+package ics
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"time"
+)
+
+// ContentType untuk response .ics
+const ContentType = "text/calendar; charset=utf-8"
+
+// utcFormat adalah format DATE-TIME UTC di iCalendar
+const utcFormat = "20060102T150405Z"
+
+// maxLineOctets batas panjang satu baris sebelum dilipat (RFC 5545 3.1)
+const maxLineOctets = 75
+
+// Event adalah satu VEVENT
+type Event struct {
+	UID         string
+	Summary     string
+	Description string
+	Location    string
+	Start       time.Time
+	End         time.Time
+	Cancelled   bool
+}
+
+// Calendar adalah satu VCALENDAR. Waktu event ditulis dalam UTC (akhiran Z) sehingga
+// aplikasi kalender menampilkannya di timezone perangkat penerima. Timezone (IANA) hanya
+// petunjuk timezone tampilan lewat X-WR-TIMEZONE, boleh kosong
+type Calendar struct {
+	Name     string
+	Timezone string
+	Events   []Event
+}
+
+// Write menulis kalender ke w dengan baris CRLF
+func (c *Calendar) Write(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	line := func(name, value string) {
+		writeFolded(bw, name+":"+value)
+	}
+
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", "-//GoEvent//GoEvent App//EN")
+	line("CALSCALE", "GREGORIAN")
+	line("METHOD", "PUBLISH")
+	if c.Name != "" {
+		line("X-WR-CALNAME", escapeText(c.Name))
+	}
+	if c.Timezone != "" {
+		line("X-WR-TIMEZONE", c.Timezone)
+	}
+
+	stamp := time.Now().UTC().Format(utcFormat)
+	for _, e := range c.Events {
+		line("BEGIN", "VEVENT")
+		line("UID", e.UID)
+		line("DTSTAMP", stamp)
+		line("DTSTART", e.Start.UTC().Format(utcFormat))
+		line("DTEND", e.End.UTC().Format(utcFormat))
+		line("SUMMARY", escapeText(e.Summary))
+		if e.Description != "" {
+			line("DESCRIPTION", escapeText(e.Description))
+		}
+		if e.Location != "" {
+			line("LOCATION", escapeText(e.Location))
+		}
+		if e.Cancelled {
+			line("STATUS", "CANCELLED")
+		} else {
+			line("STATUS", "CONFIRMED")
+		}
+		line("END", "VEVENT")
+	}
+
+	line("END", "VCALENDAR")
+	return bw.Flush()
+}
+
+var textEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`)
+
+// escapeText meng-escape nilai TEXT (RFC 5545 3.3.11)
+func escapeText(s string) string {
+	return textEscaper.Replace(s)
+}
+
+// writeFolded menulis satu content line, dilipat setiap 75 octet tanpa memotong
+// karakter UTF-8. Baris lanjutan diawali satu spasi
+func writeFolded(w *bufio.Writer, s string) {
+	limit := maxLineOctets
+	for len(s) > limit {
+		cut := limit
+		// Mundur sampai batas awal karakter UTF-8 (byte yang bukan 10xxxxxx)
+		for cut > 0 && s[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		w.WriteString(s[:cut])
+		w.WriteString("\r\n ")
+		s = s[cut:]
+		// Spasi di awal baris lanjutan ikut dihitung
+		limit = maxLineOctets - 1
+	}
+	w.WriteString(s)
+	w.WriteString("\r\n")
+}
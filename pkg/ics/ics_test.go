@@ -0,0 +1,87 @@
+package ics
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// DTSTART/DTEND selalu ditulis dalam UTC, apa pun timezone time.Time-nya,
+// termasuk event yang melewati pergantian DST
+func TestWriteUTCAcrossDST(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		start     time.Time
+		end       time.Time
+		wantStart string
+		wantEnd   string
+	}{
+		{
+			name:      "new york before dst",
+			start:     time.Date(2025, 3, 8, 10, 0, 0, 0, newYork),
+			end:       time.Date(2025, 3, 8, 12, 0, 0, 0, newYork),
+			wantStart: "20250308T150000Z",
+			wantEnd:   "20250308T170000Z",
+		},
+		{
+			name:      "new york after dst",
+			start:     time.Date(2025, 3, 9, 10, 0, 0, 0, newYork),
+			end:       time.Date(2025, 3, 9, 12, 0, 0, 0, newYork),
+			wantStart: "20250309T140000Z",
+			wantEnd:   "20250309T160000Z",
+		},
+		{
+			name:      "new york overnight across dst",
+			start:     time.Date(2025, 3, 8, 23, 0, 0, 0, newYork),
+			end:       time.Date(2025, 3, 9, 5, 0, 0, 0, newYork),
+			wantStart: "20250309T040000Z",
+			wantEnd:   "20250309T090000Z",
+		},
+		{
+			name:      "berlin overnight across dst end",
+			start:     time.Date(2025, 10, 25, 22, 0, 0, 0, berlin),
+			end:       time.Date(2025, 10, 26, 6, 0, 0, 0, berlin),
+			wantStart: "20251025T200000Z",
+			wantEnd:   "20251026T050000Z",
+		},
+		{
+			name:      "already utc",
+			start:     time.Date(2025, 10, 26, 1, 0, 0, 0, time.UTC),
+			end:       time.Date(2025, 10, 26, 2, 0, 0, 0, time.UTC),
+			wantStart: "20251026T010000Z",
+			wantEnd:   "20251026T020000Z",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cal := &Calendar{
+				Name:     "Test",
+				Timezone: tt.start.Location().String(),
+				Events:   []Event{{UID: "event-1@goevent", Summary: "Meetup", Start: tt.start, End: tt.end}},
+			}
+			var b strings.Builder
+			if err := cal.Write(&b); err != nil {
+				t.Fatal(err)
+			}
+			out := b.String()
+			for _, want := range []string{
+				"DTSTART:" + tt.wantStart + "\r\n",
+				"DTEND:" + tt.wantEnd + "\r\n",
+				"X-WR-TIMEZONE:" + tt.start.Location().String() + "\r\n",
+			} {
+				if !strings.Contains(out, want) {
+					t.Errorf("output missing %q:\n%s", want, out)
+				}
+			}
+		})
+	}
+}
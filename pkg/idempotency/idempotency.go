@@ -0,0 +1,115 @@
+// Package idempotency menyimpan response request ber-Idempotency-Key agar retry dari
+// client bisa di-replay. Repository dibuat sekali di main lalu di-inject ke middleware
+// Idempotency dan job purge di scheduler
+package idempotency
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// TTL adalah lama response disimpan, setelahnya key boleh dipakai ulang
+	TTL = 24 * time.Hour
+	// LockTimeout: key yang masih "diproses" lebih lama dari ini dianggap
+	// ditinggalkan (server restart di tengah request) dan boleh diambil alih
+	LockTimeout = time.Minute
+)
+
+// Key menyimpan response dari request ber-Idempotency-Key, per user.
+// StatusCode 0 berarti request pertama masih diproses
+type Key struct {
+	ID          uint   `gorm:"primaryKey"`
+	UserID      uint   `gorm:"uniqueIndex:idx_idempotency_user_key,priority:1"`
+	Key         string `gorm:"column:idempotency_key;size:128;uniqueIndex:idx_idempotency_user_key,priority:2"`
+	RequestHash string `gorm:"size:64"`
+	StatusCode  int
+	ContentType string    `gorm:"size:100"`
+	Body        []byte    `gorm:"type:mediumblob"`
+	CreatedAt   time.Time `gorm:"index"`
+}
+
+// TableName mempertahankan nama tabel lama (idempotency_keys)
+func (Key) TableName() string {
+	return "idempotency_keys"
+}
+
+// expired mengecek apakah key boleh diambil alih oleh request baru
+func (k *Key) expired(now time.Time) bool {
+	if k.StatusCode == 0 {
+		return now.Sub(k.CreatedAt) > LockTimeout
+	}
+	return now.Sub(k.CreatedAt) > TTL
+}
+
+type Repository interface {
+	Claim(record *Key) (*Key, error)
+	Release(id uint) error
+	SaveResponse(id uint, statusCode int, contentType string, body []byte) error
+	Purge(now time.Time) error
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+// Claim implements Repository.
+// Menyimpan key berstatus diproses. Jika key sudah dipakai, record lama dikembalikan,
+// kecuali sudah kadaluarsa (dihapus lalu diklaim ulang).
+// Unique index (user_id, key) memastikan hanya satu request paralel yang menang
+func (r *repository) Claim(record *Key) (*Key, error) {
+	for attempt := 0; ; attempt++ {
+		record.ID = 0
+		record.CreatedAt = time.Now()
+		err := r.db.Create(record).Error
+		if err == nil {
+			return nil, nil
+		}
+		if !errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, err
+		}
+
+		var stored Key
+		err = r.db.Where(&Key{UserID: record.UserID, Key: record.Key}).Take(&stored).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) && attempt == 0 {
+			continue // baru saja dilepas request lain
+		}
+		if err != nil {
+			return nil, err
+		}
+		if attempt > 0 || !stored.expired(time.Now()) {
+			return &stored, nil
+		}
+		if err := r.db.Where("id = ? AND created_at = ?", stored.ID, stored.CreatedAt).
+			Delete(&Key{}).Error; err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Release implements Repository.
+// Melepas key agar request berikutnya dengan key yang sama dijalankan ulang
+func (r *repository) Release(id uint) error {
+	return r.db.Delete(&Key{}, id).Error
+}
+
+// SaveResponse implements Repository.
+func (r *repository) SaveResponse(id uint, statusCode int, contentType string, body []byte) error {
+	return r.db.Model(&Key{ID: id}).Updates(map[string]interface{}{
+		"status_code":  statusCode,
+		"content_type": contentType,
+		"body":         body,
+	}).Error
+}
+
+// Purge implements Repository.
+// Menghapus key yang sudah melewati TTL (dipanggil scheduler)
+func (r *repository) Purge(now time.Time) error {
+	return r.db.Where("created_at < ?", now.Add(-TTL)).Delete(&Key{}).Error
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
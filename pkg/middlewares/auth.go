@@ -3,6 +3,7 @@ package middlewares
 import (
 	"strings"
 
+	"go-event/pkg/apperror"
 	"go-event/pkg/config"
 
 	"github.com/gofiber/fiber/v2"
@@ -16,6 +17,15 @@ type Claims struct {
 	jwt.RegisteredClaims
 }
 
+// Error autentikasi / otorisasi
+var (
+	ErrTokenMissing     = apperror.New(apperror.KindUnauthorized, "TOKEN_MISSING", "Akses ditolak. Token tidak ditemukan.")
+	ErrTokenInvalid     = apperror.New(apperror.KindUnauthorized, "TOKEN_INVALID", "Token tidak valid atau kadaluarsa.")
+	ErrUserNotFound     = apperror.New(apperror.KindUnauthorized, "TOKEN_USER_NOT_FOUND", "User tidak ditemukan.")
+	ErrNotAuthenticated = apperror.New(apperror.KindUnauthorized, "NOT_AUTHENTICATED", "User belum terautentikasi.")
+	ErrRoleForbidden    = apperror.New(apperror.KindForbidden, "ROLE_FORBIDDEN", "Akses ditolak. Anda tidak memiliki izin yang sesuai.")
+)
+
 // UserLocals is a simplified user struct for context
 type UserLocals struct {
 	ID    uint
@@ -38,9 +48,7 @@ func Authenticate(cfg *config.Config) fiber.Handler {
 
 		// Jika token tidak ditemukan
 		if token == "" {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"message": "Akses ditolak. Token tidak ditemukan.",
-			})
+			return ErrTokenMissing
 		}
 
 		// Parse dan verifikasi token JWT
@@ -50,9 +58,7 @@ func Authenticate(cfg *config.Config) fiber.Handler {
 		})
 
 		if err != nil || !tkn.Valid {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"message": "Token tidak valid atau kadaluarsa.",
-			})
+			return ErrTokenInvalid
 		}
 		// Ambil user dari database
 		// We need to query the user to verify they exist
@@ -63,9 +69,7 @@ func Authenticate(cfg *config.Config) fiber.Handler {
 		// Query to check if user exists
 		var count int64
 		if err := db.Table("users").Where("id = ?", claims.ID).Count(&count).Error; err != nil || count == 0 {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"message": "User tidak ditemukan.",
-			})
+			return ErrUserNotFound
 		}
 
 		// Store user ID and role in context
@@ -84,9 +88,7 @@ func Authorize(roles ...string) fiber.Handler {
 		// Ambil user role dari context (harus lewat Authenticate dulu)
 		userRole, ok := c.Locals("userRole").(string)
 		if !ok {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"message": "User belum terautentikasi.",
-			})
+			return ErrNotAuthenticated
 		}
 
 		// Jika roles kosong, berarti semua user boleh
@@ -102,8 +104,6 @@ func Authorize(roles ...string) fiber.Handler {
 		}
 
 		// Jika tidak memiliki izin
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"message": "Akses ditolak. Anda tidak memiliki izin yang sesuai.",
-		})
+		return ErrRoleForbidden
 	}
 }
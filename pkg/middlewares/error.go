@@ -2,31 +2,116 @@
 package middlewares
 
 import (
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+
+	"go-event/pkg/apperror"
+	"go-event/pkg/validation"
+
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
 )
 
+// ErrorResponse adalah envelope JSON untuk semua response error
+type ErrorResponse struct {
+	Message   string      `json:"message"`
+	Code      string      `json:"code"`
+	Details   interface{} `json:"details,omitempty"`
+	RequestID string      `json:"request_id,omitempty"`
+}
+
+// statusByKind adalah satu-satunya tempat mapping error domain ke HTTP status
+var statusByKind = map[apperror.Kind]int{
+	apperror.KindInternal:        fiber.StatusInternalServerError,
+	apperror.KindBadRequest:      fiber.StatusBadRequest,
+	apperror.KindUnauthorized:    fiber.StatusUnauthorized,
+	apperror.KindForbidden:       fiber.StatusForbidden,
+	apperror.KindNotFound:        fiber.StatusNotFound,
+	apperror.KindConflict:        fiber.StatusConflict,
+	apperror.KindValidation:      fiber.StatusUnprocessableEntity,
+	apperror.KindPaymentRequired: fiber.StatusPaymentRequired,
+}
+
 // ErrorHandler adalah custom error handler untuk Fiber
 // Function ini akan dipanggil saat terjadi error di aplikasi
 // Function ini di-set di Fiber config saat inisialisasi app
 // Parameters:
 //   - c: Fiber context
 //   - err: Error yang terjadi
+//
 // Returns: error (selalu nil karena sudah di-handle)
 func ErrorHandler(c *fiber.Ctx, err error) error {
-	// Default status code 500 Internal Server Error
-	code := fiber.StatusInternalServerError
+	status, body := resolveError(err)
+	body.RequestID = RequestID(c)
 
-	// Jika error adalah Fiber error, gunakan status code dari error tersebut
-	if e, ok := err.(*fiber.Error); ok {
-		code = e.Code
+	if status >= fiber.StatusInternalServerError {
+		// Penyebab asli hanya di-log, tidak dikirim ke client
+		cause := err
+		if appErr, ok := apperror.As(err); ok && appErr.Err != nil {
+			cause = appErr.Err
+		}
+		log.Printf("[ERROR] request_id=%s %s %s: %v", body.RequestID, c.Method(), c.Path(), cause)
 	}
 
-	// Return JSON response dengan error message
-	// Stack trace tidak ditampilkan untuk security (production)
-	return c.Status(code).JSON(fiber.Map{
-		"message": err.Error(),
-		"stack":   nil, // Don't expose stack trace in production
-	})
+	return c.Status(status).JSON(body)
+}
+
+// resolveError mengubah error apa pun menjadi HTTP status dan envelope
+func resolveError(err error) (int, ErrorResponse) {
+	var verrs validation.Errors
+	if errors.As(err, &verrs) {
+		return fiber.StatusUnprocessableEntity, ErrorResponse{
+			Message: "validation failed",
+			Code:    "VALIDATION_FAILED",
+			Details: verrs,
+		}
+	}
+
+	if appErr, ok := apperror.As(err); ok {
+		status, ok := statusByKind[appErr.Kind]
+		if !ok {
+			status = fiber.StatusInternalServerError
+		}
+		return status, ErrorResponse{
+			Message: appErr.Message,
+			Code:    appErr.Code,
+			Details: appErr.Details,
+		}
+	}
+
+	// Error dari Fiber sendiri (body terlalu besar, method not allowed, dll)
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		return fiberErr.Code, ErrorResponse{
+			Message: fiberErr.Message,
+			Code:    codeFromStatus(fiberErr.Code),
+		}
+	}
+
+	// Error tak terduga tidak boleh bocor ke client
+	return fiber.StatusInternalServerError, ErrorResponse{
+		Message: apperror.ErrInternal.Message,
+		Code:    apperror.ErrInternal.Code,
+	}
+}
+
+// codeFromStatus contoh: 413 -> REQUEST_ENTITY_TOO_LARGE
+func codeFromStatus(status int) string {
+	msg := utils.StatusMessage(status)
+	if msg == "" {
+		return "HTTP_" + strconv.Itoa(status)
+	}
+	return strings.ToUpper(strings.ReplaceAll(msg, " ", "_"))
+}
+
+// RequestID mengambil request ID yang di-set oleh middleware requestid
+func RequestID(c *fiber.Ctx) string {
+	if id, ok := c.Locals("requestid").(string); ok {
+		return id
+	}
+	return c.GetRespHeader(fiber.HeaderXRequestID)
 }
 
 // NotFound adalah handler untuk 404 Not Found
@@ -34,9 +119,8 @@ func ErrorHandler(c *fiber.Ctx, err error) error {
 // Function ini di-register sebagai fallback handler di main.go
 // Parameters:
 //   - c: Fiber context
+//
 // Returns: error
 func NotFound(c *fiber.Ctx) error {
-	return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-		"message": "NOT FOUND - " + c.OriginalURL(),
-	})
+	return apperror.New(apperror.KindNotFound, "ROUTE_NOT_FOUND", "NOT FOUND - "+c.OriginalURL())
 }
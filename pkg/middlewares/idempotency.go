@@ -0,0 +1,99 @@
+package middlewares
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"strings"
+
+	"go-event/pkg/apperror"
+	"go-event/pkg/idempotency"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	// IdempotencyHeader dikirim client, nilainya bebas (disarankan UUID) dan unik per aksi
+	IdempotencyHeader = "Idempotency-Key"
+	// IdempotencyReplayedHeader ditambahkan pada response yang diambil dari penyimpanan
+	IdempotencyReplayedHeader = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLength = 128
+)
+
+// Error idempotency key
+var (
+	ErrIdempotencyKeyInvalid = apperror.New(apperror.KindBadRequest, "IDEMPOTENCY_KEY_INVALID", "Idempotency-Key must be at most 128 characters")
+	ErrIdempotencyKeyReused  = apperror.New(apperror.KindValidation, "IDEMPOTENCY_KEY_REUSED", "Idempotency-Key was already used for a different request")
+	ErrIdempotencyInProgress = apperror.New(apperror.KindConflict, "IDEMPOTENCY_REQUEST_IN_PROGRESS", "a request with this Idempotency-Key is still being processed")
+)
+
+// ✅ Idempotency Middleware
+// Request dengan header Idempotency-Key hanya dijalankan sekali per user. Retry dengan
+// key yang sama mendapat response pertama apa adanya. Response error (termasuk 5xx)
+// tidak disimpan agar client bisa retry. Harus dipasang setelah Authenticate
+func Idempotency(store idempotency.Repository) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		key := strings.TrimSpace(c.Get(IdempotencyHeader))
+		if key == "" {
+			return c.Next()
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			return ErrIdempotencyKeyInvalid
+		}
+		userID, ok := c.Locals("userID").(uint)
+		if !ok {
+			return ErrNotAuthenticated
+		}
+
+		record := &idempotency.Key{UserID: userID, Key: key, RequestHash: requestHash(c)}
+		stored, err := store.Claim(record)
+		if err != nil {
+			return apperror.Internal(err)
+		}
+		if stored != nil {
+			return replayIdempotent(c, stored, record.RequestHash)
+		}
+
+		err = c.Next()
+		status := c.Response().StatusCode()
+		if err != nil || status >= fiber.StatusInternalServerError {
+			if delErr := store.Release(record.ID); delErr != nil {
+				log.Printf("idempotency: failed to release key %d: %v", record.ID, delErr)
+			}
+			return err
+		}
+
+		contentType := string(c.Response().Header.ContentType())
+		body := append([]byte(nil), c.Response().Body()...)
+		if err := store.SaveResponse(record.ID, status, contentType, body); err != nil {
+			// Response tetap dikirim, hanya retry berikutnya yang tidak bisa di-replay
+			log.Printf("idempotency: failed to store response for key %d: %v", record.ID, err)
+		}
+		return nil
+	}
+}
+
+// replayIdempotent mengirim ulang response yang tersimpan
+func replayIdempotent(c *fiber.Ctx, stored *idempotency.Key, hash string) error {
+	if stored.RequestHash != hash {
+		return ErrIdempotencyKeyReused
+	}
+	if stored.StatusCode == 0 {
+		return ErrIdempotencyInProgress
+	}
+	c.Set(IdempotencyReplayedHeader, "true")
+	if stored.ContentType != "" {
+		c.Set(fiber.HeaderContentType, stored.ContentType)
+	}
+	return c.Status(stored.StatusCode).Send(stored.Body)
+}
+
+// requestHash sidik jari request (method, URL, body) untuk mendeteksi key yang
+// dipakai ulang dengan request berbeda
+func requestHash(c *fiber.Ctx) string {
+	h := sha256.New()
+	h.Write([]byte(c.Method() + " " + c.OriginalURL() + "\n"))
+	h.Write(c.Body())
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,51 @@
+// Package pagination berisi parameter page/per_page dari query string dan metadata
+// halaman untuk response list
+package pagination
+
+const (
+	DefaultPerPage = 20
+	MaxPerPage     = 100
+)
+
+// Params dibaca dari query `?page=2&per_page=50`. Nilai kosong memakai default
+type Params struct {
+	Page    int `json:"page" query:"page" validate:"omitempty,min=1"`
+	PerPage int `json:"per_page" query:"per_page" validate:"omitempty,min=1,max=100"`
+}
+
+// Normalize mengisi nilai default, dipanggil setelah validasi
+func (p *Params) Normalize() {
+	if p.Page < 1 {
+		p.Page = 1
+	}
+	if p.PerPage < 1 {
+		p.PerPage = DefaultPerPage
+	}
+	if p.PerPage > MaxPerPage {
+		p.PerPage = MaxPerPage
+	}
+}
+
+func (p Params) Offset() int {
+	return (p.Page - 1) * p.PerPage
+}
+
+func (p Params) Limit() int {
+	return p.PerPage
+}
+
+// Meta dikirim bersama data list
+type Meta struct {
+	Page       int   `json:"page"`
+	PerPage    int   `json:"per_page"`
+	Total      int64 `json:"total"`
+	TotalPages int   `json:"total_pages"`
+}
+
+func NewMeta(p Params, total int64) Meta {
+	pages := 0
+	if p.PerPage > 0 {
+		pages = int((total + int64(p.PerPage) - 1) / int64(p.PerPage))
+	}
+	return Meta{Page: p.Page, PerPage: p.PerPage, Total: total, TotalPages: pages}
+}
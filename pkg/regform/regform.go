@@ -0,0 +1,285 @@
+// Package regform mendefinisikan form pendaftaran yang disusun organizer per event
+// (pertanyaan + validasi jawaban). Dipakai oleh event (menyimpan form) serta
+// participant dan ticket (memvalidasi jawaban) tanpa saling import.
+package regform
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"go-event/pkg/validation"
+)
+
+type QuestionType string
+
+const (
+	TypeText         QuestionType = "text"
+	TypeNumber       QuestionType = "number"
+	TypeCheckbox     QuestionType = "checkbox"
+	TypeSingleChoice QuestionType = "single_choice"
+	TypeMultiChoice  QuestionType = "multi_choice"
+)
+
+const (
+	MaxQuestions    = 30
+	MaxAnswerLength = 1000
+)
+
+var keyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,49}$`)
+
+// Question adalah satu pertanyaan di form. Key dipakai sebagai nama field jawaban
+// sehingga label bisa diubah tanpa memutus jawaban yang sudah tersimpan
+type Question struct {
+	Key      string       `json:"key" validate:"required,max=50"`
+	Label    string       `json:"label" validate:"required,notblank,max=200"`
+	Type     QuestionType `json:"type" validate:"required,oneof=text number checkbox single_choice multi_choice"`
+	Options  []string     `json:"options,omitempty" validate:"max=50,dive,notblank,max=100"`
+	Required bool         `json:"required"`
+}
+
+// questionList membungkus slice agar path error berbentuk "questions[0].label"
+type questionList struct {
+	Questions []Question `json:"questions" validate:"dive"`
+}
+
+// Answers adalah jawaban participant, key -> nilai (string, float64, bool, atau []string)
+type Answers map[string]interface{}
+
+// ValidateQuestions memeriksa aturan yang tidak bisa diekspresikan lewat tag validate:
+// format & keunikan key, dan opsi untuk pertanyaan pilihan
+func ValidateQuestions(questions []Question) error {
+	if len(questions) > MaxQuestions {
+		return validation.NewError("questions", "max", strconv.Itoa(MaxQuestions), fmt.Sprintf("must contain at most %d items", MaxQuestions))
+	}
+	if err := validation.Struct(&questionList{Questions: questions}); err != nil {
+		return err
+	}
+
+	var errs validation.Errors
+	seen := make(map[string]bool, len(questions))
+	for i, q := range questions {
+		field := fmt.Sprintf("questions[%d]", i)
+		if !keyPattern.MatchString(q.Key) {
+			errs = append(errs, validation.FieldError{Field: field + ".key", Rule: "key", Message: "must start with a letter and contain only lowercase letters, numbers and underscores"})
+		} else if seen[q.Key] {
+			errs = append(errs, validation.FieldError{Field: field + ".key", Rule: "unique", Message: "must be unique"})
+		}
+		seen[q.Key] = true
+
+		isChoice := q.Type == TypeSingleChoice || q.Type == TypeMultiChoice
+		if isChoice && len(q.Options) < 2 {
+			errs = append(errs, validation.FieldError{Field: field + ".options", Rule: "min", Param: "2", Message: "must contain at least 2 items for choice questions"})
+		}
+		if !isChoice && len(q.Options) > 0 {
+			errs = append(errs, validation.FieldError{Field: field + ".options", Rule: "excluded", Message: "is only allowed for choice questions"})
+		}
+		if hasDuplicate(q.Options) {
+			errs = append(errs, validation.FieldError{Field: field + ".options", Rule: "unique", Message: "must not contain duplicates"})
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// ValidateAnswers memvalidasi jawaban terhadap form dan mengembalikan jawaban yang
+// sudah dinormalisasi (hanya key yang dikenal, tipe sesuai pertanyaan)
+func ValidateAnswers(questions []Question, answers Answers) (Answers, error) {
+	var errs validation.Errors
+	normalized := make(Answers, len(questions))
+
+	known := make(map[string]bool, len(questions))
+	for _, q := range questions {
+		known[q.Key] = true
+		field := "answers." + q.Key
+
+		raw, present := answers[q.Key]
+		if !present || raw == nil || isBlank(raw) {
+			if q.Required {
+				errs = append(errs, validation.FieldError{Field: field, Rule: "required", Message: "is required"})
+			}
+			continue
+		}
+
+		value, msg := normalize(q, raw)
+		if msg != "" {
+			errs = append(errs, validation.FieldError{Field: field, Rule: string(q.Type), Message: msg})
+			continue
+		}
+		// Checkbox wajib berarti harus dicentang (contoh: persetujuan syarat & ketentuan)
+		if q.Type == TypeCheckbox && q.Required && value == false {
+			errs = append(errs, validation.FieldError{Field: field, Rule: "required", Message: "must be checked"})
+			continue
+		}
+		normalized[q.Key] = value
+	}
+
+	for key := range answers {
+		if !known[key] {
+			errs = append(errs, validation.FieldError{Field: "answers." + key, Rule: "unknown", Message: "is not a question of this event"})
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	if len(normalized) == 0 {
+		return nil, nil
+	}
+	return normalized, nil
+}
+
+// normalize mengubah nilai JSON ke tipe pertanyaan, msg berisi pesan error jika tidak valid
+func normalize(q Question, raw interface{}) (interface{}, string) {
+	switch q.Type {
+	case TypeText:
+		s, ok := raw.(string)
+		if !ok {
+			return nil, "must be a string"
+		}
+		s = strings.TrimSpace(s)
+		if len(s) > MaxAnswerLength {
+			return nil, fmt.Sprintf("must be at most %d characters", MaxAnswerLength)
+		}
+		return s, ""
+	case TypeNumber:
+		switch n := raw.(type) {
+		case float64:
+			if math.IsNaN(n) || math.IsInf(n, 0) {
+				return nil, "must be a number"
+			}
+			return n, ""
+		case string:
+			f, err := strconv.ParseFloat(strings.TrimSpace(n), 64)
+			if err != nil {
+				return nil, "must be a number"
+			}
+			return f, ""
+		}
+		return nil, "must be a number"
+	case TypeCheckbox:
+		b, ok := raw.(bool)
+		if !ok {
+			return nil, "must be true or false"
+		}
+		return b, ""
+	case TypeSingleChoice:
+		s, ok := raw.(string)
+		if !ok || !contains(q.Options, s) {
+			return nil, "must be one of: " + strings.Join(q.Options, ", ")
+		}
+		return s, ""
+	case TypeMultiChoice:
+		items, ok := raw.([]interface{})
+		if !ok {
+			return nil, "must be a list of options"
+		}
+		selected := make([]string, 0, len(items))
+		for _, item := range items {
+			s, ok := item.(string)
+			if !ok || !contains(q.Options, s) {
+				return nil, "must only contain: " + strings.Join(q.Options, ", ")
+			}
+			if !contains(selected, s) {
+				selected = append(selected, s)
+			}
+		}
+		return selected, ""
+	}
+	return nil, "has an unsupported question type"
+}
+
+// FormatAnswer mengubah jawaban menjadi teks, dipakai untuk tampilan / export
+func FormatAnswer(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool:
+		if v {
+			return "yes"
+		}
+		return "no"
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case []string:
+		return strings.Join(v, "; ")
+	case []interface{}:
+		parts := make([]string, 0, len(v))
+		for _, item := range v {
+			parts = append(parts, FormatAnswer(item))
+		}
+		return strings.Join(parts, "; ")
+	}
+	return fmt.Sprint(value)
+}
+
+// ParseTextAnswers kebalikan FormatAnswer: mengubah jawaban teks (misalnya dari CSV)
+// ke bentuk JSON yang diterima ValidateAnswers. Kolom yang tidak ada di form dan sel
+// kosong diabaikan
+func ParseTextAnswers(questions []Question, values map[string]string) Answers {
+	answers := Answers{}
+	for _, q := range questions {
+		text := strings.TrimSpace(values[q.Key])
+		if text == "" {
+			continue
+		}
+		switch q.Type {
+		case TypeCheckbox:
+			switch strings.ToLower(text) {
+			case "yes", "true", "1", "x", "y":
+				answers[q.Key] = true
+			case "no", "false", "0", "n":
+				answers[q.Key] = false
+			default:
+				answers[q.Key] = text // ditolak ValidateAnswers
+			}
+		case TypeMultiChoice:
+			items := []interface{}{}
+			for _, item := range strings.Split(text, ";") {
+				if item = strings.TrimSpace(item); item != "" {
+					items = append(items, item)
+				}
+			}
+			answers[q.Key] = items
+		default:
+			answers[q.Key] = text
+		}
+	}
+	return answers
+}
+
+func isBlank(v interface{}) bool {
+	switch t := v.(type) {
+	case string:
+		return strings.TrimSpace(t) == ""
+	case []interface{}:
+		return len(t) == 0
+	}
+	return false
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func hasDuplicate(list []string) bool {
+	seen := make(map[string]bool, len(list))
+	for _, item := range list {
+		if seen[item] {
+			return true
+		}
+		seen[item] = true
+	}
+	return false
+}
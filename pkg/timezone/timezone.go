@@ -0,0 +1,52 @@
+// Package timezone membaca timezone IANA milik event dan user. Waktu selalu disimpan
+// dalam UTC, timezone hanya dipakai saat waktu ditampilkan ke penerima
+package timezone
+
+import (
+	"sync"
+	"time"
+
+	// Database timezone ikut di-embed agar tetap jalan di image tanpa /usr/share/zoneinfo
+	_ "time/tzdata"
+)
+
+// Default dipakai untuk event yang tidak mengisi timezone
+const Default = "UTC"
+
+var (
+	mu    sync.RWMutex
+	cache = map[string]*time.Location{}
+)
+
+// Load membaca timezone IANA, fallback ke UTC jika kosong atau tidak dikenal.
+// Hasilnya di-cache karena time.LoadLocation membaca tzdata setiap dipanggil
+func Load(name string) *time.Location {
+	if name == "" || name == "UTC" {
+		return time.UTC
+	}
+
+	mu.RLock()
+	loc, ok := cache[name]
+	mu.RUnlock()
+	if ok {
+		return loc
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		loc = time.UTC
+	}
+	mu.Lock()
+	cache[name] = loc
+	mu.Unlock()
+	return loc
+}
+
+// Resolve memilih timezone untuk menampilkan waktu event ke penerima: timezone
+// pilihan user jika diisi, selain itu timezone event
+func Resolve(preferred, eventTimezone string) *time.Location {
+	if preferred != "" {
+		return Load(preferred)
+	}
+	return Load(eventTimezone)
+}
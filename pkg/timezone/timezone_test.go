@@ -0,0 +1,82 @@
+package timezone
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"", "UTC"},
+		{"UTC", "UTC"},
+		{"America/New_York", "America/New_York"},
+		{"Europe/Berlin", "Europe/Berlin"},
+		{"Asia/Jakarta", "Asia/Jakarta"},
+		{"Mars/Olympus_Mons", "UTC"},
+	}
+	for _, tt := range tests {
+		if got := Load(tt.name).String(); got != tt.want {
+			t.Errorf("Load(%q) = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+	if Load("Europe/Berlin") != Load("Europe/Berlin") {
+		t.Error("Load should return the cached location")
+	}
+}
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		preferred, eventTimezone string
+		want                     string
+	}{
+		{"Europe/Berlin", "America/New_York", "Europe/Berlin"},
+		{"", "America/New_York", "America/New_York"},
+		{"", "", "UTC"},
+		{"Invalid/Zone", "America/New_York", "UTC"},
+	}
+	for _, tt := range tests {
+		if got := Resolve(tt.preferred, tt.eventTimezone).String(); got != tt.want {
+			t.Errorf("Resolve(%q, %q) = %s, want %s", tt.preferred, tt.eventTimezone, got, tt.want)
+		}
+	}
+}
+
+// Waktu yang sama di UTC ditampilkan dengan offset yang benar di kedua sisi
+// pergantian DST, dan kembali ke instant UTC yang sama
+func TestLoadAcrossDST(t *testing.T) {
+	tests := []struct {
+		zone   string
+		utc    string
+		wall   string
+		abbrev string
+		offset int
+	}{
+		// America/New_York maju 9 Mar 2025 02:00 EST, mundur 2 Nov 2025 02:00 EDT
+		{"America/New_York", "2025-03-09T06:59:00Z", "2025-03-09 01:59", "EST", -5 * 3600},
+		{"America/New_York", "2025-03-09T07:00:00Z", "2025-03-09 03:00", "EDT", -4 * 3600},
+		{"America/New_York", "2025-11-02T05:30:00Z", "2025-11-02 01:30", "EDT", -4 * 3600},
+		{"America/New_York", "2025-11-02T06:30:00Z", "2025-11-02 01:30", "EST", -5 * 3600},
+		// Europe/Berlin maju 30 Mar 2025 02:00 CET, mundur 26 Okt 2025 03:00 CEST
+		{"Europe/Berlin", "2025-03-30T00:59:00Z", "2025-03-30 01:59", "CET", 3600},
+		{"Europe/Berlin", "2025-03-30T01:00:00Z", "2025-03-30 03:00", "CEST", 2 * 3600},
+		{"Europe/Berlin", "2025-10-26T00:30:00Z", "2025-10-26 02:30", "CEST", 2 * 3600},
+		{"Europe/Berlin", "2025-10-26T01:30:00Z", "2025-10-26 02:30", "CET", 3600},
+	}
+	for _, tt := range tests {
+		stored, err := time.Parse(time.RFC3339, tt.utc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		local := stored.In(Load(tt.zone))
+		abbrev, offset := local.Zone()
+		if got := local.Format("2006-01-02 15:04"); got != tt.wall || abbrev != tt.abbrev || offset != tt.offset {
+			t.Errorf("%s in %s = %s %s (%d), want %s %s (%d)", tt.utc, tt.zone, got, abbrev, offset, tt.wall, tt.abbrev, tt.offset)
+		}
+		if !local.UTC().Equal(stored) || local.UTC().Format(time.RFC3339) != tt.utc {
+			t.Errorf("%s in %s does not round-trip to the same UTC instant", tt.utc, tt.zone)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+// Package validation menjalankan validasi struct request berdasarkan tag `validate:`
+// dan mengubah hasilnya menjadi daftar field error yang konsisten untuk response 422
+// (dipetakan oleh middlewares.ErrorHandler)
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// FieldError menjelaskan satu field yang gagal validasi
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+// Errors adalah kumpulan FieldError, mengimplementasikan error
+// sehingga bisa dikembalikan juga dari layer service
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, fe := range e {
+		msgs = append(msgs, fe.Field+" "+fe.Message)
+	}
+	return "validation failed: " + strings.Join(msgs, "; ")
+}
+
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New()
+
+	// Gunakan nama dari tag json agar field di response sama dengan payload
+	v.RegisterTagNameFunc(func(fld reflect.StructField) string {
+		name := strings.SplitN(fld.Tag.Get("json"), ",", 2)[0]
+		if name == "-" || name == "" {
+			return fld.Name
+		}
+		return name
+	})
+
+	// notblank: string tidak boleh kosong atau hanya berisi spasi
+	v.RegisterValidation("notblank", func(fl validator.FieldLevel) bool {
+		return strings.TrimSpace(fl.Field().String()) != ""
+	})
+
+	// future: waktu harus setelah sekarang
+	v.RegisterValidation("future", func(fl validator.FieldLevel) bool {
+		t, ok := fl.Field().Interface().(time.Time)
+		return ok && t.After(time.Now())
+	})
+
+	return v
+}
+
+// Struct memvalidasi s dan mengembalikan Errors jika ada field yang tidak valid
+func Struct(s interface{}) error {
+	err := validate.Struct(s)
+	if err == nil {
+		return nil
+	}
+
+	var verrs validator.ValidationErrors
+	if !errors.As(err, &verrs) {
+		return err
+	}
+
+	result := make(Errors, 0, len(verrs))
+	for _, fe := range verrs {
+		param := fe.Param()
+		// Rule yang merujuk field lain (gtfield, required_without, ...) memakai nama field json
+		if strings.HasSuffix(fe.Tag(), "field") || strings.HasPrefix(fe.Tag(), "required_with") {
+			param = toSnakeCase(param)
+		}
+		result = append(result, FieldError{
+			Field:   fieldPath(fe),
+			Rule:    fe.Tag(),
+			Param:   param,
+			Message: message(fe),
+		})
+	}
+	return result
+}
+
+// NewError membuat Errors untuk satu field, dipakai oleh service untuk
+// validasi yang bergantung pada data di database
+func NewError(field, rule, param, message string) Errors {
+	return Errors{{Field: field, Rule: rule, Param: param, Message: message}}
+}
+
+// fieldPath mengembalikan path field tanpa nama struct root, contoh "answers[0].value"
+func fieldPath(fe validator.FieldError) string {
+	ns := fe.Namespace()
+	if i := strings.Index(ns, "."); i >= 0 {
+		return ns[i+1:]
+	}
+	return fe.Field()
+}
+
+func message(fe validator.FieldError) string {
+	isString := fe.Kind() == reflect.String
+	switch fe.Tag() {
+	case "required", "notblank":
+		return "is required"
+	case "required_without":
+		return "is required when " + toSnakeCase(fe.Param()) + " is not set"
+	case "required_with":
+		return "is required when " + toSnakeCase(fe.Param()) + " is set"
+	case "required_if":
+		// param: "Field value", contoh "DiscountType fixed"
+		parts := strings.SplitN(fe.Param(), " ", 2)
+		if len(parts) == 2 {
+			return "is required when " + toSnakeCase(parts[0]) + " is " + parts[1]
+		}
+		return "is required"
+	case "len":
+		if isString {
+			return fmt.Sprintf("must be exactly %s characters", fe.Param())
+		}
+		return fmt.Sprintf("must contain exactly %s items", fe.Param())
+	case "alpha":
+		return "must contain only letters"
+	case "alphanum":
+		return "must contain only letters and numbers"
+	case "email":
+		return "must be a valid email address"
+	case "min":
+		if isString {
+			return fmt.Sprintf("must be at least %s characters", fe.Param())
+		}
+		return fmt.Sprintf("must be at least %s", fe.Param())
+	case "max":
+		if isString {
+			return fmt.Sprintf("must be at most %s characters", fe.Param())
+		}
+		return fmt.Sprintf("must be at most %s", fe.Param())
+	case "oneof":
+		return "must be one of: " + strings.ReplaceAll(fe.Param(), " ", ", ")
+	case "gtfield":
+		return "must be after " + toSnakeCase(fe.Param())
+	case "future":
+		return "must be in the future"
+	case "datetime":
+		return "must match format " + fe.Param()
+	case "timezone":
+		return "must be a valid IANA time zone, e.g. Asia/Jakarta"
+	case "gt":
+		return "must be greater than " + fe.Param()
+	case "gte":
+		return "must be greater than or equal to " + fe.Param()
+	case "lte":
+		return "must be less than or equal to " + fe.Param()
+	default:
+		return "is invalid"
+	}
+}
+
+// toSnakeCase mengubah nama field Go (StartTime, RoomID) ke format json (start_time, room_id)
+func toSnakeCase(s string) string {
+	runes := []rune(s)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			// Underscore hanya di awal kata baru, akronim (ID) tetap satu kata
+			if i > 0 && (unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}